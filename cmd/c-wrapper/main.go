@@ -1,7 +1,9 @@
 // ./cmd/c-wrapper/main.go
 
 // пакет экспортирует API go-fake-useragent в С (.so/.dll/.dylib) через CGo для Python ctypes.
-// управляет состоянием потокобезопасным синглтоном sync.Once (инициализация/очистка ресурсов).
+// управляет состоянием потокобезопасным синглтоном (инициализация/очистка ресурсов, повторная
+// инициализация после Shutdown) и отдельными генераторами по дескрипторам CreateGenerator
+// для разных конфигураций в одном процессе.
 // логирует асинхронно через C-коллбэк в Python с буферизацией.
 // обеспечивает межъязыковой обмен/маршалинг данных и управление памятью Python.
 package main
@@ -31,6 +33,8 @@ import (
 	ua "github.com/imbecility/go-fake-useragent/useragent"
 	"log/slog"
 	"math"
+	"net/http"
+	"runtime"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -49,25 +53,41 @@ const (
 	ErrJSONMarshal    = -2 // ошибка сериализации данных в JSON
 	ErrUnknownCrawler = -3 // передан неизвестный тип поискового робота
 	ErrInitialization = -4 // ошибка инициализации
+	ErrInvalidOptions = -5 // не удалось разобрать JSON с параметрами вызова
+	ErrInvalidHandle  = -6 // дескриптор генератора не создан CreateGenerator или уже уничтожен
 )
 
 var (
-	globalGenerator *ua.Generator // единый глобальный синглтон генератора user-agent'ов
-	initErr         error         // хранит ошибку, возникшую во время инициализации
-	initOnce        sync.Once     // гарантия однократной инициализации, даже при конкурентных вызовах из разных потоков Python
-	shutdownOnce    sync.Once     // гарантия однократного завершения
+	globalGenerator atomic.Pointer[ua.Generator] // единый глобальный синглтон генератора user-agent'ов, nil - до Initialize и после Shutdown
+	lifecycleMu     sync.Mutex                   // сериализует Initialize, CreateGenerator и Shutdown из разных потоков Python
+	loggingStarted  bool                         // обработчик логов запущен, защищено lifecycleMu
 
 	// --- асинхронное python-логгирование ---
 
 	cLogCallback    C.log_callback_f // защищеный мьютексом указатель на функцию логирования для Python
 	logMutex        sync.Mutex       // защита от гонок для cLogCallback
-	logChannel      chan string      // буферизованный канал aсинхронной передачи логов из между Go и Python
 	stopLogs        chan struct{}    // канал для сигнала о завершении работы горутины-обработчика логов
 	processorWG     sync.WaitGroup   // ожидание остановки обработчика логов
 	droppedLogs     atomic.Uint64    // счетчик отброшенных логов (при переполнении)
-	loggingDisabled atomic.Bool      // флаг быстрого отключения логирования при завершении, когда канал уже закрыт
+	logLevel        slog.LevelVar    // минимальный уровень логов, изменяемый во время работы через SetLogLevel
+	loggingDisabled atomic.Bool      // флаг быстрого отключения логирования при завершении, когда обработчик уже остановлен
+
+	// --- персоны GetHeadersEx ---
+
+	personas   = make(map[personaKey]*ua.Session) // сессии по генератору и идентификатору персоны из параметров вызова
+	personasMu sync.Mutex                         // защита personas
+
+	// --- отдельные генераторы CreateGenerator ---
+
+	generators = make(map[C.longlong]*ua.Generator) // генераторы по дескриптору
+	lastHandle C.longlong                           // последний выданный дескриптор, дескрипторы не переиспользуются
+	handlesMu  sync.Mutex                           // защита generators и lastHandle
 )
 
+// logChannel буферизованный канал aсинхронной передачи логов между Go и Python (на 100 сообщений):
+// создается один раз и переживает Shutdown, чтобы обработчики логов не читали переменную во время повторной инициализации
+var logChannel = make(chan string, 100)
+
 // PythonLogHandler перенаправляет логи из Go в Python коллбэк через slog.Handler
 type PythonLogHandler struct {
 	opts    slog.HandlerOptions
@@ -79,7 +99,7 @@ type PythonLogHandler struct {
 // NewPythonLogHandler создает новый экземпляр обработчика логов
 func NewPythonLogHandler() *PythonLogHandler {
 	return &PythonLogHandler{
-		opts:    slog.HandlerOptions{Level: slog.LevelDebug},
+		opts:    slog.HandlerOptions{Level: &logLevel},
 		bufPool: &sync.Pool{New: func() any { return new(bytes.Buffer) }},
 	}
 }
@@ -148,6 +168,7 @@ func (h *PythonLogHandler) WithGroup(name string) slog.Handler {
 // startLogProcessor запускает фоновую горутину для чтения из logChannel и передачи сообщений в C-коллбэк
 func startLogProcessor() {
 	stopLogs = make(chan struct{})
+	stop := stopLogs
 	processorWG.Add(1)
 	go func() {
 		defer processorWG.Done()
@@ -173,63 +194,266 @@ func startLogProcessor() {
 				// освобождение памяти, выделенной под C.CString
 				C.free(unsafe.Pointer(cMsg))
 			// получен сигнал завершения
-			case <-stopLogs:
+			case <-stop:
 				return
 			}
 		}
 	}()
 }
 
-// Initialize экспортируется в C, однократно инициализирует глобальный генератор user-agent'ов
+// startLogging запускает систему асинхронного логирования, если она еще не запущена (впервые или после Shutdown),
+// и возвращает логгер для генераторов. вызывается под lifecycleMu
+func startLogging() *slog.Logger {
+	if !loggingStarted {
+		logLevel.Set(slog.LevelDebug)
+		loggingDisabled.Store(false)
+		startLogProcessor()
+		loggingStarted = true
+	}
+	return slog.New(NewPythonLogHandler())
+}
+
+// Initialize экспортируется в C, инициализирует глобальный генератор user-agent'ов. повторный вызов
+// ничего не меняет, пока не вызван Shutdown: после него Initialize снова создает генератор
+// (например, с другими параметрами), а неудачную инициализацию можно повторить
 //
-// потокобезопасна благодаря `sync.Once`
+// потокобезопасна: вызовы Initialize, CreateGenerator и Shutdown сериализуются
 //
 // параметры:
 //   - useCache: C.bool (true), если нужно использовать дисковый кеш
 //   - cacheTTLDays: C.int, время жизни (TTL) кеша в днях
 //
+// сигнатура не меняется ради совместимости ABI: офлайн-режим и остальные параметры задаются
+// через InitializeWithConfig
+//
 // возвращает:
 //   - C.int: 0 (ErrSuccess) в случае успеха, или код ошибки
 //
 //export Initialize
 func Initialize(useCache C.bool, cacheTTLDays C.int) C.int {
-	initOnce.Do(func() {
-		// инициализация системы асинхронного логирования
-		logChannel = make(chan string, 100) // буфер на 100 сообщений.
-		startLogProcessor()
-		logger := slog.New(NewPythonLogHandler())
-		opts := []ua.Option{ua.WithLogger(logger)}
-		if bool(useCache) {
-			opts = append(opts, ua.WithDiskCache("", time.Duration(cacheTTLDays)*24*time.Hour))
+	var opts []ua.Option
+	if bool(useCache) {
+		opts = append(opts, ua.WithDiskCache("", time.Duration(cacheTTLDays)*24*time.Hour))
+	}
+	return initialize(opts, nil)
+}
+
+// InitializeWithConfig экспортируется в C, аналог Initialize с конфигурацией в JSON: новые опции Go API
+// добавляются полями конфигурации, а не новыми сигнатурами C-функций. как и Initialize, ничего не меняет
+// до вызова Shutdown, если библиотека уже инициализирована
+//
+// параметры:
+//   - configJSON: JSON-объект с конфигурацией или NULL, например
+//     {"use_cache": true, "cache_ttl_days": 1, "cache_path": "/var/cache/ua.json", "offline": false,
+//     "locales": ["de-DE"], "platforms": ["Windows", "macOS"], "proxy": "socks5://127.0.0.1:1080",
+//     "request_timeout_seconds": 10, "init_deadline_seconds": 0.5, "log_level": "INFO"}.
+//     неизвестные поля игнорируются. без cache_ttl_days кеш живет 1 день, а явное значение
+//     действует как cacheTTLDays в Initialize: 0 - кеш записывается, но никогда не считается актуальным
+//
+// возвращает:
+//   - C.int: 0 (ErrSuccess) в случае успеха, или код ошибки
+//
+//export InitializeWithConfig
+func InitializeWithConfig(configJSON *C.char) C.int {
+	var config generatorConfig
+	if configJSON != nil {
+		if err := json.Unmarshal([]byte(C.GoString(configJSON)), &config); err != nil {
+			return C.int(ErrInvalidOptions)
 		}
-		globalGenerator, initErr = ua.NewGenerator(opts...)
-	})
-	if initErr != nil {
+	}
+	return initialize(config.options(), config.LogLevel)
+}
+
+// initialize создает глобальный генератор с опциями opts, если он еще не создан,
+// и устанавливает уровень логов level (nil - DEBUG по умолчанию)
+func initialize(opts []ua.Option, level *slog.Level) C.int {
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+	if globalGenerator.Load() != nil {
+		return C.int(ErrSuccess)
+	}
+	// инициализация системы асинхронного логирования
+	logger := startLogging()
+	if level != nil {
+		logLevel.Set(*level)
+	}
+	gen, err := ua.NewGenerator(append(opts, ua.WithLogger(logger))...)
+	if err != nil {
 		return C.int(ErrInitialization)
 	}
+	globalGenerator.Store(gen)
 	return C.int(ErrSuccess)
 }
 
-// Shutdown экспортируется в C, корректно завершает работу библиотеки,
+// Shutdown экспортируется в C, корректно завершает работу библиотеки:
 // отключает логирование и дожидается завершения обработки всех оставшихся логов,
-// потокобезопасна благодаря `sync.Once`.
+// останавливает глобальный генератор и генераторы CreateGenerator, забывает персоны.
+// повторный вызов ничего не делает, после Shutdown библиотеку можно снова инициализировать Initialize.
+// потокобезопасна.
 //
 //export Shutdown
 func Shutdown() {
-	shutdownOnce.Do(func() {
-		// установка атомарного флана, чтобы новые логи больше не обрабатывались
-		loggingDisabled.Store(true)
-		logMutex.Lock()
-		cLogCallback = nil
-		logMutex.Unlock()
-		// сигнал горутине-обработчику логов о завершении
-		if stopLogs != nil {
-			close(stopLogs)
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+	if !loggingStarted {
+		return
+	}
+	// установка атомарного флана, чтобы новые логи больше не обрабатывались
+	loggingDisabled.Store(true)
+	logMutex.Lock()
+	cLogCallback = nil
+	logMutex.Unlock()
+	// сигнал горутине-обработчику логов о завершении и ожидание ее завершения
+	close(stopLogs)
+	processorWG.Wait()
+	loggingStarted = false
+	// сообщения, не переданные до остановки, не должны попасть в коллбэк следующей инициализации
+	for len(logChannel) > 0 {
+		<-logChannel
+	}
+
+	// остановка фонового обновления версий генераторов
+	if gen := globalGenerator.Swap(nil); gen != nil {
+		_ = gen.Close()
+	}
+	personasMu.Lock()
+	clear(personas)
+	personasMu.Unlock()
+	handlesMu.Lock()
+	for _, gen := range generators {
+		_ = gen.Close()
+	}
+	clear(generators)
+	handlesMu.Unlock()
+}
+
+// generatorConfig конфигурация InitializeWithConfig и CreateGenerator в JSON, все поля необязательны
+type generatorConfig struct {
+	UseCache              bool        `json:"use_cache"`               // использовать дисковый кеш
+	CacheTTLDays          *int        `json:"cache_ttl_days"`          // время жизни кеша в днях, по умолчанию 1, 0 - как в Initialize
+	CachePath             string      `json:"cache_path"`              // путь к файлу кеша, пусто - путь по умолчанию
+	Offline               bool        `json:"offline"`                 // не обращаться к сети
+	Locales               []string    `json:"locales"`                 // языки браузера, например ["de-DE", "en-US"]
+	Platforms             []string    `json:"platforms"`               // платформы, например ["Windows", "macOS"]
+	Proxy                 string      `json:"proxy"`                   // прокси для источников версий: http, https или socks5
+	RequestTimeoutSeconds float64     `json:"request_timeout_seconds"` // таймаут запроса к источникам версий, по умолчанию 15 секунд
+	InitDeadlineSeconds   float64     `json:"init_deadline_seconds"`   // сколько инициализация ждет сеть, затем аппроксимация (WithInitDeadline)
+	LogLevel              *slog.Level `json:"log_level"`               // уровень логов ("DEBUG", "INFO", "WARN", "ERROR"), общий для процесса: только InitializeWithConfig
+}
+
+// options преобразует конфигурацию в опции генератора
+func (c generatorConfig) options() []ua.Option {
+	var opts []ua.Option
+	if c.UseCache || c.CachePath != "" {
+		// явное значение передается как есть, как в Initialize: 0 - кеш записывается, но никогда не считается актуальным
+		ttlDays := 1
+		if c.CacheTTLDays != nil {
+			ttlDays = *c.CacheTTLDays
+		}
+		opts = append(opts, ua.WithDiskCache(c.CachePath, time.Duration(ttlDays)*24*time.Hour))
+	}
+	if c.Offline {
+		opts = append(opts, ua.WithOfflineMode())
+	}
+	if len(c.Locales) > 0 {
+		opts = append(opts, ua.WithLocales(c.Locales...))
+	}
+	if len(c.Platforms) > 0 {
+		platforms := make([]ua.Platform, len(c.Platforms))
+		for i, p := range c.Platforms {
+			platforms[i] = ua.Platform(p)
 		}
+		opts = append(opts, ua.WithPlatforms(platforms...))
+	}
+	if c.Proxy != "" {
+		opts = append(opts, ua.WithProxy(c.Proxy))
+	}
+	if c.RequestTimeoutSeconds > 0 {
+		opts = append(opts, ua.WithHTTPClient(&http.Client{Timeout: seconds(c.RequestTimeoutSeconds)}))
+	}
+	if c.InitDeadlineSeconds > 0 {
+		opts = append(opts, ua.WithInitDeadline(seconds(c.InitDeadlineSeconds)))
+	}
+	return opts
+}
 
-		// ожидание завершения горутины-обработчика
-		processorWG.Wait()
-	})
+// seconds переводит секунды из конфигурации в time.Duration
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// CreateGenerator экспортируется в C, создает отдельный генератор со своей конфигурацией, независимый
+// от глобального генератора Initialize: один процесс может держать несколько генераторов с разными
+// языками, платформами и путями кеша. логи всех генераторов уходят в общий коллбэк с атрибутом generator.
+// генератор живет до вызова DestroyGenerator или Shutdown
+//
+// параметры:
+//   - configJSON: JSON-объект с конфигурацией или NULL, например
+//     {"use_cache": true, "cache_ttl_days": 1, "cache_path": "/tmp/ua-de.json", "offline": false,
+//     "locales": ["de-DE"], "platforms": ["Windows", "macOS"]}. неизвестные поля игнорируются
+//
+// возвращает:
+//   - C.longlong: дескриптор генератора (больше нуля) или код ошибки
+//
+//export CreateGenerator
+func CreateGenerator(configJSON *C.char) C.longlong {
+	var config generatorConfig
+	if configJSON != nil {
+		if err := json.Unmarshal([]byte(C.GoString(configJSON)), &config); err != nil {
+			return C.longlong(ErrInvalidOptions)
+		}
+	}
+	lifecycleMu.Lock()
+	defer lifecycleMu.Unlock()
+	handlesMu.Lock()
+	lastHandle++
+	handle := lastHandle
+	handlesMu.Unlock()
+
+	logger := startLogging().With("generator", int64(handle))
+	gen, err := ua.NewGenerator(append(config.options(), ua.WithLogger(logger))...)
+	if err != nil {
+		return C.longlong(ErrInitialization)
+	}
+	handlesMu.Lock()
+	generators[handle] = gen
+	handlesMu.Unlock()
+	return handle
+}
+
+// DestroyGenerator экспортируется в C, останавливает генератор CreateGenerator и освобождает дескриптор
+//
+// параметры:
+//   - handle: дескриптор генератора
+//
+// возвращает:
+//   - C.int: 0 (ErrSuccess) или ErrInvalidHandle, если дескриптор неизвестен
+//
+//export DestroyGenerator
+func DestroyGenerator(handle C.longlong) C.int {
+	handlesMu.Lock()
+	gen, ok := generators[handle]
+	delete(generators, handle)
+	handlesMu.Unlock()
+	if !ok {
+		return C.int(ErrInvalidHandle)
+	}
+	personasMu.Lock()
+	for key := range personas {
+		if key.handle == handle {
+			delete(personas, key)
+		}
+	}
+	personasMu.Unlock()
+	_ = gen.Close()
+	return C.int(ErrSuccess)
+}
+
+// generatorFor возвращает генератор по дескриптору, nil - дескриптор неизвестен
+func generatorFor(handle C.longlong) *ua.Generator {
+	handlesMu.Lock()
+	defer handlesMu.Unlock()
+	return generators[handle]
 }
 
 // SetLoggerCallback экспортируется в C, устанавливает функцию обратного вызова для получения логов.
@@ -255,6 +479,27 @@ func GetDroppedLogs() C.ulonglong {
 	return C.ulonglong(droppedLogs.Load())
 }
 
+// ResetDroppedLogs экспортируется в C, обнуляет счетчик отброшенных сообщений лога,
+// позволяя использовать его как метрику за интервал
+//
+// возвращает:
+//   - C.ulonglong: количество отброшенных логов до обнуления
+//
+//export ResetDroppedLogs
+func ResetDroppedLogs() C.ulonglong {
+	return C.ulonglong(droppedLogs.Swap(0))
+}
+
+// SetLogLevel экспортируется в C, устанавливает минимальный уровень передаваемых в Python логов
+//
+// параметры:
+//   - level: уровень slog (-4: DEBUG, 0: INFO, 4: WARN, 8: ERROR)
+//
+//export SetLogLevel
+func SetLogLevel(level C.int) {
+	logLevel.Set(slog.Level(level))
+}
+
 // copyToBuffer внутренняя функция-хелпер для безопасного копирования данных из Go-слайса байт в C-буфер,
 // предоставленный вызывающей стороной Python, через паттерн двойного вызова:
 //   - первый вызов с NULL-буфером возвращает требуемый размер
@@ -311,10 +556,31 @@ func copyToBuffer(data []byte, buffer *C.char, length C.size_t) C.int {
 //
 //export GetRandomUA
 func GetRandomUA(buffer *C.char, length C.size_t) C.int {
-	if globalGenerator == nil {
+	gen := globalGenerator.Load()
+	if gen == nil {
 		return C.int(ErrNotInitialized)
 	}
-	return copyToBuffer([]byte(globalGenerator.Get()), buffer, length)
+	return copyToBuffer([]byte(gen.Get()), buffer, length)
+}
+
+// GetRandomUAFrom экспортируется в C, аналог GetRandomUA для генератора CreateGenerator
+//
+// параметры:
+//   - handle: дескриптор генератора
+//   - buffer: указатель на буфер для записи строки User-Agent
+//   - length: размер буфера
+//
+// возвращает:
+//   - C.int: код ошибки (ErrInvalidHandle для неизвестного дескриптора), требуемый размер буфера
+//     или количество скопированных байт
+//
+//export GetRandomUAFrom
+func GetRandomUAFrom(handle C.longlong, buffer *C.char, length C.size_t) C.int {
+	gen := generatorFor(handle)
+	if gen == nil {
+		return C.int(ErrInvalidHandle)
+	}
+	return copyToBuffer([]byte(gen.Get()), buffer, length)
 }
 
 // GetHeaders экспортируется в C, генерирует заголовки для HTTP-запроса, возвращает их в виде JSON-строки
@@ -329,7 +595,8 @@ func GetRandomUA(buffer *C.char, length C.size_t) C.int {
 //
 //export GetHeaders
 func GetHeaders(url *C.char, buffer *C.char, length C.size_t) C.int {
-	if globalGenerator == nil {
+	gen := globalGenerator.Load()
+	if gen == nil {
 		return C.int(ErrNotInitialized)
 	}
 	var goURL string
@@ -337,7 +604,7 @@ func GetHeaders(url *C.char, buffer *C.char, length C.size_t) C.int {
 		goURL = C.GoString(url)
 	}
 
-	headersMap := globalGenerator.GetHeaders(goURL)
+	headersMap := gen.GetHeaders(goURL)
 	jsonData, err := json.Marshal(headersMap)
 	if err != nil {
 		return C.int(ErrJSONMarshal)
@@ -345,10 +612,182 @@ func GetHeaders(url *C.char, buffer *C.char, length C.size_t) C.int {
 	return copyToBuffer(jsonData, buffer, length)
 }
 
+// GetHeadersFrom экспортируется в C, аналог GetHeaders для генератора CreateGenerator
+//
+// параметры:
+//   - handle: дескриптор генератора
+//   - url: URL, для которого генерируются заголовки или NULL
+//   - buffer: указатель на буфер для записи JSON-строки
+//   - length: размер буфера
+//
+// возвращает:
+//   - C.int: код ошибки (ErrInvalidHandle для неизвестного дескриптора), требуемый размер буфера
+//     или количество скопированных байт
+//
+//export GetHeadersFrom
+func GetHeadersFrom(handle C.longlong, url *C.char, buffer *C.char, length C.size_t) C.int {
+	gen := generatorFor(handle)
+	if gen == nil {
+		return C.int(ErrInvalidHandle)
+	}
+	var goURL string
+	if url != nil {
+		goURL = C.GoString(url)
+	}
+	jsonData, err := json.Marshal(gen.GetHeaders(goURL))
+	if err != nil {
+		return C.int(ErrJSONMarshal)
+	}
+	return copyToBuffer(jsonData, buffer, length)
+}
+
+// headersExOptions параметры вызова GetHeadersEx, все поля необязательны:
+// новые параметры Go API добавляются сюда, а не новыми экспортируемыми функциями
+type headersExOptions struct {
+	URL            string  `json:"url"`             // целевой адрес запроса
+	FromURL        *string `json:"from_url"`        // страница, с которой совершается переход ("" - прямой заход)
+	ReferrerPolicy string  `json:"referrer_policy"` // политика формирования referer
+	Persona        string  `json:"persona"`         // идентификатор персоны: один и тот же браузер для всех вызовов
+	Resource       string  `json:"resource"`        // тип ресурса (image, script, style, font, fetch, video, audio, iframe), пусто - навигация
+	Locale         string  `json:"locale"`          // язык запроса ("de-DE"), заменяет языки генератора в accept-language
+}
+
+// personaKey ключ персоны: у каждого генератора CreateGenerator свои персоны
+type personaKey struct {
+	handle C.longlong // дескриптор генератора, 0 - глобальный генератор
+	id     string     // идентификатор персоны из параметров вызова
+}
+
+// sessionFor возвращает сессию персоны id генератора handle, создавая её генератором gen при первом обращении
+func sessionFor(gen *ua.Generator, handle C.longlong, id string) *ua.Session {
+	personasMu.Lock()
+	defer personasMu.Unlock()
+	key := personaKey{handle: handle, id: id}
+	s, ok := personas[key]
+	if !ok {
+		s = gen.NewSession()
+		personas[key] = s
+	}
+	return s
+}
+
+// GetHeadersEx экспортируется в C, генерирует заголовки с параметрами вызова в JSON, возвращает их в виде JSON-строки
+//
+// параметры:
+//   - optionsJSON: JSON-объект с параметрами или NULL, например
+//     {"url": "https://example.com/", "from_url": "https://google.com/", "referrer_policy": "origin", "persona": "user-42"}.
+//     с "resource" (например "image") заголовки соответствуют запросу ресурса url страницей from_url
+//     (accept, sec-fetch-dest и т.д. как у браузера для этого типа ресурса), с "locale" (например "de-DE")
+//     accept-language строится для этого языка вместо языков генератора. неизвестные поля игнорируются, поэтому новые параметры не ломают старые версии библиотеки
+//   - buffer: указатель на буфер для записи JSON-строки
+//   - length: размер буфера
+//
+// возвращает:
+//   - C.int: код ошибки, требуемый размер буфера или количество скопированных байт
+//
+//export GetHeadersEx
+func GetHeadersEx(optionsJSON *C.char, buffer *C.char, length C.size_t) C.int {
+	gen := globalGenerator.Load()
+	if gen == nil {
+		return C.int(ErrNotInitialized)
+	}
+	return headersEx(gen, 0, optionsJSON, buffer, length)
+}
+
+// GetHeadersExFrom экспортируется в C, аналог GetHeadersEx для генератора CreateGenerator:
+// персоны у каждого генератора свои и освобождаются ReleasePersonaFrom или DestroyGenerator
+//
+// параметры:
+//   - handle: дескриптор генератора
+//   - optionsJSON: JSON-объект с параметрами или NULL, как у GetHeadersEx
+//   - buffer: указатель на буфер для записи JSON-строки
+//   - length: размер буфера
+//
+// возвращает:
+//   - C.int: код ошибки (ErrInvalidHandle для неизвестного дескриптора), требуемый размер буфера
+//     или количество скопированных байт
+//
+//export GetHeadersExFrom
+func GetHeadersExFrom(handle C.longlong, optionsJSON *C.char, buffer *C.char, length C.size_t) C.int {
+	gen := generatorFor(handle)
+	if gen == nil {
+		return C.int(ErrInvalidHandle)
+	}
+	return headersEx(gen, handle, optionsJSON, buffer, length)
+}
+
+// headersEx генерирует заголовки генератором gen (дескриптор handle, 0 - глобальный) по параметрам вызова
+func headersEx(gen *ua.Generator, handle C.longlong, optionsJSON *C.char, buffer *C.char, length C.size_t) C.int {
+	var opts headersExOptions
+	if optionsJSON != nil {
+		if err := json.Unmarshal([]byte(C.GoString(optionsJSON)), &opts); err != nil {
+			return C.int(ErrInvalidOptions)
+		}
+	}
+
+	var headersMap map[string]string
+	policy := ua.ReferrerPolicy(opts.ReferrerPolicy)
+	var pageURL string
+	if opts.FromURL != nil {
+		pageURL = *opts.FromURL
+	}
+	switch {
+	case opts.Resource != "" && opts.Persona != "":
+		headersMap = sessionFor(gen, handle, opts.Persona).GetHeadersForWithPolicy(policy, ua.ResourceType(opts.Resource), pageURL, opts.URL)
+	case opts.Resource != "":
+		headersMap = gen.GetHeadersForWithPolicy(policy, ua.ResourceType(opts.Resource), pageURL, opts.URL)
+	case opts.Persona != "" && opts.FromURL != nil:
+		headersMap = sessionFor(gen, handle, opts.Persona).GetNavigationHeadersWithPolicy(policy, *opts.FromURL, opts.URL)
+	case opts.Persona != "":
+		headersMap = sessionFor(gen, handle, opts.Persona).GetHeadersWithPolicy(policy, opts.URL)
+	case opts.FromURL != nil:
+		headersMap = gen.GetNavigationHeadersWithPolicy(policy, *opts.FromURL, opts.URL)
+	default:
+		headersMap = gen.GetHeadersWithPolicy(policy, opts.URL)
+	}
+	if _, ok := headersMap["accept-language"]; ok && opts.Locale != "" {
+		// некорректный язык не меняет accept-language, как некорректные значения опций генератора
+		if language := ua.AcceptLanguage(headersMap["user-agent"], opts.Locale); language != "" {
+			headersMap["accept-language"] = language
+		}
+	}
+
+	jsonData, err := json.Marshal(headersMap)
+	if err != nil {
+		return C.int(ErrJSONMarshal)
+	}
+	return copyToBuffer(jsonData, buffer, length)
+}
+
+// ReleasePersona экспортируется в C, забывает персону GetHeadersEx: следующий вызов с тем же
+// идентификатором получит новый браузер. персоны хранятся до вызова ReleasePersona или Shutdown
+//
+//export ReleasePersona
+func ReleasePersona(id *C.char) {
+	if id == nil {
+		return
+	}
+	personasMu.Lock()
+	delete(personas, personaKey{id: C.GoString(id)})
+	personasMu.Unlock()
+}
+
+// ReleasePersonaFrom экспортируется в C, аналог ReleasePersona для персон GetHeadersExFrom генератора handle
+//
+//export ReleasePersonaFrom
+func ReleasePersonaFrom(handle C.longlong, id *C.char) {
+	if id == nil {
+		return
+	}
+	personasMu.Lock()
+	delete(personas, personaKey{handle: handle, id: C.GoString(id)})
+	personasMu.Unlock()
+}
+
 // GetCrawlerHeaders экспортируется в C, генерирует заголовки под поискового робота, возвращает их в виде JSON-строки
 //
 // параметры:
-//   - crawlerType: тип робота (0: google, 1: bing, 2: yandex)
+//   - crawlerType: тип робота (0: google, 1: bing, 2: yandex, 3: duckduckgo, 4: baidu, 5: apple, 6: sogou)
 //   - buffer: указатель на буфер для записи JSON-строки
 //   - length: размер буфера
 //
@@ -357,18 +796,135 @@ func GetHeaders(url *C.char, buffer *C.char, length C.size_t) C.int {
 //
 //export GetCrawlerHeaders
 func GetCrawlerHeaders(crawlerType C.int, buffer *C.char, length C.size_t) C.int {
-	if globalGenerator == nil {
+	gen := globalGenerator.Load()
+	if gen == nil {
 		return C.int(ErrNotInitialized)
 	}
-	// проверка что тип краулера 0-2
-	if crawlerType < 0 || crawlerType > 2 {
+	// проверка что тип краулера 0-6
+	if crawlerType < 0 || crawlerType > C.int(ua.SogouSpider) {
 		return C.int(ErrUnknownCrawler)
 	}
 
-	headersMap := globalGenerator.GetCrawlerHeaders(ua.CrawlerType(crawlerType))
+	headersMap := gen.GetCrawlerHeaders(ua.CrawlerType(crawlerType))
 	jsonData, err := json.Marshal(headersMap)
 	if err != nil {
 		return C.int(ErrJSONMarshal)
 	}
 	return copyToBuffer(jsonData, buffer, length)
 }
+
+// runtimeStats ответ GetRuntimeStats
+type runtimeStats struct {
+	Goroutines      int     `json:"goroutines"`        // количество горутин Go-рантайма
+	LogQueue        int     `json:"log_queue"`         // сообщений лога в очереди на передачу в Python
+	LogQueueCap     int     `json:"log_queue_cap"`     // емкость очереди лога
+	DroppedLogs     uint64  `json:"dropped_logs"`      // отброшено сообщений лога из-за переполнения очереди
+	Personas        int     `json:"personas"`          // персон GetHeadersEx и GetHeadersExFrom, еще не освобожденных
+	Generators      int     `json:"generators"`        // генераторов CreateGenerator, еще не уничтоженных DestroyGenerator
+	HeapBytes       uint64  `json:"heap_bytes"`        // занятая память кучи Go
+	Versions        int     `json:"versions"`          // количество версий Chrome/Edge в текущем наборе
+	VersionsOrigin  string  `json:"versions_origin"`   // источник набора: cache, network, approximation или import
+	CacheAgeSeconds float64 `json:"cache_age_seconds"` // возраст набора версий в секундах
+	LastRefresh     struct {
+		At    *time.Time `json:"at"`              // момент последнего опроса сетевых источников, null - опросов не было
+		OK    bool       `json:"ok"`              // опрос завершился успешно
+		Error string     `json:"error,omitempty"` // ошибка опроса
+	} `json:"last_refresh"`
+}
+
+// GetRuntimeStats экспортируется в C, возвращает JSON со сведениями о состоянии Go-стороны:
+// позволяет сервисам, работающим неделями, следить за утечками горутин, переполнением очереди логов
+// и свежестью версий без отладчика
+//
+// параметры:
+//   - buffer: указатель на буфер для записи JSON-строки
+//   - length: размер буфера
+//
+// возвращает:
+//   - C.int: код ошибки, требуемый размер буфера или количество скопированных байт
+//
+//export GetRuntimeStats
+func GetRuntimeStats(buffer *C.char, length C.size_t) C.int {
+	gen := globalGenerator.Load()
+	if gen == nil {
+		return C.int(ErrNotInitialized)
+	}
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := runtimeStats{
+		Goroutines:  runtime.NumGoroutine(),
+		LogQueue:    len(logChannel),
+		LogQueueCap: cap(logChannel),
+		DroppedLogs: droppedLogs.Load(),
+		HeapBytes:   mem.HeapAlloc,
+	}
+	personasMu.Lock()
+	stats.Personas = len(personas)
+	personasMu.Unlock()
+	handlesMu.Lock()
+	stats.Generators = len(generators)
+	handlesMu.Unlock()
+
+	genStats := gen.Stats()
+	stats.Versions = genStats.Versions
+	stats.VersionsOrigin = genStats.VersionsOrigin
+	if !genStats.VersionsAt.IsZero() {
+		stats.CacheAgeSeconds = time.Since(genStats.VersionsAt).Seconds()
+	}
+	if !genStats.LastFetchAt.IsZero() {
+		stats.LastRefresh.At = &genStats.LastFetchAt
+		stats.LastRefresh.OK = genStats.LastFetchError == ""
+		stats.LastRefresh.Error = genStats.LastFetchError
+	}
+
+	jsonData, err := json.Marshal(stats)
+	if err != nil {
+		return C.int(ErrJSONMarshal)
+	}
+	return copyToBuffer(jsonData, buffer, length)
+}
+
+// versionsInfo ответ GetVersions
+type versionsInfo struct {
+	Versions   []string            `json:"versions"`    // набор версий Chromium для Chrome/Edge, как GetVersions генератора
+	Browsers   map[string][]string `json:"browsers"`    // версии по семействам (chrome, edge, firefox, safari), как VersionsFor
+	Origin     string              `json:"origin"`      // источник набора: cache, network, approximation, import, static или snapshot
+	VersionsAt *time.Time          `json:"versions_at"` // момент получения набора, null - неизвестен
+}
+
+// GetVersions экспортируется в C, возвращает JSON с версиями браузеров, которые сейчас использует генератор:
+// позволяет проверить из Python, откуда взяты версии и не устарели ли они
+//
+// параметры:
+//   - buffer: указатель на буфер для записи JSON-строки
+//   - length: размер буфера
+//
+// возвращает:
+//   - C.int: код ошибки, требуемый размер буфера или количество скопированных байт
+//
+//export GetVersions
+func GetVersions(buffer *C.char, length C.size_t) C.int {
+	gen := globalGenerator.Load()
+	if gen == nil {
+		return C.int(ErrNotInitialized)
+	}
+	info := versionsInfo{
+		Versions: gen.GetVersions(),
+		Browsers: make(map[string][]string),
+	}
+	for _, b := range []ua.Browser{ua.BrowserChrome, ua.BrowserEdge, ua.BrowserFirefox, ua.BrowserSafari} {
+		info.Browsers[string(b)] = gen.VersionsFor(b)
+	}
+	stats := gen.Stats()
+	info.Origin = stats.VersionsOrigin
+	if !stats.VersionsAt.IsZero() {
+		info.VersionsAt = &stats.VersionsAt
+	}
+
+	jsonData, err := json.Marshal(info)
+	if err != nil {
+		return C.int(ErrJSONMarshal)
+	}
+	return copyToBuffer(jsonData, buffer, length)
+}
@@ -0,0 +1,333 @@
+// ./cmd/fakeua/commands.go
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/imbecility/go-fake-useragent/useragent"
+)
+
+// crawlers имена роботов для подкоманды crawler
+var crawlers = map[string]useragent.CrawlerType{
+	"googlebot":   useragent.GoogleBot,
+	"bingbot":     useragent.BingBot,
+	"yandexbot":   useragent.YandexBot,
+	"duckduckbot": useragent.DuckDuckBot,
+	"baiduspider": useragent.BaiduSpider,
+	"applebot":    useragent.AppleBot,
+	"sogou":       useragent.SogouSpider,
+}
+
+// runUA выводит n строк User-Agent, по одной на строку
+func runUA(args []string) error {
+	fs := flag.NewFlagSet("ua", flag.ExitOnError)
+	genFlags := addGeneratorFlags(fs)
+	n := fs.Int("n", 1, "количество строк")
+	noRepeat := fs.Bool("no-repeat", false, "не повторять строки, пока не выведены все комбинации")
+	fs.Parse(args)
+	if *n < 1 {
+		return fmt.Errorf("-n должно быть больше нуля")
+	}
+
+	var extra []useragent.Option
+	if *noRepeat {
+		extra = append(extra, useragent.WithNoRepeat())
+	}
+	g, err := genFlags.newGenerator(extra...)
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+
+	for range *n {
+		fmt.Println(g.Get())
+	}
+	return nil
+}
+
+// runHeaders выводит заголовки браузера для перехода на адрес (без адреса - переход из поисковой выдачи)
+func runHeaders(args []string) error {
+	fs := flag.NewFlagSet("headers", flag.ExitOnError)
+	genFlags := addGeneratorFlags(fs)
+	asJSON := fs.Bool("json", false, "вывести JSON-массив пар [имя, значение] в порядке отправки")
+	asCurl := fs.Bool("curl", false, "вывести команду curl (требуется адрес)")
+	resource := fs.String("resource", "", "тип ресурса: image, script, style, font, fetch, video, audio или iframe (требуется адрес)")
+	page := fs.String("page", "", "страница, которая загружает ресурс -resource (по умолчанию - главная страница сайта ресурса)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "использование: fakeua headers [флаги] [url]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		return fmt.Errorf("ожидается не более одного адреса")
+	}
+	targetURL := fs.Arg(0)
+	if *asCurl && targetURL == "" {
+		return fmt.Errorf("для -curl нужен адрес")
+	}
+	if *resource != "" && targetURL == "" {
+		return fmt.Errorf("для -resource нужен адрес")
+	}
+
+	g, err := genFlags.newGenerator()
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+
+	var hs *useragent.HeaderSet
+	switch {
+	case *resource != "":
+		hs = g.GetHeaderSetFor(useragent.ResourceType(*resource), *page, targetURL)
+	case targetURL == "":
+		hs = g.GetHeaderSet()
+	default:
+		hs = g.GetHeaderSet(targetURL)
+	}
+	switch {
+	case *asCurl:
+		fmt.Println(hs.ToCurl(targetURL))
+		return nil
+	case *asJSON:
+		return printJSON(hs.ToOrderedPairs())
+	}
+	for _, h := range hs.Headers() {
+		fmt.Printf("%s: %s\n", h.Name, h.Value)
+	}
+	return nil
+}
+
+// runCrawler выводит заголовки поискового робота
+func runCrawler(args []string) error {
+	fs := flag.NewFlagSet("crawler", flag.ExitOnError)
+	genFlags := addGeneratorFlags(fs)
+	asJSON := fs.Bool("json", false, "вывести JSON-объект заголовков")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "использование: fakeua crawler [флаги] <%s>\n", strings.Join(crawlerNames(), "|"))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	crawler, ok := crawlers[strings.ToLower(fs.Arg(0))]
+	if !ok {
+		return fmt.Errorf("неизвестный робот %q, доступны: %s", fs.Arg(0), strings.Join(crawlerNames(), ", "))
+	}
+
+	g, err := genFlags.newGenerator()
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+
+	headers := g.GetCrawlerHeaders(crawler)
+	if *asJSON {
+		return printJSON(headers)
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Printf("%s: %s\n", name, headers[name])
+	}
+	return nil
+}
+
+// runCheck выполняет запрос со сгенерированными заголовками (Generator.Check) и сравнивает их с тем,
+// что увидел сервер: код выхода 1, если заголовки не дошли или дошли с другими значениями
+func runCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	genFlags := addGeneratorFlags(fs)
+	asJSON := fs.Bool("json", false, "вывести отчет в формате JSON")
+	timeout := fs.Duration("timeout", 30*time.Second, "таймаут запроса")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "использование: fakeua check [флаги] [url]")
+		fmt.Fprintln(fs.Output(), "без адреса используется эхо-сервер https://httpbin.org/headers")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		return fmt.Errorf("ожидается не более одного адреса")
+	}
+
+	g, err := genFlags.newGenerator()
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	report, err := g.Check(ctx, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printCheckReport(report)
+	}
+	if report.Observed != nil && !report.OK() {
+		return fmt.Errorf("заголовки дошли до сервера с изменениями")
+	}
+	return nil
+}
+
+// printCheckReport выводит отчет самопроверки: расхождения с отправленными и полученными значениями
+func printCheckReport(r useragent.CheckReport) {
+	fmt.Printf("%s: HTTP %d\n", r.URL, r.StatusCode)
+	if r.Observed == nil {
+		fmt.Println("сервер не вернул заголовки запроса, сравнение невозможно (нужен эхо-сервер с ответом {\"headers\": {...}})")
+		return
+	}
+	for _, name := range r.Missing {
+		fmt.Printf("  не дошел   %s: %s\n", name, r.Intended[name])
+	}
+	for _, name := range r.Mismatched {
+		fmt.Printf("  изменен    %s: %s -> %s\n", name, r.Intended[name], r.Observed[name])
+	}
+	for _, name := range r.Extra {
+		fmt.Printf("  добавлен   %s: %s\n", name, r.Observed[name])
+	}
+	if r.OK() {
+		fmt.Printf("все %d заголовков дошли без изменений\n", len(r.Intended))
+	}
+}
+
+// runApprox выводит аппроксимированные версии Chrome/Edge на дату (ApproximateVersionsFor): работает без сети
+// и без генератора, например чтобы закрепить версии через -static-version в изолированном окружении
+func runApprox(args []string) error {
+	fs := flag.NewFlagSet("approx", flag.ExitOnError)
+	date := fs.String("date", "", "дата в формате 2006-01-02, по умолчанию - сегодня")
+	n := fs.Int("n", 5, "количество версий: на дату и на каждую из предыдущих недель")
+	asJSON := fs.Bool("json", false, "вывести JSON-массив версий")
+	fs.Parse(args)
+	if *n < 1 {
+		return fmt.Errorf("-n должно быть больше нуля")
+	}
+	at := time.Now()
+	if *date != "" {
+		var err error
+		if at, err = time.Parse(time.DateOnly, *date); err != nil {
+			return fmt.Errorf("неверная дата %q, ожидается вида 2006-01-02", *date)
+		}
+	}
+
+	versions := useragent.ApproximateVersionsFor(at, *n)
+	if *asJSON {
+		return printJSON(versions)
+	}
+	for _, v := range versions {
+		fmt.Println(v)
+	}
+	return nil
+}
+
+// runServe запускает HTTP-сервис useragent.NewHandler до SIGINT или SIGTERM, после сигнала /readyz отвечает 503,
+// новые соединения не принимаются, а текущие запросы завершаются в пределах -shutdown-timeout.
+// с -config конфигурация перечитывается по SIGHUP и POST /-/reload
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	genFlags := addGeneratorFlags(fs)
+	addr := fs.String("addr", "127.0.0.1:8080", "адрес, на котором принимаются запросы")
+	refresh := fs.String("refresh", "daily@03:00", "расписание обновления версий (пусто - без обновления)")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "сколько ждать завершения текущих запросов при остановке")
+	configPath := fs.String("config", "", "JSON-файл с языками, платформами, долями браузеров и источниками версий (перечитывается по SIGHUP)")
+	fs.Parse(args)
+
+	service, err := newReloadableService(*configPath, func(extra ...useragent.Option) (*useragent.Generator, error) {
+		return genFlags.newGenerator(append([]useragent.Option{useragent.WithRefreshSchedule(*refresh, 30*time.Minute)}, extra...)...)
+	})
+	if err != nil {
+		return err
+	}
+	defer service.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go func() {
+		for {
+			select {
+			case <-hup:
+				if err := service.reload(); err != nil {
+					fmt.Fprintln(os.Stderr, "fakeua: конфигурация не перечитана:", err)
+				} else {
+					fmt.Fprintln(os.Stderr, "fakeua: конфигурация перечитана")
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var draining atomic.Bool
+	server := &http.Server{
+		Addr: *addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/readyz" && draining.Load() {
+				writeStatus(w, http.StatusServiceUnavailable, map[string]string{"status": "shutting down"})
+				return
+			}
+			service.ServeHTTP(w, r)
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return serveUntilSignal(ctx, server, &draining, *shutdownTimeout)
+}
+
+// serveUntilSignal обслуживает запросы server до отмены ctx, затем помечает сервис останавливающимся
+// и ждет завершения текущих запросов не дольше timeout
+func serveUntilSignal(ctx context.Context, server *http.Server, draining *atomic.Bool, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+	fmt.Fprintln(os.Stderr, "fakeua: запросы принимаются на", server.Addr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	draining.Store(true)
+	fmt.Fprintln(os.Stderr, "fakeua: остановка, завершаются текущие запросы")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
+
+// crawlerNames возвращает имена роботов в алфавитном порядке
+func crawlerNames() []string {
+	names := make([]string, 0, len(crawlers))
+	for name := range crawlers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// printJSON выводит значение в стандартный вывод одной строкой JSON (удобно для jq)
+func printJSON(v any) error {
+	return json.NewEncoder(os.Stdout).Encode(v)
+}
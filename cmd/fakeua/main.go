@@ -0,0 +1,171 @@
+// ./cmd/fakeua/main.go
+
+// утилита командной строки для go-fake-useragent: позволяет пользоваться генератором из shell-скриптов
+// и отлаживать заголовки без написания программ на Go:
+//
+//	fakeua ua -n 5                        // пять случайных User-Agent, по одному на строку
+//	fakeua headers https://example.com/   // заголовки браузера в порядке отправки
+//	fakeua headers -curl https://example.com/ | sh
+//	fakeua crawler googlebot              // заголовки поискового робота
+//	fakeua check                          // что увидел эхо-сервер из отправленных заголовков
+//	fakeua approx -date 2026-01-15        // версии Chrome/Edge на дату по модели аппроксимации, без сети
+//	fakeua serve -addr 127.0.0.1:8080     // HTTP-сервис с JSON-ответами (см. useragent.NewHandler)
+//	fakeua proxy -addr 127.0.0.1:8080     // прямой прокси: заголовки браузера для curl и любых программ через HTTP_PROXY
+//	fakeua shell                          // интерактивная оболочка: персоны, переходы, заголовки, curl и HAR
+//
+// общие флаги генератора (-offline, -cache, -browser, -seed, -proxy, -referer, -static-version) указываются после подкоманды.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/imbecility/go-fake-useragent/useragent"
+)
+
+// command подкоманда утилиты
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+// commands подкоманды в порядке вывода в справке
+var commands = []command{
+	{name: "ua", summary: "вывести случайные строки User-Agent", run: runUA},
+	{name: "headers", summary: "вывести заголовки браузера для адреса", run: runHeaders},
+	{name: "crawler", summary: "вывести заголовки поискового робота", run: runCrawler},
+	{name: "check", summary: "отправить запрос с заголовками и сравнить их с тем, что увидел сервер", run: runCheck},
+	{name: "approx", summary: "вывести аппроксимированные версии Chrome/Edge на дату без обращения к сети", run: runApprox},
+	{name: "serve", summary: "запустить HTTP-сервис с User-Agent и заголовками в формате JSON", run: runServe},
+	{name: "proxy", summary: "запустить прямой HTTP-прокси, подставляющий заголовки браузера в запросы", run: runProxy},
+	{name: "shell", summary: "интерактивная оболочка для отладки персон и заголовков", run: runShell},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage(os.Stdout)
+		return
+	}
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "fakeua:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "fakeua: неизвестная подкоманда %q\n\n", name)
+	usage(os.Stderr)
+	os.Exit(2)
+}
+
+// usage выводит список подкоманд
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "использование: fakeua <подкоманда> [флаги] [аргументы]")
+	fmt.Fprintln(w)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "справка по флагам подкоманды: fakeua <подкоманда> -h")
+}
+
+// staticVersionRegex формат полной версии Chromium для -static-version
+var staticVersionRegex = regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+$`)
+
+// generatorFlags общие флаги, которыми настраивается генератор
+type generatorFlags struct {
+	offline     *bool
+	cache       *string
+	cacheTTL    *time.Duration
+	browser     *string
+	seed        *uint64
+	fullVersion *bool
+	proxy       *string
+	referer     *string
+	version     *string
+}
+
+// addGeneratorFlags регистрирует общие флаги генератора в наборе флагов подкоманды
+func addGeneratorFlags(fs *flag.FlagSet) generatorFlags {
+	return generatorFlags{
+		offline:     fs.Bool("offline", false, "не обращаться к сетевым источникам версий"),
+		cache:       fs.String("cache", "", "файл дискового кэша версий (\"-\" - файл по умолчанию во временном каталоге)"),
+		cacheTTL:    fs.Duration("cache-ttl", 24*time.Hour, "время жизни дискового кэша"),
+		browser:     fs.String("browser", "", "только один браузер: chrome, edge, firefox или safari"),
+		seed:        fs.Uint64("seed", 0, "seed для воспроизводимого вывода, 0 - случайный"),
+		fullVersion: fs.Bool("full-version", false, "полная версия Chromium в User-Agent вместо сокращенной"),
+		proxy:       fs.String("proxy", "", "прокси для запросов к источникам версий (http://, socks5://)"),
+		version:     fs.String("static-version", "", "закрепить версию Chromium, например 142.0.7444.60 (без обращения к источникам версий)"),
+		referer:     fs.String("referer", "", "откуда приходит пользователь: direct, none, search-engine, social, same-site или адрес страницы"),
+	}
+}
+
+// newGenerator создает генератор по общим флагам и дополнительным опциям подкоманды
+func (f generatorFlags) newGenerator(extra ...useragent.Option) (*useragent.Generator, error) {
+	opts := extra
+	if *f.offline {
+		opts = append(opts, useragent.WithOfflineMode())
+	}
+	switch *f.cache {
+	case "":
+	case "-":
+		opts = append(opts, useragent.WithDiskCache("", *f.cacheTTL))
+	default:
+		opts = append(opts, useragent.WithDiskCache(*f.cache, *f.cacheTTL))
+	}
+	if *f.browser != "" {
+		browser := useragent.Browser(strings.ToLower(*f.browser))
+		switch browser {
+		case useragent.BrowserChrome, useragent.BrowserEdge, useragent.BrowserFirefox, useragent.BrowserSafari:
+			opts = append(opts, useragent.WithBrowserWeights(map[useragent.Browser]float64{browser: 1}))
+		default:
+			return nil, fmt.Errorf("неизвестный браузер %q", *f.browser)
+		}
+	}
+	if *f.seed != 0 {
+		opts = append(opts, useragent.WithSeed(*f.seed))
+	}
+	if *f.fullVersion {
+		opts = append(opts, useragent.WithFullVersionUA())
+	}
+	if *f.proxy != "" {
+		// WithProxy молча игнорирует неверный адрес, а опечатку в флаге лучше показать сразу
+		if u, err := url.Parse(*f.proxy); err != nil || u.Host == "" {
+			return nil, fmt.Errorf("неверный адрес прокси %q", *f.proxy)
+		}
+		opts = append(opts, useragent.WithProxy(*f.proxy))
+	}
+	if *f.version != "" {
+		// WithStaticVersion молча игнорирует неверную версию, а опечатку в флаге лучше показать сразу
+		if !staticVersionRegex.MatchString(*f.version) {
+			return nil, fmt.Errorf("неверная версия %q, ожидается вида 142.0.7444.60", *f.version)
+		}
+		opts = append(opts, useragent.WithStaticVersion(*f.version))
+	}
+	switch strategy := useragent.RefererStrategy(*f.referer); strategy {
+	case useragent.RefererAuto:
+	case useragent.RefererDirect, useragent.RefererNone, useragent.RefererSearch, useragent.RefererSocial, useragent.RefererSameSite:
+		opts = append(opts, useragent.WithRefererStrategy(strategy))
+	default:
+		if u, err := url.Parse(*f.referer); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return nil, fmt.Errorf("неизвестная стратегия referer %q", *f.referer)
+		}
+		opts = append(opts, useragent.WithRefererStrategy(useragent.RefererCustom, *f.referer))
+	}
+	return useragent.NewGenerator(opts...)
+}
@@ -0,0 +1,321 @@
+// ./cmd/fakeua/proxy.go
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/netip"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/imbecility/go-fake-useragent/useragent"
+	"github.com/imbecility/go-fake-useragent/useragent/httpmw"
+)
+
+const (
+	// срок действия сертификатов хостов, выпущенных прокси
+	proxyCertTTL = 7 * 24 * time.Hour
+	// сколько сертификатов хостов прокси держит в памяти, при переполнении кэш сбрасывается
+	maxProxyCerts = 1024
+)
+
+// runProxy запускает прямой HTTP-прокси, который подставляет заголовки браузера в проходящие запросы:
+// любая программа (curl, старый парсер, код на другом языке) получает их через HTTP_PROXY без изменений кода.
+// запросы http:// переписываются всегда, HTTPS (CONNECT) - только с сертификатом центра -ca-cert,
+// без него туннель передается без изменений
+func runProxy(args []string) error {
+	fs := flag.NewFlagSet("proxy", flag.ExitOnError)
+	genFlags := addGeneratorFlags(fs)
+	addr := fs.String("addr", "127.0.0.1:8080", "адрес, на котором принимаются запросы")
+	fs.StringVar(addr, "listen", "127.0.0.1:8080", "синоним -addr")
+	refresh := fs.String("refresh", "daily@03:00", "расписание обновления версий (пусто - без обновления)")
+	sticky := fs.Bool("sticky", true, "закрепить за каждым хостом один браузер")
+	override := fs.Bool("override", true, "заменять заголовки, заданные клиентом (User-Agent curl и т. п.)")
+	exclude := fs.String("exclude", "", "хосты через запятую, запросы к которым не изменяются (.example.com - с поддоменами)")
+	caCert := fs.String("ca-cert", "", "PEM-сертификат центра для перехвата HTTPS (нужен и -ca-key)")
+	caKey := fs.String("ca-key", "", "PEM-ключ центра для перехвата HTTPS")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 30*time.Second, "сколько ждать завершения текущих запросов при остановке")
+	fs.Parse(args)
+	if (*caCert == "") != (*caKey == "") {
+		return fmt.Errorf("-ca-cert и -ca-key задаются вместе")
+	}
+
+	g, err := genFlags.newGenerator(useragent.WithRefreshSchedule(*refresh, 30*time.Minute))
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+
+	var opts []httpmw.Option
+	if *sticky {
+		opts = append(opts, httpmw.WithStickySessions())
+	}
+	if *override {
+		opts = append(opts, httpmw.WithOverride())
+	}
+	if *exclude != "" {
+		opts = append(opts, httpmw.WithExclude(strings.Split(*exclude, ",")...))
+	}
+	proxy := newForwardProxy(httpmw.New(g, opts...))
+	if *caCert != "" {
+		ca, err := tls.LoadX509KeyPair(*caCert, *caKey)
+		if err != nil {
+			return fmt.Errorf("не удалось загрузить сертификат центра: %w", err)
+		}
+		proxy.ca = &ca
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           proxy,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	var draining atomic.Bool
+	return serveUntilSignal(ctx, server, &draining, *shutdownTimeout)
+}
+
+// forwardProxy прямой HTTP-прокси с подстановкой заголовков Decorator
+type forwardProxy struct {
+	mw      *httpmw.Decorator
+	reverse *httputil.ReverseProxy
+	ca      *tls.Certificate // центр для перехвата HTTPS, nil - CONNECT туннелируется без изменений
+
+	certsMu sync.Mutex
+	certs   map[string]*tls.Certificate // выпущенные сертификаты хостов
+}
+
+// newForwardProxy создает прокси, подставляющий заголовки mw
+func newForwardProxy(mw *httpmw.Decorator) *forwardProxy {
+	p := &forwardProxy{mw: mw, certs: make(map[string]*tls.Certificate)}
+	p.reverse = &httputil.ReverseProxy{
+		Rewrite: p.rewrite,
+		// прокси не должен уходить в прокси из окружения: HTTP_PROXY клиента обычно указывает на него самого
+		Transport: &http.Transport{
+			DialContext:         (&net.Dialer{Timeout: 30 * time.Second}).DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: 10 * time.Second,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+		},
+	}
+	return p
+}
+
+// ServeHTTP обслуживает CONNECT и запросы с абсолютным адресом
+func (p *forwardProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodConnect {
+		p.connect(w, r)
+		return
+	}
+	if !r.URL.IsAbs() || (r.URL.Scheme != "http" && r.URL.Scheme != "https") {
+		http.Error(w, "прокси принимает только запросы с абсолютным http(s) адресом", http.StatusBadRequest)
+		return
+	}
+	p.reverse.ServeHTTP(w, r)
+}
+
+// rewrite готовит исходящий запрос: адрес остается прежним, заголовки браузера подставляет Decorator.
+// accept-encoding клиента сохраняется: тело ответа передается как есть, и сжатие br или zstd, которое
+// клиент не запрашивал, он не смог бы распаковать
+func (p *forwardProxy) rewrite(pr *httputil.ProxyRequest) {
+	pr.Out.Host = ""
+	pr.Out.Header.Del("Proxy-Authorization")
+	encoding := pr.In.Header.Values("Accept-Encoding")
+	p.mw.Apply(pr.Out)
+	pr.Out.Header.Del("Accept-Encoding")
+	for _, value := range encoding {
+		pr.Out.Header.Add("Accept-Encoding", value)
+	}
+}
+
+// connect обслуживает CONNECT: без центра - прозрачный туннель, с центром - перехват TLS
+// сертификатом, выпущенным для хоста, и подстановка заголовков в расшифрованные запросы
+func (p *forwardProxy) connect(w http.ResponseWriter, r *http.Request) {
+	target := r.Host
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		target = net.JoinHostPort(target, "443")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "соединение не поддерживает CONNECT", http.StatusInternalServerError)
+		return
+	}
+
+	var upstream net.Conn
+	if p.ca == nil {
+		var err error
+		if upstream, err = (&net.Dialer{Timeout: 30 * time.Second}).DialContext(r.Context(), "tcp", target); err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+	}
+	client, _, err := hijacker.Hijack()
+	if err != nil {
+		if upstream != nil {
+			upstream.Close()
+		}
+		return
+	}
+	if _, err := io.WriteString(client, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		client.Close()
+		if upstream != nil {
+			upstream.Close()
+		}
+		return
+	}
+
+	if upstream != nil {
+		tunnel(client, upstream)
+		return
+	}
+	p.intercept(client, target)
+}
+
+// tunnel передает данные между соединениями в обе стороны до закрытия любого из них
+func tunnel(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	copyConn := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		done <- struct{}{}
+	}
+	go copyConn(a, b)
+	go copyConn(b, a)
+	<-done
+	a.Close()
+	b.Close()
+	<-done
+}
+
+// intercept завершает TLS клиента сертификатом хоста и обслуживает запросы внутри соединения как https://target
+func (p *forwardProxy) intercept(client net.Conn, target string) {
+	host, _, _ := net.SplitHostPort(target)
+	tlsConn := tls.Server(client, &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			name := hello.ServerName
+			if name == "" {
+				name = host
+			}
+			return p.certificate(name)
+		},
+		NextProtos: []string{"http/1.1"},
+	})
+	listener := newSingleConnListener(tlsConn)
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// адрес берется из CONNECT, а не из host внутреннего запроса: туннель открыт именно к target
+			r.URL.Scheme, r.URL.Host = "https", target
+			p.reverse.ServeHTTP(w, r)
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+		ConnState: func(_ net.Conn, state http.ConnState) {
+			if state == http.StateClosed || state == http.StateHijacked {
+				listener.Close()
+			}
+		},
+	}
+	_ = server.Serve(listener)
+}
+
+// certificate возвращает сертификат для host, подписанный центром прокси
+func (p *forwardProxy) certificate(host string) (*tls.Certificate, error) {
+	host = strings.ToLower(host)
+	p.certsMu.Lock()
+	defer p.certsMu.Unlock()
+	if cert, ok := p.certs[host]; ok && time.Until(cert.Leaf.NotAfter) > time.Hour {
+		return cert, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(proxyCertTTL),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		template.IPAddresses = []net.IP{addr.AsSlice()}
+	} else {
+		template.DNSNames = []string{host}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, p.ca.Leaf, &key.PublicKey, p.ca.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.certs) >= maxProxyCerts {
+		clear(p.certs)
+	}
+	cert := &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+	p.certs[host] = cert
+	return cert, nil
+}
+
+// singleConnListener net.Listener из одного соединения: Accept отдает его один раз,
+// а затем ждет Close, чтобы http.Server.Serve завершился вместе с соединением
+type singleConnListener struct {
+	conn      net.Conn
+	accepted  atomic.Bool
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newSingleConnListener создает listener для conn
+func newSingleConnListener(conn net.Conn) *singleConnListener {
+	return &singleConnListener{conn: conn, done: make(chan struct{})}
+}
+
+// Accept возвращает соединение при первом вызове, затем блокируется до Close
+func (l *singleConnListener) Accept() (net.Conn, error) {
+	if l.accepted.CompareAndSwap(false, true) {
+		return l.conn, nil
+	}
+	<-l.done
+	return nil, net.ErrClosed
+}
+
+// Close завершает ожидание Accept, само соединение закрывает http.Server
+func (l *singleConnListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return nil
+}
+
+// Addr возвращает локальный адрес соединения
+func (l *singleConnListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
@@ -0,0 +1,182 @@
+// ./cmd/fakeua/reload.go
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/imbecility/go-fake-useragent/useragent"
+)
+
+// reloadInitDeadline сколько новый генератор ждет сеть при перезагрузке: версии все равно переносятся
+// из текущего генератора, а ответ источников, пришедший позже, подменит их в фоне
+const reloadInitDeadline = 100 * time.Millisecond
+
+// serveConfig файл конфигурации fakeua serve -config, все поля необязательны.
+// перечитывается по SIGHUP и POST /-/reload без перезапуска процесса
+type serveConfig struct {
+	Locales        []string            `json:"locales"`         // языки браузера, например ["de-DE", "en-US"]
+	Platforms      []string            `json:"platforms"`       // платформы, например ["Windows", "macOS"]
+	BrowserWeights map[string]float64  `json:"browser_weights"` // доли браузеров: chrome, edge, firefox, safari
+	Mirrors        map[string][]string `json:"mirrors"`         // зеркала источников версий: google, microsoft, mozilla
+	SourceRegistry string              `json:"source_registry"` // реестр адресов источников (WithSourceRegistry)
+}
+
+// configSources имена источников версий в serveConfig.Mirrors
+var configSources = map[string]useragent.Source{
+	"google":    useragent.SourceGoogle,
+	"microsoft": useragent.SourceMicrosoft,
+	"mozilla":   useragent.SourceMozilla,
+}
+
+// loadServeConfig читает конфигурацию и преобразует ее в опции генератора. неизвестные поля, браузеры
+// и источники считаются ошибкой: опечатка не должна молча оставить сервис со старыми настройками
+func loadServeConfig(path string) ([]useragent.Option, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config serveConfig
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("неверная конфигурация %s: %w", path, err)
+	}
+
+	var opts []useragent.Option
+	if len(config.Locales) > 0 {
+		opts = append(opts, useragent.WithLocales(config.Locales...))
+	}
+	if len(config.Platforms) > 0 {
+		platforms := make([]useragent.Platform, len(config.Platforms))
+		for i, p := range config.Platforms {
+			platforms[i] = useragent.Platform(p)
+		}
+		opts = append(opts, useragent.WithPlatforms(platforms...))
+	}
+	if len(config.BrowserWeights) > 0 {
+		weights := make(map[useragent.Browser]float64, len(config.BrowserWeights))
+		for name, weight := range config.BrowserWeights {
+			browser := useragent.Browser(strings.ToLower(name))
+			switch browser {
+			case useragent.BrowserChrome, useragent.BrowserEdge, useragent.BrowserFirefox, useragent.BrowserSafari:
+				weights[browser] = weight
+			default:
+				return nil, fmt.Errorf("неизвестный браузер %q в browser_weights", name)
+			}
+		}
+		opts = append(opts, useragent.WithBrowserWeights(weights))
+	}
+	for name, urls := range config.Mirrors {
+		source, ok := configSources[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("неизвестный источник %q в mirrors, доступны: google, microsoft, mozilla", name)
+		}
+		opts = append(opts, useragent.WithMirrors(source, urls...))
+	}
+	if config.SourceRegistry != "" {
+		opts = append(opts, useragent.WithSourceRegistry(config.SourceRegistry, 24*time.Hour))
+	}
+	return opts, nil
+}
+
+// servedGenerator генератор сервиса и его обработчик
+type servedGenerator struct {
+	g       *useragent.Generator
+	handler http.Handler
+}
+
+// reloadableService обработчик fakeua serve, генератор которого пересоздается по новой конфигурации
+// без перезапуска процесса: загруженные версии переносятся в новый генератор через ExportVersions
+type reloadableService struct {
+	configPath string
+	build      func(extra ...useragent.Option) (*useragent.Generator, error)
+
+	reloadMu sync.Mutex // одна перезагрузка за раз
+	current  atomic.Pointer[servedGenerator]
+}
+
+// newReloadableService создает сервис с генератором build и конфигурацией из configPath (пусто - без файла)
+func newReloadableService(configPath string, build func(extra ...useragent.Option) (*useragent.Generator, error)) (*reloadableService, error) {
+	s := &reloadableService{configPath: configPath, build: build}
+	var opts []useragent.Option
+	if configPath != "" {
+		var err error
+		if opts, err = loadServeConfig(configPath); err != nil {
+			return nil, err
+		}
+	}
+	g, err := build(opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.current.Store(&servedGenerator{g: g, handler: useragent.NewHandler(g)})
+	return s, nil
+}
+
+// ServeHTTP передает запрос обработчику текущего генератора, POST /-/reload перечитывает конфигурацию
+func (s *reloadableService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/-/reload" {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeStatus(w, http.StatusMethodNotAllowed, map[string]string{"error": "перезагрузка выполняется запросом POST"})
+			return
+		}
+		if err := s.reload(); err != nil {
+			writeStatus(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeStatus(w, http.StatusOK, map[string]string{"status": "reloaded"})
+		return
+	}
+	s.current.Load().handler.ServeHTTP(w, r)
+}
+
+// reload создает генератор по перечитанной конфигурации, переносит в него версии текущего и подменяет его.
+// при ошибке в конфигурации продолжает работать прежний генератор
+func (s *reloadableService) reload() error {
+	if s.configPath == "" {
+		return fmt.Errorf("сервис запущен без -config, перечитывать нечего")
+	}
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	opts, err := loadServeConfig(s.configPath)
+	if err != nil {
+		return err
+	}
+	old := s.current.Load()
+	g, err := s.build(append(opts, useragent.WithInitDeadline(reloadInitDeadline))...)
+	if err != nil {
+		return err
+	}
+	// с закрепленными версиями импорт отклоняется, а переносить и нечего
+	if versions, err := old.g.ExportVersions(); err == nil {
+		_ = g.ImportVersions(versions)
+	}
+	s.current.Store(&servedGenerator{g: g, handler: useragent.NewHandler(g)})
+	// запросы, начатые до подмены, дорабатывают со старым генератором: Close лишь останавливает фоновое обновление
+	go old.g.Close()
+	return nil
+}
+
+// Close останавливает текущий генератор
+func (s *reloadableService) Close() error {
+	return s.current.Load().g.Close()
+}
+
+// writeStatus отправляет значение в формате JSON с указанным статусом
+func writeStatus(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,297 @@
+// ./cmd/fakeua/shell.go
+
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/imbecility/go-fake-useragent/useragent"
+)
+
+// shellHelp справка интерактивной оболочки
+const shellHelp = `команды:
+  persona [seed]   новая персона: случайная или однозначно заданная строкой seed
+  go <url>         переход на адрес со страницы, открытой последней (первый переход - прямой заход)
+  open <url>       прямой заход на адрес (адресная строка), цепочка переходов начинается заново
+  headers          заголовки последнего запроса в порядке отправки
+  curl             команда curl для последнего запроса
+  har [файл]       запросы персоны в формате HAR (без файла - в стандартный вывод)
+  info             сведения о персоне
+  history          цепочка переходов персоны
+  help             эта справка
+  exit             выход`
+
+// visit запрос, выполненный персоной в оболочке
+type visit struct {
+	url     string
+	headers *useragent.HeaderSet
+	at      time.Time
+}
+
+// shell состояние интерактивной оболочки
+type shell struct {
+	g       *useragent.Generator
+	out     io.Writer
+	session *useragent.Session
+	seed    string
+	visits  []visit
+}
+
+// runShell запускает интерактивную оболочку: чтение команд из стандартного ввода до exit или конца ввода
+func runShell(args []string) error {
+	fs := flag.NewFlagSet("shell", flag.ExitOnError)
+	genFlags := addGeneratorFlags(fs)
+	fs.Parse(args)
+
+	g, err := genFlags.newGenerator()
+	if err != nil {
+		return err
+	}
+	defer g.Close()
+
+	sh := &shell{g: g, out: os.Stdout}
+	sh.newPersona("")
+	fmt.Fprintln(sh.out, "fakeua shell: help - список команд")
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Fprint(sh.out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(sh.out)
+			return scanner.Err()
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "exit" || fields[0] == "quit" {
+			return nil
+		}
+		if err := sh.exec(fields[0], fields[1:]); err != nil {
+			fmt.Fprintln(sh.out, "ошибка:", err)
+		}
+	}
+}
+
+// exec выполняет одну команду оболочки
+func (sh *shell) exec(name string, args []string) error {
+	switch name {
+	case "help":
+		fmt.Fprintln(sh.out, shellHelp)
+	case "persona":
+		sh.newPersona(strings.Join(args, " "))
+	case "go", "open":
+		if len(args) != 1 {
+			return fmt.Errorf("использование: %s <url>", name)
+		}
+		return sh.navigate(args[0], name == "open")
+	case "headers":
+		last, err := sh.last()
+		if err != nil {
+			return err
+		}
+		for _, h := range last.headers.Headers() {
+			fmt.Fprintf(sh.out, "%s: %s\n", h.Name, h.Value)
+		}
+	case "curl":
+		last, err := sh.last()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(sh.out, last.headers.ToCurl(last.url))
+	case "har":
+		return sh.exportHAR(args)
+	case "info":
+		sh.info()
+	case "history":
+		if len(sh.visits) == 0 {
+			fmt.Fprintln(sh.out, "переходов не было")
+		}
+		for i, v := range sh.visits {
+			fmt.Fprintf(sh.out, "%2d. %s\n", i+1, v.url)
+		}
+	default:
+		return fmt.Errorf("неизвестная команда %q, help - список команд", name)
+	}
+	return nil
+}
+
+// newPersona заменяет персону оболочки и сбрасывает цепочку переходов
+func (sh *shell) newPersona(seed string) {
+	if seed == "" {
+		sh.session = sh.g.NewSession()
+	} else {
+		sh.session = sh.g.PersonaFromSeed(seed)
+	}
+	sh.seed = seed
+	sh.visits = nil
+	fmt.Fprintln(sh.out, "персона:", sh.session.UserAgent())
+}
+
+// navigate выполняет переход на адрес и выводит referer и sec-fetch-site полученного запроса
+func (sh *shell) navigate(rawURL string, direct bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("ожидается абсолютный http(s) адрес, получено %q", rawURL)
+	}
+
+	from := ""
+	if !direct && len(sh.visits) > 0 {
+		from = sh.visits[len(sh.visits)-1].url
+	}
+	if direct {
+		sh.visits = nil
+	}
+	headers := sh.session.GetNavigationHeaderSet(from, rawURL)
+	sh.visits = append(sh.visits, visit{url: rawURL, headers: headers, at: time.Now()})
+
+	referer, _ := headers.Get("referer")
+	site, _ := headers.Get("sec-fetch-site")
+	fmt.Fprintf(sh.out, "%s  sec-fetch-site=%s referer=%s\n", rawURL, site, cmp.Or(referer, "-"))
+	return nil
+}
+
+// info выводит сведения о персоне
+func (sh *shell) info() {
+	fmt.Fprintln(sh.out, "user-agent:", sh.session.UserAgent())
+	if sh.seed != "" {
+		fmt.Fprintln(sh.out, "seed:      ", sh.seed)
+	}
+	meta := sh.session.GetHeaderSet().Meta()
+	fmt.Fprintln(sh.out, "браузер:   ", meta.Browser, meta.Version)
+	fmt.Fprintln(sh.out, "платформа: ", meta.Platform)
+	fmt.Fprintln(sh.out, "мобильный: ", meta.Mobile)
+	if device := sh.session.Device(); device.ScreenWidth > 0 {
+		fmt.Fprintf(sh.out, "экран:      %dx%d, вьюпорт %dx%d, DPR %g\n",
+			device.ScreenWidth, device.ScreenHeight, device.ViewportWidth, device.ViewportHeight, device.DPR)
+	}
+}
+
+// last возвращает последний запрос персоны
+func (sh *shell) last() (visit, error) {
+	if len(sh.visits) == 0 {
+		return visit{}, fmt.Errorf("запросов еще не было, используйте go <url>")
+	}
+	return sh.visits[len(sh.visits)-1], nil
+}
+
+// harLog минимальное подмножество формата HAR 1.2, достаточное для импорта в DevTools и прокси
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            int         `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string    `json:"method"`
+	URL         string    `json:"url"`
+	HTTPVersion string    `json:"httpVersion"`
+	Cookies     []harPair `json:"cookies"`
+	Headers     []harPair `json:"headers"`
+	QueryString []harPair `json:"queryString"`
+	HeadersSize int       `json:"headersSize"`
+	BodySize    int       `json:"bodySize"`
+}
+
+// harResponse ответ не выполнялся: поля заполнены значениями "нет данных" по спецификации HAR
+type harResponse struct {
+	Status      int       `json:"status"`
+	StatusText  string    `json:"statusText"`
+	HTTPVersion string    `json:"httpVersion"`
+	Cookies     []harPair `json:"cookies"`
+	Headers     []harPair `json:"headers"`
+	Content     struct {
+		Size     int    `json:"size"`
+		MimeType string `json:"mimeType"`
+	} `json:"content"`
+	RedirectURL string `json:"redirectURL"`
+	HeadersSize int    `json:"headersSize"`
+	BodySize    int    `json:"bodySize"`
+}
+
+type harTimings struct {
+	Send    int `json:"send"`
+	Wait    int `json:"wait"`
+	Receive int `json:"receive"`
+}
+
+type harPair struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// exportHAR выводит запросы персоны в формате HAR в файл или стандартный вывод
+func (sh *shell) exportHAR(args []string) error {
+	if len(sh.visits) == 0 {
+		return fmt.Errorf("запросов еще не было, используйте go <url>")
+	}
+
+	var har harLog
+	har.Log.Version = "1.2"
+	har.Log.Creator = harCreator{Name: "fakeua", Version: "1"}
+	for _, v := range sh.visits {
+		entry := harEntry{StartedDateTime: v.at.Format(time.RFC3339Nano)}
+		entry.Request = harRequest{
+			Method:      "GET",
+			URL:         v.url,
+			HTTPVersion: "HTTP/2",
+			Cookies:     []harPair{},
+			Headers:     []harPair{},
+			QueryString: []harPair{},
+			HeadersSize: -1,
+		}
+		for _, h := range v.headers.Headers() {
+			entry.Request.Headers = append(entry.Request.Headers, harPair{Name: h.Name, Value: h.Value})
+		}
+		if u, err := url.Parse(v.url); err == nil {
+			for name, values := range u.Query() {
+				for _, value := range values {
+					entry.Request.QueryString = append(entry.Request.QueryString, harPair{Name: name, Value: value})
+				}
+			}
+		}
+		entry.Response = harResponse{Cookies: []harPair{}, Headers: []harPair{}, HeadersSize: -1, BodySize: -1}
+		entry.Response.Content.MimeType = "x-unknown"
+		entry.Timings = harTimings{}
+		har.Log.Entries = append(har.Log.Entries, entry)
+	}
+
+	data, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		fmt.Fprintln(sh.out, string(data))
+		return nil
+	}
+	if err := os.WriteFile(args[0], data, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(sh.out, "сохранено запросов: %d в %s\n", len(har.Log.Entries), args[0])
+	return nil
+}
@@ -0,0 +1,266 @@
+// ./cmd/uabench/main.go
+
+// утилита замеряет производительность основных операций go-fake-useragent (Get, GetHeaders, сессии,
+// загрузка кэша, получение версий из сети) и сравнивает результаты с сохраненным базовым замером:
+//
+//	go run ./cmd/uabench -save baseline.json              // базовый замер до изменений
+//	go run ./cmd/uabench -compare baseline.json -max 20   // после изменений: код выхода 1 при замедлении более 20%
+//
+// сетевые источники подменяются локальным транспортом, поэтому замеры не зависят от сети.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imbecility/go-fake-useragent/useragent"
+)
+
+// result результат одного замера
+type result struct {
+	NsPerOp     int64 `json:"ns_per_op"`
+	AllocsPerOp int64 `json:"allocs_per_op"`
+	BytesPerOp  int64 `json:"bytes_per_op"`
+}
+
+// benchmark именованный замер
+type benchmark struct {
+	name string
+	fn   func(b *testing.B)
+}
+
+func main() {
+	save := flag.String("save", "", "сохранить результаты в JSON-файл")
+	compare := flag.String("compare", "", "сравнить результаты с JSON-файлом базового замера")
+	maxRegression := flag.Float64("max", 20, "допустимое замедление ns/op в процентах при -compare")
+	filter := flag.String("run", "", "запускать только замеры, имя которых содержит подстроку")
+	flag.Parse()
+
+	benchmarks, cleanup, err := benchmarks()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ошибка подготовки замеров:", err)
+		os.Exit(2)
+	}
+	defer cleanup()
+
+	results := make(map[string]result)
+	for _, bm := range benchmarks {
+		if !strings.Contains(bm.name, *filter) {
+			continue
+		}
+		r := testing.Benchmark(bm.fn)
+		results[bm.name] = result{NsPerOp: r.NsPerOp(), AllocsPerOp: r.AllocsPerOp(), BytesPerOp: r.AllocedBytesPerOp()}
+		fmt.Printf("%-28s %12d ns/op %10d B/op %8d allocs/op\n", bm.name, r.NsPerOp(), r.AllocedBytesPerOp(), r.AllocsPerOp())
+	}
+
+	if *save != "" {
+		data, _ := json.MarshalIndent(results, "", "  ")
+		if err := os.WriteFile(*save, data, 0o644); err != nil {
+			fmt.Fprintln(os.Stderr, "не удалось сохранить результаты:", err)
+			os.Exit(2)
+		}
+	}
+	if *compare != "" {
+		regressed, err := compareWith(*compare, results, *maxRegression)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "не удалось сравнить результаты:", err)
+			os.Exit(2)
+		}
+		if regressed {
+			os.Exit(1)
+		}
+	}
+}
+
+// compareWith выводит изменения относительно базового замера и сообщает, есть ли замедление сверх допустимого
+func compareWith(path string, results map[string]result, maxRegression float64) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	var baseline map[string]result
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return false, err
+	}
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println()
+	regressed := false
+	for _, name := range names {
+		base, ok := baseline[name]
+		if !ok || base.NsPerOp == 0 {
+			fmt.Printf("%-28s нет в базовом замере\n", name)
+			continue
+		}
+		cur := results[name]
+		delta := float64(cur.NsPerOp-base.NsPerOp) / float64(base.NsPerOp) * 100
+		mark := ""
+		if delta > maxRegression {
+			mark = "  ЗАМЕДЛЕНИЕ"
+			regressed = true
+		}
+		fmt.Printf("%-28s %12d -> %12d ns/op (%+.1f%%), allocs %d -> %d%s\n",
+			name, base.NsPerOp, cur.NsPerOp, delta, base.AllocsPerOp, cur.AllocsPerOp, mark)
+	}
+	return regressed, nil
+}
+
+// benchmarks готовит генераторы и возвращает список замеров
+func benchmarks() ([]benchmark, func(), error) {
+	dir, err := os.MkdirTemp("", "uabench")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) }
+	cachePath := filepath.Join(dir, "versions.json")
+
+	// генератор с версиями из "сети" заодно записывает дисковый кэш для замера его загрузки
+	client := &http.Client{Transport: fakeSources{}, Timeout: 5 * time.Second}
+	gen, err := useragent.NewGenerator(
+		useragent.WithHTTPClient(client),
+		useragent.WithDiskCache(cachePath, time.Hour),
+		useragent.WithBrowserWeights(useragent.MarketShareWeights),
+	)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	// генератор с пулом строк WithUAPool для сравнения с обычным Get
+	pooled, err := useragent.NewGenerator(
+		useragent.WithDiskCache(cachePath, time.Hour),
+		useragent.WithOfflineMode(),
+		useragent.WithBrowserWeights(useragent.MarketShareWeights),
+		useragent.WithUAPool(4096),
+	)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	cleanup = func() {
+		_ = pooled.Close()
+		_ = os.RemoveAll(dir)
+	}
+	// сессия из seed: стоимость заголовков зависит от браузера, и он не должен меняться между запусками
+	session := gen.PersonaFromSeed("uabench")
+	const target = "https://shop.example.com/item/42"
+
+	return []benchmark{
+		{"Get", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				gen.Get()
+			}
+		}},
+		{"GetParallel", func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					gen.Get()
+				}
+			})
+		}},
+		{"AppendUA", func(b *testing.B) {
+			b.ReportAllocs()
+			buf := make([]byte, 0, 256)
+			for b.Loop() {
+				buf = gen.AppendUA(buf[:0])
+			}
+		}},
+		{"GetPooledParallel", func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					pooled.Get()
+				}
+			})
+		}},
+		{"GetHeaders", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				gen.GetHeaders(target)
+			}
+		}},
+		{"GetHeadersParallel", func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					gen.GetHeaders(target)
+				}
+			})
+		}},
+		{"NewSession", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				gen.NewSession()
+			}
+		}},
+		{"PersonaFromSeed", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				gen.PersonaFromSeed("account-42")
+			}
+		}},
+		{"SessionGetHeaders", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				session.GetHeaders(target)
+			}
+		}},
+		{"NewGeneratorFromCache", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				g, err := useragent.NewGenerator(useragent.WithDiskCache(cachePath, time.Hour), useragent.WithOfflineMode())
+				if err != nil {
+					b.Fatal(err)
+				}
+				_ = g.Close()
+			}
+		}},
+		{"NewGeneratorFromNetwork", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				g, err := useragent.NewGenerator(useragent.WithHTTPClient(client))
+				if err != nil {
+					b.Fatal(err)
+				}
+				_ = g.Close()
+			}
+		}},
+	}, cleanup, nil
+}
+
+// googleVersionsResponse ответ Google Versions API с несколькими релизами Chrome
+const googleVersionsResponse = `{"releases":[{"version":"142.0.7444.59"},{"version":"142.0.7444.52"},` +
+	`{"version":"141.0.7390.122"},{"version":"141.0.7390.108"},{"version":"141.0.7390.76"}]}`
+
+// fakeSources локальный транспорт вместо сетевых источников: Google отвечает списком версий,
+// остальные источники - ошибкой 404 (генератор берет первый успешный ответ)
+type fakeSources struct{}
+
+func (fakeSources) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, body := http.StatusNotFound, ""
+	if strings.Contains(req.URL.Host, "googleapis.com") {
+		status, body = http.StatusOK, googleVersionsResponse
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,136 @@
+// ./cmd/wasm/main.go
+
+//go:build js && wasm
+
+// пакет собирает генератор в WebAssembly (GOOS=js) и экспортирует его в JavaScript через syscall/js:
+// Node.js и браузерные инструменты получают те же User-Agent и заголовки, что и Go, без разделяемой библиотеки CGo.
+//
+//	GOOS=js GOARCH=wasm go build -o useragent.wasm ./cmd/wasm
+//
+// после запуска модуля (wasm_exec.js из $(go env GOROOT)/lib/wasm) в globalThis.fakeUserAgent доступны:
+//   - init(options) - Promise, создает генератор с параметрами {offline, locales, platforms, seed}
+//   - get() - случайный User-Agent
+//   - getHeaders(url) - объект с заголовками браузера для url
+package main
+
+import (
+	"encoding/json"
+	"sync"
+	"syscall/js"
+
+	ua "github.com/imbecility/go-fake-useragent/useragent"
+)
+
+// initOptions параметры fakeUserAgent.init, все поля необязательны
+type initOptions struct {
+	Offline   bool     `json:"offline"`   // не обращаться к сети: версии из кэша или аппроксимация по дате
+	Locales   []string `json:"locales"`   // языки браузера (WithLocales)
+	Platforms []string `json:"platforms"` // платформы (WithPlatforms)
+	Seed      *uint64  `json:"seed"`      // seed для воспроизводимой последовательности (WithSeed)
+}
+
+var (
+	generator   *ua.Generator // генератор, созданный init или при первом вызове get/getHeaders
+	generatorMu sync.Mutex    // защита generator: init выполняется в отдельной горутине
+)
+
+// main регистрирует функции в globalThis.fakeUserAgent и не завершается, пока страница или процесс Node.js живы
+func main() {
+	js.Global().Set("fakeUserAgent", js.ValueOf(map[string]any{
+		"init":       js.FuncOf(initGenerator),
+		"get":        js.FuncOf(get),
+		"getHeaders": js.FuncOf(getHeaders),
+	}))
+	select {}
+}
+
+// initGenerator fakeUserAgent.init(options): возвращает Promise, так как получение версий из сети
+// блокирует, а обработчик вызова из JavaScript блокироваться не должен. при ошибке Promise отклоняется
+func initGenerator(_ js.Value, args []js.Value) any {
+	var opts initOptions
+	if len(args) > 0 && args[0].Type() == js.TypeObject {
+		raw := js.Global().Get("JSON").Call("stringify", args[0]).String()
+		if err := json.Unmarshal([]byte(raw), &opts); err != nil {
+			return rejected(err)
+		}
+	}
+
+	var executor js.Func
+	executor = js.FuncOf(func(_ js.Value, args []js.Value) any {
+		resolve, reject := args[0], args[1]
+		go func() {
+			gen, err := ua.NewGenerator(opts.options()...)
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			generatorMu.Lock()
+			previous := generator
+			generator = gen
+			generatorMu.Unlock()
+			if previous != nil {
+				_ = previous.Close()
+			}
+			resolve.Invoke()
+		}()
+		return nil
+	})
+	defer executor.Release() // Promise вызывает executor синхронно в конструкторе
+	return js.Global().Get("Promise").New(executor)
+}
+
+// options преобразует параметры init в опции генератора
+func (o initOptions) options() []ua.Option {
+	var opts []ua.Option
+	if o.Offline {
+		opts = append(opts, ua.WithOfflineMode())
+	}
+	if len(o.Locales) > 0 {
+		opts = append(opts, ua.WithLocales(o.Locales...))
+	}
+	if len(o.Platforms) > 0 {
+		platforms := make([]ua.Platform, len(o.Platforms))
+		for i, p := range o.Platforms {
+			platforms[i] = ua.Platform(p)
+		}
+		opts = append(opts, ua.WithPlatforms(platforms...))
+	}
+	if o.Seed != nil {
+		opts = append(opts, ua.WithSeed(*o.Seed))
+	}
+	return opts
+}
+
+// rejected возвращает отклоненный Promise с ошибкой err
+func rejected(err error) js.Value {
+	return js.Global().Get("Promise").Call("reject", js.Global().Get("Error").New(err.Error()))
+}
+
+// current возвращает генератор, без вызова init - офлайн-генератор с настройками по умолчанию:
+// он не обращается к сети, поэтому создается синхронно
+func current() *ua.Generator {
+	generatorMu.Lock()
+	defer generatorMu.Unlock()
+	if generator == nil {
+		generator, _ = ua.NewGenerator(ua.WithOfflineMode())
+	}
+	return generator
+}
+
+// get fakeUserAgent.get(): случайный User-Agent
+func get(_ js.Value, _ []js.Value) any {
+	return current().Get()
+}
+
+// getHeaders fakeUserAgent.getHeaders(url): заголовки браузера для url (без аргумента - для прямого захода)
+func getHeaders(_ js.Value, args []js.Value) any {
+	var targetURL string
+	if len(args) > 0 && args[0].Type() == js.TypeString {
+		targetURL = args[0].String()
+	}
+	headers := make(map[string]any)
+	for name, value := range current().GetHeaders(targetURL) {
+		headers[name] = value
+	}
+	return js.ValueOf(headers)
+}
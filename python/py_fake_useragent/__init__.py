@@ -5,12 +5,12 @@ import sys
 from enum import IntEnum
 from logging import Logger
 from pathlib import Path
-from typing import Dict, Optional
+from typing import Any, Dict, List, Optional
 from warnings import warn
 
 from .exceptions import (
-    BufferTooSmallError, InitializationError, JsonMarshalError, LibraryLoadError,
-    NotInitializedError, UnknownCrawlerTypeError, UserAgentException
+    BufferTooSmallError, InitializationError, InvalidHandleError, InvalidOptionsError, JsonMarshalError,
+    LibraryLoadError, NotInitializedError, UnknownCrawlerTypeError, UserAgentException
 )
 
 LOG_CALLBACK_TYPE = ctypes.CFUNCTYPE(None, ctypes.c_char_p)
@@ -21,6 +21,20 @@ class CrawlerType(IntEnum):
     GOOGLE = 0
     BING = 1
     YANDEX = 2
+    DUCKDUCKGO = 3
+    BAIDU = 4
+    APPLE = 5
+    SOGOU = 6
+
+
+def shutdown():
+    """
+    останавливает Go-библиотеку: генераторы, фоновое обновление версий и передачу логов.
+    открытые экземпляры UserAgent после этого не работают, а следующий созданный экземпляр
+    инициализирует библиотеку заново, в том числе с другими параметрами (например, между тестами)
+    """
+    if _GLOBAL_LIB:
+        _GLOBAL_LIB.Shutdown()
 
 
 class UserAgent:
@@ -33,24 +47,62 @@ class UserAgent:
 
     Args:
         use_disk_cache (bool): Использовать ли дисковый кэш для хранения данных.
-        cache_ttl_days (int): The second parameter.
+        cache_ttl_days (int): Время жизни дискового кэша в днях. 0, как и в Initialize, - кэш записывается,
+            но никогда не считается актуальным, и версии запрашиваются при каждом запуске.
         logger (Logger): Опциональный экземпляр Logger для приёма логов из Go-библиотеки.
+        offline (bool): Запретить библиотеке сетевые запросы (только кэш и аппроксимация версий).
+        isolated (bool): Создать отдельный генератор со своей конфигурацией вместо общего для процесса:
+            несколько экземпляров с разными locales, platforms и cache_path работают независимо.
+            для отдельного генератора доступны только `get`, `get_headers`, `get_headers_ex` и `release_persona`.
+        cache_path (str): Путь к файлу дискового кэша.
+        locales (list[str]): Языки браузера, например ['de-DE'].
+        platforms (list[str]): Платформы, например ['Windows', 'macOS'].
+        proxy (str): Прокси для источников версий, например 'socks5://127.0.0.1:1080'.
+        request_timeout (float): Таймаут запроса к источникам версий в секундах (по умолчанию 15).
+        init_deadline (float): Сколько секунд инициализация ждет сеть, прежде чем аппроксимировать версии,
+            полученные позже версии подменяют аппроксимацию в фоне.
+        log_level (int): Минимальный уровень логов в терминах модуля logging (по умолчанию logging.DEBUG),
+            общий для процесса, не применяется с isolated.
+
+    общий генератор настраивается первым созданным экземпляром: параметры следующих экземпляров
+    не действуют до вызова `shutdown()`.
 
     Raises:
         LibraryLoadError: библиотека не найдена или не удалось загрузить
         InitializationError: инициализация библиотеки завершилась с ошибкой
     """
 
-    def __init__(self, use_disk_cache: bool = True, cache_ttl_days: int = 1, logger: Optional[Logger] = None):
+    def __init__(self, use_disk_cache: bool = True, cache_ttl_days: int = 1, logger: Optional[Logger] = None,
+                 offline: bool = False, *, isolated: bool = False, cache_path: Optional[str] = None,
+                 locales: Optional[List[str]] = None, platforms: Optional[List[str]] = None,
+                 proxy: Optional[str] = None, request_timeout: Optional[float] = None,
+                 init_deadline: Optional[float] = None, log_level: Optional[int] = None):
         self._lib = self._load_library()
         self._define_signatures()
         self._is_closed = False
         self._c_log_callback = None
+        self._handle: Optional[int] = None
 
         if logger:
             self._setup_logging(logger)
 
-        result = self._lib.Initialize(use_disk_cache, cache_ttl_days)
+        config = {'use_cache': use_disk_cache, 'cache_ttl_days': cache_ttl_days, 'offline': offline}
+        if cache_path: config['cache_path'] = cache_path
+        if locales: config['locales'] = locales
+        if platforms: config['platforms'] = platforms
+        if proxy: config['proxy'] = proxy
+        if request_timeout: config['request_timeout_seconds'] = request_timeout
+        if init_deadline: config['init_deadline_seconds'] = init_deadline
+        if log_level is not None: config['log_level'] = f'INFO{self._slog_level(log_level):+d}'
+
+        if isolated:
+            handle = self._lib.CreateGenerator(json.dumps(config).encode('utf-8'))
+            if handle < 0:
+                self._handle_error_code(handle)
+            self._handle = handle
+            return
+
+        result = self._lib.InitializeWithConfig(json.dumps(config).encode('utf-8'))
         if result != 0:
             self._handle_error_code(result)
 
@@ -93,15 +145,34 @@ class UserAgent:
         """
         self._lib.Initialize.argtypes = [ctypes.c_bool, ctypes.c_int]
         self._lib.Initialize.restype = ctypes.c_int
+        self._lib.InitializeWithConfig.argtypes = [ctypes.c_char_p]
+        self._lib.InitializeWithConfig.restype = ctypes.c_int
         self._lib.Shutdown.restype = None
         self._lib.SetLoggerCallback.argtypes = [ctypes.c_void_p]
         self._lib.GetDroppedLogs.restype = ctypes.c_ulonglong
+        self._lib.ResetDroppedLogs.restype = ctypes.c_ulonglong
+        self._lib.SetLogLevel.argtypes = [ctypes.c_int]
+        self._lib.SetLogLevel.restype = None
+        self._lib.ReleasePersona.argtypes = [ctypes.c_char_p]
+        self._lib.ReleasePersona.restype = None
+        self._lib.ReleasePersonaFrom.argtypes = [ctypes.c_longlong, ctypes.c_char_p]
+        self._lib.ReleasePersonaFrom.restype = None
+        self._lib.CreateGenerator.argtypes = [ctypes.c_char_p]
+        self._lib.CreateGenerator.restype = ctypes.c_longlong
+        self._lib.DestroyGenerator.argtypes = [ctypes.c_longlong]
+        self._lib.DestroyGenerator.restype = ctypes.c_int
 
         # словарь с описанием аргументов для функций, возвращающих данные в буфер
         arg_types = {
             'GetRandomUA': [ctypes.c_void_p, ctypes.c_size_t],
             'GetHeaders': [ctypes.c_char_p, ctypes.c_void_p, ctypes.c_size_t],
+            'GetHeadersEx': [ctypes.c_char_p, ctypes.c_void_p, ctypes.c_size_t],
             'GetCrawlerHeaders': [ctypes.c_int, ctypes.c_void_p, ctypes.c_size_t],
+            'GetRuntimeStats': [ctypes.c_void_p, ctypes.c_size_t],
+            'GetVersions': [ctypes.c_void_p, ctypes.c_size_t],
+            'GetRandomUAFrom': [ctypes.c_longlong, ctypes.c_void_p, ctypes.c_size_t],
+            'GetHeadersFrom': [ctypes.c_longlong, ctypes.c_char_p, ctypes.c_void_p, ctypes.c_size_t],
+            'GetHeadersExFrom': [ctypes.c_longlong, ctypes.c_char_p, ctypes.c_void_p, ctypes.c_size_t],
         }
         for name, types in arg_types.items():
             func = getattr(self._lib, name)
@@ -146,7 +217,7 @@ class UserAgent:
 
         """
         error_map = {-1: NotInitializedError, -2: JsonMarshalError, -3: UnknownCrawlerTypeError,
-                     -4: InitializationError}
+                     -4: InitializationError, -5: InvalidOptionsError, -6: InvalidHandleError}
         exc_class = error_map.get(code, UserAgentException)
         raise exc_class(f'внутренняя ошибка внешней библиотеки, код: {code}')
 
@@ -193,6 +264,8 @@ class UserAgent:
         Returns:
             str строка User-Agent
         """
+        if self._handle is not None:
+            return self._call_go_with_buffer(self._lib.GetRandomUAFrom, self._handle)
         return self._call_go_with_buffer(self._lib.GetRandomUA)
 
     def get_headers(self, url: str = '') -> dict[str, str]:
@@ -207,15 +280,63 @@ class UserAgent:
         Returns:
             dict словарь с заголовками браузера
         """
-        json_str = self._call_go_with_buffer(self._lib.GetHeaders, url.encode('utf-8'), initial_size=2048)
+        if self._handle is not None:
+            json_str = self._call_go_with_buffer(self._lib.GetHeadersFrom, self._handle, url.encode('utf-8'),
+                                                 initial_size=2048)
+        else:
+            json_str = self._call_go_with_buffer(self._lib.GetHeaders, url.encode('utf-8'), initial_size=2048)
+        return json.loads(json_str)
+
+    def get_headers_ex(self, url: str = '', *, from_url: Optional[str] = None, referrer_policy: Optional[str] = None,
+                       persona: Optional[str] = None, resource: Optional[str] = None,
+                       locale: Optional[str] = None) -> Dict[str, str]:
+        """
+        генерирует заголовки браузера с дополнительными параметрами вызова
+
+        Args:
+            url (str): целевая ссылка для последующего запроса
+            from_url (str): страница, с которой совершается переход ('' - прямой заход из адресной строки)
+            referrer_policy (str): политика формирования referer ('origin', 'no-referrer', 'unsafe-url'...)
+            persona (str): идентификатор персоны: для одного идентификатора всегда один и тот же браузер
+            resource (str): тип ресурса ('image', 'script', 'style', 'font', 'fetch', 'video', 'audio', 'iframe'),
+                который страница from_url загружает по адресу url: accept и sec-fetch-* как у браузера
+            locale (str): язык этого запроса ('de-DE'): accept-language строится для него вместо языков генератора
+
+        Returns:
+            dict словарь с заголовками браузера
+        """
+        options = {'url': url}
+        if from_url is not None: options['from_url'] = from_url
+        if referrer_policy: options['referrer_policy'] = referrer_policy
+        if persona: options['persona'] = persona
+        if resource: options['resource'] = resource
+        if locale: options['locale'] = locale
+        if self._handle is not None:
+            json_str = self._call_go_with_buffer(self._lib.GetHeadersExFrom, self._handle,
+                                                 json.dumps(options).encode('utf-8'), initial_size=2048)
+        else:
+            json_str = self._call_go_with_buffer(self._lib.GetHeadersEx, json.dumps(options).encode('utf-8'),
+                                                 initial_size=2048)
         return json.loads(json_str)
 
+    def release_persona(self, persona: str):
+        """
+        забывает персону: следующий вызов get_headers_ex с тем же идентификатором получит новый браузер
+
+        Args:
+            persona (str): идентификатор персоны
+        """
+        if self._handle is not None:
+            self._lib.ReleasePersonaFrom(self._handle, persona.encode('utf-8'))
+        else:
+            self._lib.ReleasePersona(persona.encode('utf-8'))
+
     def get_crawler_headers(self, crawler: CrawlerType) -> Dict[str, str]:
         """
         генерирует заголовки поисковых роботов
 
         Args:
-            crawler (CrawlerType): тип поискового робота (GOOGLE, BING, YANDEX)
+            crawler (CrawlerType): тип поискового робота (GOOGLE, BING, YANDEX, DUCKDUCKGO, BAIDU, APPLE, SOGOU)
 
         Returns:
             dict словарь с заголовками краулера
@@ -229,6 +350,54 @@ class UserAgent:
         """
         return self._lib.GetDroppedLogs()
 
+    def reset_dropped_logs(self) -> int:
+        """
+        обнуляет счетчик отброшенных сообщений лога (для использования как метрики за интервал)
+
+        Returns:
+            int количество отброшенных сообщений до обнуления
+        """
+        return self._lib.ResetDroppedLogs()
+
+    def get_runtime_stats(self) -> Dict[str, Any]:
+        """
+        возвращает сведения о состоянии Go-стороны для мониторинга долгоживущих сервисов:
+        goroutines, log_queue, log_queue_cap, dropped_logs, personas, generators, heap_bytes, versions,
+        versions_origin, cache_age_seconds и last_refresh ({'at', 'ok', 'error'})
+
+        Returns:
+            dict словарь со сведениями
+        """
+        json_str = self._call_go_with_buffer(self._lib.GetRuntimeStats, initial_size=1024)
+        return json.loads(json_str)
+
+    def get_versions(self) -> Dict[str, Any]:
+        """
+        возвращает версии браузеров, которые сейчас использует библиотека: versions (набор Chromium),
+        browsers (версии по семействам chrome, edge, firefox, safari), origin (cache, network,
+        approximation, import, static или snapshot) и versions_at (момент получения набора, ISO 8601)
+
+        Returns:
+            dict словарь с версиями
+        """
+        json_str = self._call_go_with_buffer(self._lib.GetVersions, initial_size=1024)
+        return json.loads(json_str)
+
+    def set_log_level(self, level: int):
+        """
+        устанавливает минимальный уровень логов, передаваемых из библиотеки
+
+        Args:
+            level (int): уровень в терминах модуля logging (logging.DEBUG, logging.INFO...)
+        """
+        self._lib.SetLogLevel(self._slog_level(level))
+
+    @staticmethod
+    def _slog_level(level: int) -> int:
+        """переводит уровень модуля logging в уровень slog"""
+        # logging: DEBUG=10, INFO=20, WARNING=30, ERROR=40 -> slog: DEBUG=-4, INFO=0, WARN=4, ERROR=8
+        return (level - 20) * 4 // 10
+
     def close(self):
         """
         закрывает экземпляр, останавливает обмен логами и высвобождает ресурсы
@@ -237,6 +406,9 @@ class UserAgent:
             if self._c_log_callback:
                 self._lib.SetLoggerCallback(None)
                 self._c_log_callback = None
+            if self._handle is not None:
+                self._lib.DestroyGenerator(self._handle)
+                self._handle = None
             self._is_closed = True
 
     def __enter__(self):
@@ -6,4 +6,6 @@ class InitializationError(UserAgentException): ...
 class NotInitializedError(UserAgentException): ...
 class BufferTooSmallError(UserAgentException): ...
 class JsonMarshalError(UserAgentException): ...
-class UnknownCrawlerTypeError(UserAgentException): ...
\ No newline at end of file
+class UnknownCrawlerTypeError(UserAgentException): ...
+class InvalidOptionsError(UserAgentException): ...
+class InvalidHandleError(UserAgentException): ...
\ No newline at end of file
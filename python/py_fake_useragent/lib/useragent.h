@@ -12,6 +12,8 @@
 
 #ifndef GO_CGO_GOSTRING_TYPEDEF
 typedef struct { const char *p; ptrdiff_t n; } _GoString_;
+extern size_t _GoStringLen(_GoString_ s);
+extern const char *_GoStringPtr(_GoString_ s);
 #endif
 
 #endif
@@ -19,7 +21,7 @@ typedef struct { const char *p; ptrdiff_t n; } _GoString_;
 /* Start of preamble from import "C" comments.  */
 
 
-#line 9 "main.go"
+#line 11 "main.go"
 
 #include <stdlib.h>
 #include <stdbool.h>
@@ -62,10 +64,16 @@ typedef size_t GoUintptr;
 typedef float GoFloat32;
 typedef double GoFloat64;
 #ifdef _MSC_VER
+#if !defined(__cplusplus) || _MSVC_LANG <= 201402L
 #include <complex.h>
 typedef _Fcomplex GoComplex64;
 typedef _Dcomplex GoComplex128;
 #else
+#include <complex>
+typedef std::complex<float> GoComplex64;
+typedef std::complex<double> GoComplex128;
+#endif
+#else
 typedef float _Complex GoComplex64;
 typedef double _Complex GoComplex128;
 #endif
@@ -92,76 +100,26 @@ typedef struct { void *data; GoInt len; GoInt cap; } GoSlice;
 extern "C" {
 #endif
 
-
-// Initialize экспортируется в C, однократно инициализирует глобальный генератор user-agent'ов
-//
-// потокобезопасна благодаря `sync.Once`
-//
-// параметры:
-//   - useCache: C.bool (true), если нужно использовать дисковый кеш
-//   - cacheTTLDays: C.int, время жизни (TTL) кеша в днях
-//
-// возвращает:
-//   - C.int: 0 (ErrSuccess) в случае успеха, или код ошибки
-//
-extern __declspec(dllexport) int Initialize(_Bool useCache, int cacheTTLDays);
-
-// Shutdown экспортируется в C, корректно завершает работу библиотеки,
-// отключает логирование и дожидается завершения обработки всех оставшихся логов,
-// потокобезопасна благодаря `sync.Once`.
-//
-extern __declspec(dllexport) void Shutdown();
-
-// SetLoggerCallback экспортируется в C, устанавливает функцию обратного вызова для получения логов.
-//
-// параметры:
-//   - callback: указатель на функцию типа `log_callback_f` в Python.
-//     NULL - отключает
-//
-extern __declspec(dllexport) void SetLoggerCallback(log_callback_f callback);
-
-// GetDroppedLogs экспортируется в C, возвращает количество отброшенных из-за переполнения сообщений лога
-//
-// возвращает:
-//   - C.ulonglong: количество отброшенных логов
-//
-extern __declspec(dllexport) long long unsigned int GetDroppedLogs();
-
-// GetRandomUA экспортируется в C, генерирует случайный User-Agent
-// использует паттерн двойного вызова с помощью copyToBuffer
-//
-// параметры:
-//   - buffer: указатель на буфер для записи строки User-Agent
-//   - length: размер буфера.
-//
-// возвращает:
-//   - C.int: код ошибки, требуемый размер буфера или количество скопированных байт
-//
-extern __declspec(dllexport) int GetRandomUA(char* buffer, size_t length);
-
-// GetHeaders экспортируется в C, генерирует заголовки для HTTP-запроса, возвращает их в виде JSON-строки
-//
-// параметры:
-//   - url: URL, для которого генерируются заголовки или NULL
-//   - buffer: указатель на буфер для записи JSON-строки
-//   - length: размер буфера
-//
-// возвращает:
-//   - C.int: код ошибки, требуемый размер буфера или количество скопированных байт.
-//
-extern __declspec(dllexport) int GetHeaders(char* url, char* buffer, size_t length);
-
-// GetCrawlerHeaders экспортируется в C, генерирует заголовки под поискового робота, возвращает их в виде JSON-строки
-//
-// параметры:
-//   - crawlerType: тип робота (0: google, 1: bing, 2: yandex)
-//   - buffer: указатель на буфер для записи JSON-строки
-//   - length: размер буфера
-//
-// возвращает:
-//   - C.int: код ошибки, требуемый размер буфера или количество скопированных байт
-//
-extern __declspec(dllexport) int GetCrawlerHeaders(int crawlerType, char* buffer, size_t length);
+extern int Initialize(_Bool useCache, int cacheTTLDays);
+extern int InitializeWithConfig(char* configJSON);
+extern void Shutdown(void);
+extern long long int CreateGenerator(char* configJSON);
+extern int DestroyGenerator(long long int handle);
+extern void SetLoggerCallback(log_callback_f callback);
+extern long long unsigned int GetDroppedLogs(void);
+extern long long unsigned int ResetDroppedLogs(void);
+extern void SetLogLevel(int level);
+extern int GetRandomUA(char* buffer, size_t length);
+extern int GetRandomUAFrom(long long int handle, char* buffer, size_t length);
+extern int GetHeaders(char* url, char* buffer, size_t length);
+extern int GetHeadersFrom(long long int handle, char* url, char* buffer, size_t length);
+extern int GetHeadersEx(char* optionsJSON, char* buffer, size_t length);
+extern int GetHeadersExFrom(long long int handle, char* optionsJSON, char* buffer, size_t length);
+extern void ReleasePersona(char* id);
+extern void ReleasePersonaFrom(long long int handle, char* id);
+extern int GetCrawlerHeaders(int crawlerType, char* buffer, size_t length);
+extern int GetRuntimeStats(char* buffer, size_t length);
+extern int GetVersions(char* buffer, size_t length);
 
 #ifdef __cplusplus
 }
@@ -48,6 +48,10 @@ def compile_go_library():
         'go', 'build', '-buildmode=c-shared', '-trimpath', '-buildvcs=false',
         '-ldflags', "-s -w", '-o', str(output_path), str(go_package_path)
     ]
+    # GO_BUILD_TAGS=nonet собирает библиотеку без сетевых запросов (меньше размером, только кэш и аппроксимация)
+    build_tags = os.environ.get('GO_BUILD_TAGS')
+    if build_tags:
+        cmd[2:2] = ['-tags', build_tags]
     print(f"[*] вполнение команды: {' '.join(cmd)}")
     process = subprocess.run(cmd, check=False, capture_output=True, text=True, encoding='utf-8', env=build_env)
     if process.returncode != 0:
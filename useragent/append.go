@@ -0,0 +1,23 @@
+// append.go запись User-Agent в буфер вызывающего кода для горячих циклов
+
+package useragent
+
+// AppendUA дописывает к dst строку User-Agent, выбранную как в Get, и возвращает расширенный срез.
+// в горячем цикле буфер переиспользуется (dst[:0]), и для десктопных Chrome и Edge вызов не выделяет память:
+// строка собирается прямо в dst, без fmt.Sprintf и промежуточной строки.
+// строки Firefox, Safari, мобильного Chrome и внешнего списка дописываются готовыми,
+// с WithNoRepeat и WithUAPool строка берется из колоды или пула, как в Get
+//
+//	buf := make([]byte, 0, 256)
+//	for _, req := range requests {
+//		buf = gen.AppendUA(buf[:0])
+//		req.Header["User-Agent"] = []string{string(buf)}
+//	}
+func (g *Generator) AppendUA(dst []byte) []byte {
+	if g.noRepeat != nil || g.uaPool != nil {
+		return append(dst, g.Get()...)
+	}
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.appendRandomUA(dst, g.rng)
+}
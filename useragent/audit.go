@@ -0,0 +1,288 @@
+// audit.go выборочная проверка распределения User-Agent: сравнение фактических долей с настройками генератора
+
+package useragent
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DistributionBucket доля одного значения в выборке и ожидаемая по настройкам генератора
+type DistributionBucket struct {
+	Value  string  // значение: семейство браузера, версия, платформа или разрешение
+	Count  int     // количество в выборке
+	Share  float64 // фактическая доля (0..1)
+	Target float64 // ожидаемая доля по настройкам (0..1)
+}
+
+// DistributionReport результат AuditDistribution
+type DistributionReport struct {
+	Samples int // размер выборки
+
+	// Browsers семейства браузеров по всей выборке ("list" - строки внешнего списка WithUserAgentList)
+	Browsers []DistributionBucket
+	// Versions версии ("Google Chrome 142.0.7444.59") среди синтезированных User-Agent
+	Versions []DistributionBucket
+	// Platforms значения sec-ch-ua-platform среди синтезированных User-Agent
+	Platforms []DistributionBucket
+	// Resolutions разрешения экрана среди браузеров на Chromium (только они сообщают экран через подсказки клиента)
+	Resolutions []DistributionBucket
+
+	// MaxDeviation наибольшее отклонение фактической доли от ожидаемой по всем значениям
+	MaxDeviation float64
+}
+
+// AuditDistribution генерирует n User-Agent с отпечатками так же, как Get и GetHeaders, и сравнивает распределение
+// браузеров, версий, платформ и разрешений экрана с ожидаемым по настройкам генератора (WithBrowserWeights,
+// WithPlatforms, WithMobileProbability, WithUserAgentList, таблицы разрешений): позволяет убедиться, что
+// конфигурация дает задуманную аудиторию. на 10 000 образцов случайное отклонение долей обычно не превышает 1%.
+//
+// выборка использует собственный источник случайности и не сдвигает последовательность WithSeed.
+// ожидаемые доли не учитывают строки внешнего списка в разделах версий, платформ и разрешений.
+func (g *Generator) AuditDistribution(n int) DistributionReport {
+	report := DistributionReport{Samples: n}
+	if n <= 0 {
+		return report
+	}
+	r := rand.New(rand.NewPCG(globalRand.Uint64(), globalRand.Uint64()))
+
+	g.mu.RLock()
+	listed := make(map[string]bool, len(g.uaList))
+	for _, ua := range g.uaList {
+		listed[ua] = true
+	}
+	targets := g.distributionTargets()
+	g.mu.RUnlock()
+
+	browsers, versions, platforms, resolutions := counter{}, counter{}, counter{}, counter{}
+	for range n {
+		g.mu.RLock()
+		ua := g.randomUA(r)
+		g.mu.RUnlock()
+
+		if listed[ua] {
+			browsers.add("list")
+			continue
+		}
+		fp := g.newFingerprint(r, ua)
+		browsers.add(browserFamily(fp.info.BrandName))
+		versions.add(fp.info.BrandName + " " + fullVersion(fp.info))
+		platforms.add(fp.info.Platform)
+		if fp.device.screen.Width > 0 {
+			resolutions.add(fp.device.screen.key())
+		}
+	}
+
+	report.Browsers = browsers.buckets(targets.browsers)
+	report.Versions = versions.buckets(targets.versions)
+	report.Platforms = platforms.buckets(targets.platforms)
+	report.Resolutions = resolutions.buckets(targets.resolutions)
+	for _, section := range [][]DistributionBucket{report.Browsers, report.Versions, report.Platforms, report.Resolutions} {
+		for _, b := range section {
+			report.MaxDeviation = max(report.MaxDeviation, math.Abs(b.Share-b.Target))
+		}
+	}
+	return report
+}
+
+// String возвращает отчет в виде таблиц по разделам
+func (r DistributionReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "выборка: %d, наибольшее отклонение: %.2f%%\n", r.Samples, r.MaxDeviation*100)
+	sections := []struct {
+		title   string
+		buckets []DistributionBucket
+	}{
+		{"браузеры", r.Browsers}, {"версии", r.Versions}, {"платформы", r.Platforms}, {"разрешения", r.Resolutions},
+	}
+	for _, s := range sections {
+		fmt.Fprintf(&sb, "\n%s:\n", s.title)
+		for _, b := range s.buckets {
+			fmt.Fprintf(&sb, "  %-40s %7d  %6.2f%%  (цель %6.2f%%)\n", b.Value, b.Count, b.Share*100, b.Target*100)
+		}
+	}
+	return sb.String()
+}
+
+// counter счетчик значений в выборке
+type counter map[string]int
+
+func (c counter) add(value string) {
+	c[value]++
+}
+
+// buckets строит доли значений выборки и ожидаемых значений, отсортированные по убыванию ожидаемой доли
+func (c counter) buckets(targets map[string]float64) []DistributionBucket {
+	var total int
+	for _, count := range c {
+		total += count
+	}
+	values := make(map[string]bool, len(c)+len(targets))
+	for v := range c {
+		values[v] = true
+	}
+	for v, t := range targets {
+		if t > 0 {
+			values[v] = true
+		}
+	}
+
+	buckets := make([]DistributionBucket, 0, len(values))
+	for v := range values {
+		b := DistributionBucket{Value: v, Count: c[v], Target: targets[v]}
+		if total > 0 {
+			b.Share = float64(b.Count) / float64(total)
+		}
+		buckets = append(buckets, b)
+	}
+	slices.SortFunc(buckets, func(a, b DistributionBucket) int {
+		if c := cmp.Compare(b.Target, a.Target); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	return buckets
+}
+
+// uaVersionRegex версия Firefox и Safari в User-Agent (у Chromium полная версия есть в browserInfo)
+var uaVersionRegex = regexp.MustCompile(`(?:Firefox|Version)/([\d.]+)`)
+
+// fullVersion возвращает версию браузера в том виде, в каком она хранится в пуле версий
+func fullVersion(info browserInfo) string {
+	if info.FullVersion != "" {
+		return info.brandFullVersion()
+	}
+	if m := uaVersionRegex.FindStringSubmatch(info.UserAgent); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// browserFamily возвращает семейство браузера (как в Browser) по бренду из User-Agent
+func browserFamily(brand string) string {
+	switch brand {
+	case "Microsoft Edge":
+		return string(BrowserEdge)
+	case "Firefox":
+		return string(BrowserFirefox)
+	case "Safari":
+		return string(BrowserSafari)
+	default:
+		return string(BrowserChrome)
+	}
+}
+
+// distributionTargets ожидаемые доли по разделам отчета
+type distributionTargets struct {
+	browsers, versions, platforms, resolutions map[string]float64
+}
+
+// distributionTargets вычисляет ожидаемые доли по настройкам генератора так же, как их применяет randomUA,
+// вызывается под g.mu.RLock
+func (g *Generator) distributionTargets() distributionTargets {
+	t := distributionTargets{
+		browsers:    make(map[string]float64),
+		versions:    make(map[string]float64),
+		platforms:   make(map[string]float64),
+		resolutions: make(map[string]float64),
+	}
+
+	listShare := 0.0
+	if len(g.uaList) > 0 {
+		listShare = g.uaListWeight
+		t.browsers["list"] = listShare
+	}
+
+	var total float64
+	for _, b := range browserOrder {
+		total += g.browserWeight(b)
+	}
+	weights := make(map[Browser]float64, len(browserOrder))
+	if total <= 0 {
+		weights[BrowserChrome] = 1
+	} else {
+		for _, b := range browserOrder {
+			weights[b] = g.browserWeight(b) / total
+		}
+	}
+
+	platforms := g.platforms
+	if len(platforms) == 0 {
+		platforms = platformProfiles[:1]
+	}
+	perPlatform := 1 / float64(len(platforms))
+
+	// мобильный Chrome выбирается до платформы, Edge на платформах без Edge заменяется Chrome
+	mobile := weights[BrowserChrome] * g.mobileProbability
+	chrome, edge := weights[BrowserChrome]-mobile, 0.0
+	for _, p := range platforms {
+		chromium := (weights[BrowserChrome] - mobile + weights[BrowserEdge]) * perPlatform
+		if p.edge {
+			edge += weights[BrowserEdge] * perPlatform
+		} else {
+			chrome += weights[BrowserEdge] * perPlatform
+		}
+		t.platforms[p.secCHPlatform()] += chromium
+		t.platforms[detectPlatform(p.firefoxUA(""))] += weights[BrowserFirefox] * perPlatform
+	}
+	chrome += mobile
+	t.platforms["Android"] += mobile
+	t.platforms["macOS"] += weights[BrowserSafari]
+
+	family := map[Browser]float64{BrowserChrome: chrome, BrowserEdge: edge, BrowserFirefox: weights[BrowserFirefox], BrowserSafari: weights[BrowserSafari]}
+	for b, share := range family {
+		if share > 0 {
+			t.browsers[string(b)] = share * (1 - listShare)
+		}
+	}
+
+	// версии выбираются из пулов равновероятно
+	addVersions := func(brand string, share float64, pool []string) {
+		for _, v := range pool {
+			t.versions[brand+" "+v] += share / float64(len(pool))
+		}
+	}
+	chromiumPool := g.versions
+	if len(chromiumPool) == 0 {
+		chromiumPool = []string{approximateVersionForDate(time.Now())}
+	}
+	edgePool := g.edgeVersions
+	if len(edgePool) == 0 {
+		edgePool = chromiumPool
+	}
+	addVersions("Google Chrome", chrome, chromiumPool)
+	addVersions("Microsoft Edge", edge, edgePool)
+	if weights[BrowserFirefox] > 0 {
+		firefoxPool := g.firefoxVersions
+		if len(firefoxPool) == 0 {
+			firefoxPool = []string{approximateFirefoxVersionForDate(time.Now())}
+		}
+		addVersions("Firefox", weights[BrowserFirefox], firefoxPool)
+	}
+	if weights[BrowserSafari] > 0 {
+		addVersions("Safari", weights[BrowserSafari], safariVersionsAt(time.Now()))
+	}
+
+	// разрешения: среди браузеров на Chromium - десктопные и мобильные таблицы в пропорции мобильного Chrome
+	data := g.realism()
+	if chromium := chrome + edge; chromium > 0 {
+		for res, share := range data.resolutionShares(desktopClasses...) {
+			t.resolutions[res] += share * (chromium - mobile) / chromium
+		}
+		if mobile > 0 {
+			for res, share := range data.resolutionShares(DeviceMobile) {
+				t.resolutions[res] += share * mobile / chromium
+			}
+		}
+	}
+	return t
+}
@@ -0,0 +1,25 @@
+// batch.go пакетная генерация различных User-Agent для заполнения пулов воркеров
+
+package useragent
+
+// GetN возвращает n различных строк User-Agent, например для пула воркеров, каждому из которых нужна
+// собственная личность. строки выбираются так же, как Get (с учетом долей браузеров и платформ),
+// а когда случайный выбор начинает давать только повторы, недостающие берутся из всех комбинаций версии,
+// браузера и платформы (см. ExportInventory) в случайном порядке.
+// если различных комбинаций меньше n, возвращаются все. при n <= 0 возвращает nil.
+// выдача GetN не учитывается в цикле WithNoRepeat
+func (g *Generator) GetN(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	d := newUADeck()
+	result := make([]string, 0, min(n, 1024))
+	for len(result) < n {
+		ua, ok := d.next(g)
+		if !ok {
+			break
+		}
+		result = append(result, ua)
+	}
+	return result
+}
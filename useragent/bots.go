@@ -17,6 +17,14 @@ const (
 	BingBot
 	// YandexBot имитирует YandexBot
 	YandexBot
+	// DuckDuckBot имитирует робота DuckDuckGo
+	DuckDuckBot
+	// BaiduSpider имитирует Baiduspider
+	BaiduSpider
+	// AppleBot имитирует Applebot (Siri, Spotlight)
+	AppleBot
+	// SogouSpider имитирует робота Sogou
+	SogouSpider
 )
 
 // getCrawlerHeadersWithVersion создает заголовки для указанного типа краулера
@@ -43,6 +51,21 @@ func (g *Generator) getCrawlerHeadersWithVersion(crawlerType CrawlerType, chrome
 		)
 	case YandexBot:
 		userAgent = "Mozilla/5.0 (compatible; YandexBot/3.0; +http://yandex.com/bots)" // #nosec G107
+	case DuckDuckBot:
+		// https://duckduckgo.com/duckduckgo-help-pages/results/duckduckbot
+		userAgent = "DuckDuckBot/1.1; (+http://duckduckgo.com/duckduckbot.html)" // #nosec G107
+	case BaiduSpider:
+		userAgent = "Mozilla/5.0 (compatible; Baiduspider/2.0; +http://www.baidu.com/search/spider.html)" // #nosec G107
+		headers["accept-language"] = "zh-cn,zh-tw"
+	case AppleBot:
+		// https://support.apple.com/en-us/119829: строка Safari с добавленным токеном Applebot
+		userAgent = fmt.Sprintf(
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/%s Safari/605.1.15 (Applebot/0.1; +http://www.apple.com/go/applebot)", // #nosec G107
+			safariVersionsAt(time.Now())[0],
+		)
+	case SogouSpider:
+		userAgent = "Sogou web spider/4.0(+http://www.sogou.com/docs/help/webmasters.htm#07)" // #nosec G107
+		headers["accept-language"] = "zh-CN,zh;q=0.9"
 	default:
 		userAgent = defaultBotUserAgent
 		headers["from"] = "googlebot(at)google.com"
@@ -60,17 +83,18 @@ func (g *Generator) getCrawlerHeadersWithVersion(crawlerType CrawlerType, chrome
 // для успешной имитации бота на таких сайтах, запрос должен исходить из подсети,
 // принадлежащей поисковой системе (например, в Google Cloud или Google Colab для имитации Googlebot)
 func (g *Generator) GetCrawlerHeaders(crawlerType CrawlerType) map[string]string {
+	// боты Google и Bing стремятся использовать последние версии Chromium
+	return g.getCrawlerHeadersWithVersion(crawlerType, g.latestVersion())
+}
+
+// latestVersion возвращает самую свежую известную версию браузера
+func (g *Generator) latestVersion() string {
 	g.mu.RLock()
+	defer g.mu.RUnlock()
 	// проверка версий в кэше
 	if len(g.versions) == 0 {
-		g.mu.RUnlock()
 		// маловероятная ситуация: Generator всегда возвращает актуальные версии
-		latestVersion := approximateVersionForDate(time.Now()) // фоллбэк на аппроксимацию на основе даты
-		return g.getCrawlerHeadersWithVersion(crawlerType, latestVersion)
+		return approximateVersionForDate(time.Now()) // фоллбэк на аппроксимацию на основе даты
 	}
-
-	latestVersion := g.versions[0] // боты Google и Bing стремятся использовать последние версии Chromium
-	g.mu.RUnlock()
-
-	return g.getCrawlerHeadersWithVersion(crawlerType, latestVersion)
+	return g.versions[0]
 }
@@ -0,0 +1,121 @@
+// browsers.go семейства браузеров и их доли в выводе Get
+
+package useragent
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// Browser семейство браузеров
+type Browser string
+
+const (
+	BrowserChrome  Browser = "chrome"
+	BrowserEdge    Browser = "edge"
+	BrowserFirefox Browser = "firefox"
+	BrowserSafari  Browser = "safari"
+)
+
+// browserOrder фиксированный порядок семейств для детерминированного выбора по весам
+var browserOrder = []Browser{BrowserChrome, BrowserEdge, BrowserFirefox, BrowserSafari}
+
+// MarketShareWeights примерные доли десктопных браузеров по данным StatCounter
+// (https://gs.statcounter.com/browser-market-share/desktop/worldwide), для использования с WithBrowserWeights
+var MarketShareWeights = map[Browser]float64{
+	BrowserChrome:  66,
+	BrowserEdge:    13,
+	BrowserSafari:  9,
+	BrowserFirefox: 7,
+}
+
+// WithBrowserWeights задает относительные веса семейств браузеров в выводе Get, например MarketShareWeights:
+// на больших объемах перекос распределения (например, 50% Edge) сам по себе является признаком бота.
+// веса не обязаны давать в сумме 1, отрицательные и NaN игнорируются, семейства без веса не генерируются.
+// опция заменяет WithEdgeProbability, WithFirefoxProbability и WithSafariProbability.
+func WithBrowserWeights(weights map[Browser]float64) Option {
+	return func(g *Generator) {
+		filtered := make(map[Browser]float64, len(weights))
+		var total float64
+		for b, w := range weights {
+			if math.IsNaN(w) || math.IsInf(w, 0) || w <= 0 {
+				continue
+			}
+			filtered[b] = w
+			total += w
+		}
+		if total > 0 {
+			g.browserWeights = filtered
+		}
+	}
+}
+
+// browserWeight возвращает вес семейства: из WithBrowserWeights или из вероятностей отдельных опций
+func (g *Generator) browserWeight(b Browser) float64 {
+	if g.browserWeights != nil {
+		return g.browserWeights[b]
+	}
+	chromium := max(1-g.firefoxProbability-g.safariProbability, 0)
+	switch b {
+	case BrowserChrome:
+		return chromium * (1 - g.edgeProbability)
+	case BrowserEdge:
+		return chromium * g.edgeProbability
+	case BrowserFirefox:
+		return g.firefoxProbability
+	case BrowserSafari:
+		return g.safariProbability
+	default:
+		return 0
+	}
+}
+
+// pickBrowser выбирает семейство браузера с учетом весов
+func (g *Generator) pickBrowser(r *rand.Rand) Browser {
+	var total float64
+	for _, b := range browserOrder {
+		total += g.browserWeight(b)
+	}
+	if total <= 0 {
+		return BrowserChrome
+	}
+	x := r.Float64() * total
+	for _, b := range browserOrder {
+		w := g.browserWeight(b)
+		if x < w {
+			return b
+		}
+		x -= w
+	}
+	return BrowserChrome
+}
+
+// VersionsFor возвращает текущий набор версий семейства браузеров (копию, которую можно изменять):
+// Chrome - набор версий Chromium (как GetVersions), Edge - собственные сборки из репозитория Microsoft
+// (пока их нет - набор Chromium), Firefox - мажорные версии ("142.0"),
+// Safari - последние версии из таблицы релизов Apple. для неизвестного семейства возвращается nil.
+// если генерация Firefox не включена, возвращается аппроксимированная версия на текущую дату.
+func (g *Generator) VersionsFor(b Browser) []string {
+	switch b {
+	case BrowserChrome:
+		return g.GetVersions()
+	case BrowserEdge:
+		if edge := g.edgeVersionsCopy(); len(edge) > 0 {
+			return edge
+		}
+		return g.GetVersions()
+	case BrowserFirefox:
+		g.mu.RLock()
+		defer g.mu.RUnlock()
+		if len(g.firefoxVersions) == 0 {
+			return []string{approximateFirefoxVersionForDate(time.Now())}
+		}
+		return slices.Clone(g.firefoxVersions)
+	case BrowserSafari:
+		return safariVersionsAt(time.Now())
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,165 @@
+// cachelock.go файл блокировки дискового кэша: при одновременном старте многих процессов
+// сеть опрашивает только один, остальные дожидаются записанного им кэша
+
+package useragent
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"time"
+)
+
+const (
+	cacheLockSuffix = ".lock"                // суффикс файла блокировки рядом с файлом кэша
+	cacheLockPoll   = 100 * time.Millisecond // период проверки, освобождена ли блокировка
+
+	// cacheLockStages сетевые этапы, которые владелец выполняет под блокировкой последовательно,
+	// каждый в пределах sourcesTimeout: версии Chromium, затем версии Firefox
+	cacheLockStages = 2
+)
+
+// cacheLockTimeout наибольшее время, которое процесс-владелец держит блокировку (все сетевые этапы
+// и запись кэша): дольше блокировка считается брошенной (процесс завершился аварийно), и ее можно снять
+func (g *Generator) cacheLockTimeout() time.Duration {
+	return cacheLockStages*g.sourcesTimeout(SourceGoogle, SourceMicrosoft, SourceMozilla) + 5*time.Second
+}
+
+// cacheLock содержимое и время изменения файла блокировки: по ним проверяется, что файл - тот самый,
+// а не созданный заново другим процессом. содержимое включает случайный токен владельца
+type cacheLock struct {
+	content []byte
+	modTime time.Time
+}
+
+// readCacheLock читает файл блокировки
+func readCacheLock(path string) (cacheLock, error) {
+	content, err := readLimited(path, 1<<10)
+	if err != nil {
+		return cacheLock{}, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return cacheLock{}, err
+	}
+	return cacheLock{content: content, modTime: info.ModTime()}, nil
+}
+
+// takeCacheLock атомарно убирает файл блокировки lockPath, если это все еще блокировка expected:
+// переименование выигрывает только один процесс, а после него проверяется, что убрана именно expected,
+// а не блокировка, созданная другим процессом между проверкой и переименованием (такая возвращается на место)
+func takeCacheLock(lockPath string, expected cacheLock) bool {
+	moved := fmt.Sprintf("%s.%d.%x", lockPath, os.Getpid(), rand.Uint64())
+	if err := os.Rename(lockPath, moved); err != nil {
+		return false
+	}
+	defer os.Remove(moved)
+	got, err := readCacheLock(moved)
+	if err == nil && bytes.Equal(got.content, expected.content) && got.modTime.Equal(expected.modTime) {
+		return true
+	}
+	// чужая живая блокировка: ссылка не создается, если на ее месте уже появилась новая
+	_ = os.Link(moved, lockPath)
+	return false
+}
+
+// acquireCacheLock атомарно создает файл блокировки кэша (O_EXCL работает одинаково на всех ОС):
+// возвращает true, если процесс должен обновить кэш сам (блокировка получена или невозможна),
+// и функцию снятия блокировки, которую можно вызывать в любом случае
+func (g *Generator) acquireCacheLock() (release func(), owner bool) {
+	lockPath := g.diskCachePath + cacheLockSuffix
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			fmt.Fprintf(f, "%d %x %s\n", os.Getpid(), rand.Uint64(), time.Now().Format(time.RFC3339Nano))
+			_ = f.Close()
+			// снимается только своя блокировка: если ее сочли брошенной и пересоздали, чужая остается
+			own, err := readCacheLock(lockPath)
+			if err != nil {
+				return func() {}, true
+			}
+			return func() { takeCacheLock(lockPath, own) }, true
+		}
+		if !errors.Is(err, os.ErrExist) {
+			// каталог недоступен на запись: блокировка невозможна, процесс работает как раньше
+			g.logger.Debug("не удалось создать файл блокировки кэша", "event", eventCacheLockFailed, "lock_path", lockPath, "error", err)
+			return func() {}, true
+		}
+		stale, err := readCacheLock(lockPath)
+		if err != nil || time.Since(stale.modTime) < g.cacheLockTimeout() {
+			return func() {}, false
+		}
+		// брошенная блокировка: процесс-владелец не успел бы обновить кэш за это время.
+		// если ее одновременно снимает другой процесс, этот ждет обновленного им кэша
+		if !takeCacheLock(lockPath, stale) {
+			return func() {}, false
+		}
+		g.logger.Warn("блокировка кэша брошена другим процессом и снята", "event", eventCacheLockStale, "lock_path", lockPath)
+	}
+	return func() {}, false
+}
+
+// waitForCacheRefresh ждет, пока процесс, владеющий блокировкой, обновит кэш, и загружает его, но не дольше
+// таймаута одного запроса к источнику (и WithInitDeadline): сеть владелец уже опрашивает, поэтому затем
+// генератор создается без блокировки и без своих запросов - с устаревшим кэшем или аппроксимацией,
+// а кэш, записанный владельцем позже, подхватывается в фоне. возвращает false, только если версии
+// не получены ни одним из способов и их нужно запросить самому (строгий режим без кэша на диске)
+func (g *Generator) waitForCacheRefresh() bool {
+	lockPath := g.diskCachePath + cacheLockSuffix
+	wait := g.requestTimeout()
+	if g.initDeadline > 0 {
+		wait = min(wait, g.initDeadline)
+	}
+	g.logger.Debug("кэш обновляет другой процесс, ожидание…", "event", eventCacheLockWait, "lock_path", lockPath, "timeout", wait)
+	if g.awaitCacheUnlock(lockPath, wait) && g.loadFromDiskCache() {
+		g.logger.Debug("загружены версии из кэша, обновленного другим процессом", "event", eventCacheLoaded, "cache_path", g.diskCachePath)
+		return true
+	}
+
+	switch {
+	case g.loadDiskCache(true):
+		g.logger.Warn("кэш обновляет другой процесс, до его завершения используется устаревший кэш",
+			"event", eventCacheLockWait, "lock_path", lockPath)
+	case g.strictSources:
+		return false
+	default:
+		g.logger.Warn("фоллбэк на аппроксимацию: кэш обновляет другой процесс, до его завершения используется аппроксимация",
+			"event", eventFallback, "fallback", true, "lock_path", lockPath)
+		g.setVersions(g.approximateVersions(), OriginApproximation)
+	}
+	if g.firefoxEnabled() && len(g.firefoxVersions) == 0 && !g.strictSources {
+		g.setFirefoxVersions(nil)
+	}
+
+	g.background.Add(1)
+	go func() {
+		defer g.background.Done()
+		if g.awaitCacheUnlock(lockPath, g.cacheLockTimeout()-wait) && g.loadFromDiskCache() {
+			g.logger.Debug("загружены версии из кэша, обновленного другим процессом", "event", eventCacheLoaded, "cache_path", g.diskCachePath)
+		}
+	}()
+	return true
+}
+
+// awaitCacheUnlock ждет освобождения блокировки кэша не дольше wait и до закрытия генератора:
+// возвращает true, если блокировка освобождена
+func (g *Generator) awaitCacheUnlock(lockPath string, wait time.Duration) bool {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	ticker := time.NewTicker(cacheLockPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return false
+		case <-g.done:
+			return false
+		case <-ticker.C:
+			if _, err := os.Stat(lockPath); errors.Is(err, os.ErrNotExist) {
+				return true
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// cdp.go параметры Network.setUserAgentOverride для headless Chrome, согласованные с заголовками сессии
+
+package useragent
+
+import "strings"
+
+// BrandVersion бренд и версия из sec-ch-ua или sec-ch-ua-full-version-list
+type BrandVersion struct {
+	Brand   string `json:"brand"`
+	Version string `json:"version"`
+}
+
+// UserAgentMetadata подсказки клиента для Network.setUserAgentOverride (поле userAgentMetadata):
+// из них Chrome формирует заголовки sec-ch-ua-* и ответ navigator.userAgentData
+type UserAgentMetadata struct {
+	Brands          []BrandVersion `json:"brands"`
+	FullVersionList []BrandVersion `json:"fullVersionList"`
+	FullVersion     string         `json:"fullVersion,omitempty"` // устаревшее поле, Chrome берет версию из fullVersionList
+	Platform        string         `json:"platform"`
+	PlatformVersion string         `json:"platformVersion"`
+	Architecture    string         `json:"architecture"`
+	Model           string         `json:"model"`
+	Mobile          bool           `json:"mobile"`
+	Bitness         string         `json:"bitness,omitempty"`
+	Wow64           bool           `json:"wow64"`
+	FormFactors     []string       `json:"formFactors,omitempty"` // Chrome 129+
+}
+
+// UserAgentOverride параметры команды CDP Network.setUserAgentOverride (и Emulation.setUserAgentOverride):
+// в JSON совпадают с протоколом, поэтому их можно передать в chromedp, Rod или Puppeteer как есть
+type UserAgentOverride struct {
+	UserAgent         string             `json:"userAgent"`
+	AcceptLanguage    string             `json:"acceptLanguage,omitempty"`
+	Platform          string             `json:"platform,omitempty"` // navigator.platform
+	UserAgentMetadata *UserAgentMetadata `json:"userAgentMetadata,omitempty"`
+}
+
+// UserAgentOverride возвращает параметры Network.setUserAgentOverride для браузера сессии: headless Chrome
+// с ними отправляет те же User-Agent, языки и подсказки клиента, что и HTTP-запросы с заголовками сессии,
+// а navigator.userAgentData и navigator.platform им не противоречат. размер окна и DPR для
+// Emulation.setDeviceMetricsOverride дает Device.
+//
+// у Firefox и Safari нет подсказок клиента: UserAgentMetadata пуст, и Chrome с такой подменой
+// по-прежнему выдает себя через navigator.userAgentData - для автоматизации Chrome лучше выбирать
+// сессии Chrome и Edge
+func (s *Session) UserAgentOverride() UserAgentOverride {
+	fp := s.fp
+	o := UserAgentOverride{
+		UserAgent:      fp.ua,
+		AcceptLanguage: overrideLanguages(fp.acceptLanguage),
+		Platform:       navigatorPlatform(fp),
+	}
+	if fp.info.BrandName == "Firefox" || fp.info.BrandName == "Safari" {
+		return o
+	}
+	d := fp.device
+	o.UserAgentMetadata = &UserAgentMetadata{
+		Brands:          brandVersions(fp.info, false),
+		FullVersionList: brandVersions(fp.info, true),
+		FullVersion:     fp.info.brandFullVersion(),
+		Platform:        fp.info.Platform,
+		PlatformVersion: d.PlatformVersion,
+		Architecture:    d.Arch,
+		Model:           d.Model,
+		Mobile:          d.Mobile,
+		Bitness:         d.Bitness,
+		Wow64:           d.Wow64,
+	}
+	if value, ok := formFactors(fp, navigation{}); ok && fp.info.major() >= chromeFormFactorsMajor {
+		o.UserAgentMetadata.FormFactors = []string{strings.Trim(value, `"`)}
+	}
+	return o
+}
+
+// overrideLanguages превращает accept-language в список языков для acceptLanguage ("ru-RU,ru,en-US,en"):
+// веса q Chrome расставляет сам
+func overrideLanguages(acceptLanguage string) string {
+	var langs []string
+	for part := range strings.SplitSeq(acceptLanguage, ",") {
+		if lang, _, _ := strings.Cut(part, ";"); strings.TrimSpace(lang) != "" {
+			langs = append(langs, strings.TrimSpace(lang))
+		}
+	}
+	return strings.Join(langs, ",")
+}
+
+// navigatorPlatform значение navigator.platform для платформы и архитектуры: Chrome на Windows всегда
+// сообщает Win32, на macOS (и на Apple Silicon) - MacIntel, на Android - замороженное Linux armv81
+func navigatorPlatform(fp fingerprint) string {
+	switch {
+	case fp.info.Platform == "Windows":
+		return "Win32"
+	case fp.info.Platform == "macOS":
+		return "MacIntel"
+	case fp.info.Platform == "Android" || fp.info.Mobile:
+		return "Linux armv81"
+	case fp.device.Arch == "arm":
+		return "Linux aarch64"
+	}
+	return "Linux x86_64"
+}
@@ -0,0 +1,116 @@
+// check.go самопроверка отпечатка: сравнение отправленных заголовков с тем, что увидел сервер
+
+package useragent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultEchoURL - эндпоинт по умолчанию, возвращающий полученные заголовки в JSON
+	defaultEchoURL = "https://httpbin.org/headers"
+
+	// maxCheckBodySize ограничивает размер читаемого ответа при проверке
+	maxCheckBodySize = 1 << 20
+)
+
+// CheckReport содержит результат самопроверки отпечатка
+type CheckReport struct {
+	URL        string            // адрес, по которому выполнялся запрос
+	StatusCode int               // HTTP статус ответа
+	Intended   map[string]string // заголовки, которые были отправлены
+	Observed   map[string]string // заголовки, которые вернул эхо-сервер, nil - если сервер их не вернул
+	Missing    []string          // отправлены, но не дошли до сервера
+	Mismatched []string          // дошли до сервера с другим значением
+	Extra      []string          // добавлены по пути (транспортом, прокси и т.д.)
+}
+
+// echoResponse формат ответа эхо-сервера вида httpbin.org/headers
+type echoResponse struct {
+	Headers map[string]string `json:"headers"`
+}
+
+// Check выполняет запрос со сгенерированными заголовками и сообщает, что увидел сервер:
+// помогает понять, почему целевой сайт всё ещё блокирует запросы.
+//
+// если targetURL пуст, используется эхо-сервер https://httpbin.org/headers.
+// сравнение возможно только с эндпоинтами, возвращающими заголовки в формате {"headers": {...}},
+// для произвольного сайта в отчёте будут только статус ответа и отправленные заголовки.
+// порядок заголовков не проверяется: net/http не сохраняет его при отправке.
+// в офлайн-режиме и в сборке с тегом nonet запрос не выполняется и возвращается ошибка.
+func (g *Generator) Check(ctx context.Context, targetURL string) (CheckReport, error) {
+	if targetURL == "" {
+		targetURL = defaultEchoURL
+	}
+	if g.offline {
+		return CheckReport{URL: targetURL}, errOffline
+	}
+	report := CheckReport{URL: targetURL, Intended: g.GetHeaders(targetURL)}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return report, fmt.Errorf("не удалось создать запрос: %w", err)
+	}
+	for k, v := range report.Intended {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return report, fmt.Errorf("HTTP запрос не удался: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	report.StatusCode = resp.StatusCode
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCheckBodySize))
+	if err != nil {
+		return report, fmt.Errorf("не удалось прочитать тело ответа: %w", err)
+	}
+
+	var echo echoResponse
+	if err := json.Unmarshal(body, &echo); err != nil || len(echo.Headers) == 0 {
+		g.logger.Debug("сервер не вернул заголовки запроса, сравнение невозможно", "event", eventCheckNoEcho, "url", targetURL)
+		return report, nil
+	}
+
+	report.Observed = make(map[string]string, len(echo.Headers))
+	for k, v := range echo.Headers {
+		report.Observed[strings.ToLower(k)] = v
+	}
+	report.compare()
+	return report, nil
+}
+
+// compare заполняет списки расхождений между отправленными и полученными заголовками
+func (r *CheckReport) compare() {
+	for k, v := range r.Intended {
+		observed, ok := r.Observed[k]
+		switch {
+		case !ok:
+			r.Missing = append(r.Missing, k)
+		case observed != v:
+			r.Mismatched = append(r.Mismatched, k)
+		}
+	}
+	for k := range r.Observed {
+		if _, ok := r.Intended[k]; !ok {
+			r.Extra = append(r.Extra, k)
+		}
+	}
+	sort.Strings(r.Missing)
+	sort.Strings(r.Mismatched)
+	sort.Strings(r.Extra)
+}
+
+// OK сообщает, дошли ли все заголовки до сервера без изменений
+func (r CheckReport) OK() bool {
+	return r.Observed != nil && len(r.Missing) == 0 && len(r.Mismatched) == 0
+}
@@ -0,0 +1,177 @@
+// compare.go сравнение сгенерированных заголовков с заголовками, снятыми с настоящего браузера
+
+package useragent
+
+import (
+	"bufio"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// HeaderMismatch заголовок, который есть в обоих наборах, но с разными значениями
+type HeaderMismatch struct {
+	Name      string
+	Captured  string // значение в захваченном запросе
+	Generated string // сгенерированное значение
+}
+
+// HeaderDiff отличия сгенерированного запроса от захваченного у настоящего браузера
+type HeaderDiff struct {
+	Missing    []Header         // есть в захвате, но не сгенерированы
+	Extra      []Header         // сгенерированы, но в захвате их нет
+	Mismatched []HeaderMismatch // есть в обоих наборах с разными значениями
+
+	// Misordered общие заголовки, стоящие не на своем месте: минимальный набор, после перестановки которого
+	// порядок общих заголовков совпадет с захватом
+	Misordered []string
+}
+
+// Empty сообщает, что наборы совпадают, включая порядок
+func (d HeaderDiff) Empty() bool {
+	return len(d.Missing) == 0 && len(d.Extra) == 0 && len(d.Mismatched) == 0 && len(d.Misordered) == 0
+}
+
+// String возвращает отличия в читаемом виде, по одному на строку
+func (d HeaderDiff) String() string {
+	if d.Empty() {
+		return "отличий нет"
+	}
+	var sb strings.Builder
+	for _, h := range d.Missing {
+		fmt.Fprintf(&sb, "- %s: %s\n", h.Name, h.Value)
+	}
+	for _, h := range d.Extra {
+		fmt.Fprintf(&sb, "+ %s: %s\n", h.Name, h.Value)
+	}
+	for _, m := range d.Mismatched {
+		fmt.Fprintf(&sb, "~ %s: %q -> %q\n", m.Name, m.Captured, m.Generated)
+	}
+	if len(d.Misordered) > 0 {
+		fmt.Fprintf(&sb, "порядок: не на своем месте %s\n", strings.Join(d.Misordered, ", "))
+	}
+	return sb.String()
+}
+
+// CompareWithCaptured сравнивает сгенерированные заголовки с захваченными у настоящего браузера
+// (например, из DevTools или mitmproxy, см. ParseCapturedHeaders) и показывает недостающие, лишние
+// и отличающиеся заголовки, а также заголовки не на своем месте: помогает шаг за шагом приблизить запрос
+// к браузеру для конкретного сайта (обработчиками WithHeaderTransformer).
+//
+// имена сравниваются без учета регистра, псевдозаголовки HTTP/2 (:method, :path) не учитываются.
+// сгенерированные заголовки удобно получить из GetHeaderSet(...).Headers().
+func CompareWithCaptured(captured, generated []Header) HeaderDiff {
+	captured, generated = normalizeHeaders(captured), normalizeHeaders(generated)
+	capturedValues := headerValues(captured)
+	generatedValues := headerValues(generated)
+
+	var d HeaderDiff
+	var capturedOrder, generatedOrder []string
+	for _, h := range captured {
+		got, ok := generatedValues[h.Name]
+		switch {
+		case !ok:
+			d.Missing = append(d.Missing, h)
+		case got != h.Value:
+			d.Mismatched = append(d.Mismatched, HeaderMismatch{Name: h.Name, Captured: h.Value, Generated: got})
+		}
+		if ok {
+			capturedOrder = append(capturedOrder, h.Name)
+		}
+	}
+	for _, h := range generated {
+		if _, ok := capturedValues[h.Name]; !ok {
+			d.Extra = append(d.Extra, h)
+			continue
+		}
+		generatedOrder = append(generatedOrder, h.Name)
+	}
+	d.Misordered = misordered(capturedOrder, generatedOrder)
+	return d
+}
+
+// ParseCapturedHeaders разбирает заголовки, скопированные из DevTools, mitmproxy или дампа HTTP/1.1:
+// строки вида "Name: value" в исходном порядке. строка запроса ("GET / HTTP/1.1"), пустые строки
+// и псевдозаголовки HTTP/2 пропускаются
+func ParseCapturedHeaders(raw string) []Header {
+	var headers []Header
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxCacheFileSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.ContainsAny(name, " \t") {
+			continue // строка запроса или статуса
+		}
+		headers = append(headers, Header{Name: strings.ToLower(name), Value: strings.TrimSpace(value)})
+	}
+	return headers
+}
+
+// normalizeHeaders приводит имена к нижнему регистру и убирает псевдозаголовки HTTP/2
+func normalizeHeaders(headers []Header) []Header {
+	normalized := make([]Header, 0, len(headers))
+	for _, h := range headers {
+		name := strings.ToLower(strings.TrimSpace(h.Name))
+		if name == "" || strings.HasPrefix(name, ":") {
+			continue
+		}
+		normalized = append(normalized, Header{Name: name, Value: strings.TrimSpace(h.Value)})
+	}
+	return normalized
+}
+
+// headerValues возвращает значения заголовков по имени (при повторах - первое)
+func headerValues(headers []Header) map[string]string {
+	values := make(map[string]string, len(headers))
+	for _, h := range headers {
+		if _, ok := values[h.Name]; !ok {
+			values[h.Name] = h.Value
+		}
+	}
+	return values
+}
+
+// misordered возвращает заголовки из got, не входящие в наибольшую общую подпоследовательность с want:
+// это минимальный набор заголовков, которые нужно переставить, чтобы порядок совпал
+func misordered(want, got []string) []string {
+	// длины наибольших общих подпоследовательностей суффиксов
+	lcs := make([][]int, len(want)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(got)+1)
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			if want[i] == got[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	inPlace := make([]bool, len(got))
+	for i, j := 0, 0; i < len(want) && j < len(got); {
+		switch {
+		case want[i] == got[j]:
+			inPlace[j] = true
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	var out []string
+	for j, name := range got {
+		if !inPlace[j] && !slices.Contains(out, name) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
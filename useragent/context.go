@@ -0,0 +1,34 @@
+// context.go варианты Get и GetHeaders с контекстом вызова: отмена и значения запроса для обработчиков заголовков
+
+package useragent
+
+import "context"
+
+// GetCtx аналогичен Get, но для отмененного или просроченного ctx возвращает его ошибку без генерации:
+// удобно в обработчиках запросов сервера, где работа по отмененному запросу не нужна
+func (g *Generator) GetCtx(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return g.Get(), nil
+}
+
+// GetHeadersCtx аналогичен GetHeaders, но передает ctx обработчикам WithHeaderTransformer (см. HeaderSet.Context):
+// обработчик может взять из него значения запроса, например арендатора или идентификатор трассировки.
+// для отмененного или просроченного ctx возвращает его ошибку без генерации
+func (g *Generator) GetHeadersCtx(ctx context.Context, targetURL ...string) (map[string]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	fp := g.newFingerprint(g.rng, g.Get())
+	return g.transformHeadersCtx(ctx, requestHeaders(fp, g.navigationFor(targetURL...))), nil
+}
+
+// Context возвращает контекст вызова GetHeadersCtx, для которого сгенерирован набор,
+// или context.Background() для вызовов без контекста
+func (hs *HeaderSet) Context() context.Context {
+	if hs.ctx == nil {
+		return context.Background()
+	}
+	return hs.ctx
+}
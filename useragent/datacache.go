@@ -0,0 +1,125 @@
+// datacache.go кэш удаленных файлов данных (манифест, реестр источников) в личном каталоге кэша пользователя
+
+package useragent
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// dataCacheDirName каталог кэша файлов данных внутри os.UserCacheDir
+const dataCacheDirName = "go-fake-useragent"
+
+// dataCacheFile кэш удаленного файла данных на диске
+type dataCacheFile struct {
+	Timestamp time.Time `json:"timestamp"`
+	Location  string    `json:"location"`            // адрес, с которого получены данные
+	Data      []byte    `json:"data"`                // содержимое файла как есть
+	Signature string    `json:"signature,omitempty"` // HMAC-SHA256 содержимого, если задан ключ подписи
+}
+
+// sign вычисляет HMAC-SHA256 от содержимого кэша без учета самой подписи
+func (c dataCacheFile) sign(key []byte) (string, error) {
+	c.Signature = ""
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verify проверяет подпись кэша заданным ключом за постоянное время
+func (c dataCacheFile) verify(key []byte) bool {
+	if c.Signature == "" {
+		return false
+	}
+	expected, err := c.sign(key)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(c.Signature))
+}
+
+// dataCachePath возвращает путь кэша файла данных по адресу location, пусто - кэшировать негде.
+// кэш хранится в личном каталоге пользователя (os.UserCacheDir), а не в общем os.TempDir:
+// там файл с предсказуемым именем мог бы подменить или подставить ссылкой другой пользователь
+func dataCachePath(location, cacheTemplate string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	dir = filepath.Join(dir, dataCacheDirName)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return ""
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(location))
+	return filepath.Join(dir, fmt.Sprintf(cacheTemplate, h.Sum64()))
+}
+
+// readDataCache читает кэш файла данных location, если он не старше ttl и,
+// при заданном WithCacheSigningKey, подписан тем же ключом
+func (g *Generator) readDataCache(path, location string, ttl time.Duration) ([]byte, bool) {
+	raw, err := readLimited(path, 2*maxManifestSize) // base64 увеличивает содержимое на треть
+	if err != nil {
+		return nil, false
+	}
+	var cache dataCacheFile
+	if err := json.Unmarshal(raw, &cache); err != nil || cache.Location != location {
+		return nil, false
+	}
+	if g.cacheKey != nil && !cache.verify(g.cacheKey) {
+		g.logger.Warn("кэш файла данных не подписан ключом генератора и проигнорирован",
+			"event", eventDataCacheRejected, "cache_path", path, "location", location)
+		return nil, false
+	}
+	if age := time.Since(cache.Timestamp); age < 0 || age >= ttl {
+		return nil, false
+	}
+	return cache.Data, true
+}
+
+// writeDataCache атомарно сохраняет файл данных location в кэш: запись во временный файл,
+// созданный с O_EXCL в том же каталоге, и переименование поверх старого кэша
+func (g *Generator) writeDataCache(path, location string, data []byte) {
+	cache := dataCacheFile{Timestamp: time.Now(), Location: location, Data: data}
+	if g.cacheKey != nil {
+		signature, err := cache.sign(g.cacheKey)
+		if err != nil {
+			return
+		}
+		cache.Signature = signature
+	}
+	raw, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(path), "useragent-data-*.tmp")
+	if err != nil {
+		g.logger.Debug("не удалось сохранить файл данных в кэш", "event", eventDataCacheFailed, "cache_path", path, "error", err)
+		return
+	}
+	defer func() {
+		_ = os.Remove(tempFile.Name()) // после успешного переименования файла уже нет
+	}()
+	_, err = tempFile.Write(raw)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFile.Name(), path)
+	}
+	if err != nil {
+		g.logger.Debug("не удалось сохранить файл данных в кэш", "event", eventDataCacheFailed, "cache_path", path, "error", err)
+	}
+}
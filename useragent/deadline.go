@@ -0,0 +1,87 @@
+// deadline.go бюджет времени на инициализацию: фоллбэк по истечении срока и подмена версий, пришедших позже
+
+package useragent
+
+import (
+	"errors"
+	"time"
+)
+
+// errInitDeadline сетевые источники не ответили за время WithInitDeadline
+var errInitDeadline = errors.New("сетевые источники версий браузеров не ответили за время инициализации")
+
+// WithInitDeadline ограничивает время, которое NewGenerator ждет ответа сетевых источников, независимо от таймаута HTTP-клиента:
+// если источники не ответили за d, генератор сразу создается с аппроксимированными версиями,
+// а запросы продолжаются в фоне (в пределах таймаута HTTP-клиента), и полученные позже версии
+// подменяют аппроксимацию (с уведомлением подписчиков Subscribe и записью дискового кэша).
+// актуальный дисковый кэш, как и раньше, используется без обращения к сети. 0 - ограничения нет.
+func WithInitDeadline(d time.Duration) Option {
+	return func(g *Generator) {
+		g.initDeadline = max(d, 0)
+	}
+}
+
+// awaitInit запускает fetch в фоне и ждет результат не дольше WithInitDeadline.
+// если срок истек, вызывается fallback и возвращается errInitDeadline, а результат fetch,
+// полученный позже, передается в late (если генератор к тому моменту не закрыт)
+func (g *Generator) awaitInit(fetch func() ([]string, error), fallback func(), late func([]string, error)) ([]string, error) {
+	type result struct {
+		versions []string
+		err      error
+	}
+	results := make(chan result) // небуферизованный: результат получает либо NewGenerator, либо late
+	expired := make(chan struct{})
+
+	g.background.Add(1)
+	go func() {
+		defer g.background.Done()
+		versions, err := fetch()
+		select {
+		case results <- result{versions, err}:
+			return
+		case <-expired:
+		}
+		select {
+		case <-g.done:
+		default:
+			late(versions, err)
+		}
+	}()
+
+	timer := time.NewTimer(g.initDeadline)
+	defer timer.Stop()
+	select {
+	case res := <-results:
+		return res.versions, res.err
+	case <-timer.C:
+		// фоллбэк применяется до того, как late сможет подменить его настоящими версиями
+		fallback()
+		close(expired)
+		return nil, errInitDeadline
+	}
+}
+
+// lateVersions применяет версии Chrome/Edge, полученные после истечения WithInitDeadline
+func (g *Generator) lateVersions(versions []string, err error) {
+	if err != nil {
+		g.logger.Warn("сетевые источники не ответили и после истечения срока инициализации, аппроксимация сохранена",
+			"event", eventInitLateFailed, "error", err)
+		return
+	}
+	g.setVersions(versions, OriginNetwork)
+	g.logger.Info("версии браузеров получены из сети после истечения срока инициализации", "event", eventVersionsUpdated, "fallback", false)
+	if g.diskCachePath != "" {
+		g.saveToDiskCache()
+	}
+}
+
+// lateFirefoxVersions применяет версии Firefox, полученные после истечения WithInitDeadline
+func (g *Generator) lateFirefoxVersions(versions []string, err error) {
+	if err != nil {
+		return // ошибка источника уже записана в лог в fetchFirefoxPool
+	}
+	g.setFirefoxVersions(versions)
+	if g.diskCachePath != "" {
+		g.saveToDiskCache()
+	}
+}
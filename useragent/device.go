@@ -0,0 +1,64 @@
+// device.go устройство, выбранное для набора заголовков: экран, вьюпорт и DPR для настройки автоматизации браузера
+
+package useragent
+
+import "strconv"
+
+// DeviceProfile устройство, под которое сгенерированы подсказки sec-ch-*: те же значения можно передать
+// в средство автоматизации браузера (размер окна, devicePixelRatio), чтобы страница видела тот же экран,
+// что и сервер в заголовках. размеры указаны в CSS-пикселях, как их возвращают screen.width и innerWidth.
+// в JSON профиль отдает и NewHandler в ответе /headers.
+//
+// у Firefox и Safari набор заголовков не зависит от железа, поэтому экран для них не выбирается:
+// размеры и DPR нулевые, заполнены только Platform и Mobile
+type DeviceProfile struct {
+	Class           DeviceClass `json:"class,omitempty"`            // класс устройства: DeviceDesktop, DeviceLaptop, DeviceMobile или DeviceTablet
+	Platform        string      `json:"platform"`                   // платформа (значение sec-ch-ua-platform без кавычек)
+	PlatformVersion string      `json:"platform_version,omitempty"` // версия ОС (sec-ch-ua-platform-version)
+	Mobile          bool        `json:"mobile"`                     // мобильный браузер
+	Model           string      `json:"model,omitempty"`            // модель смартфона, пусто для десктопов
+	Arch            string      `json:"arch,omitempty"`             // архитектура процессора (sec-ch-ua-arch)
+	Bitness         string      `json:"bitness,omitempty"`          // разрядность (sec-ch-ua-bitness)
+	DeviceMemory    string      `json:"device_memory,omitempty"`    // объем памяти в ГБ (device-memory)
+	ScreenWidth     int         `json:"screen_width,omitempty"`     // ширина экрана (screen.width)
+	ScreenHeight    int         `json:"screen_height,omitempty"`    // высота экрана (screen.height)
+	ViewportWidth   int         `json:"viewport_width,omitempty"`   // ширина вьюпорта (window.innerWidth, sec-ch-viewport-width)
+	ViewportHeight  int         `json:"viewport_height,omitempty"`  // высота вьюпорта (window.innerHeight, sec-ch-viewport-height)
+	DPR             float64     `json:"dpr,omitempty"`              // window.devicePixelRatio с учетом масштаба страницы (sec-ch-dpr)
+}
+
+// deviceProfile собирает DeviceProfile из отпечатка
+func deviceProfile(fp fingerprint) DeviceProfile {
+	d := fp.device
+	p := DeviceProfile{
+		Platform:        fp.info.Platform,
+		PlatformVersion: d.PlatformVersion,
+		Mobile:          fp.info.Mobile,
+		Model:           d.Model,
+		Arch:            d.Arch,
+		Bitness:         d.Bitness,
+		DeviceMemory:    d.DeviceMemory,
+		ScreenWidth:     d.screen.Width,
+		ScreenHeight:    d.screen.Height,
+		ViewportWidth:   d.ViewportWidth,
+		ViewportHeight:  d.ViewportHeight,
+	}
+	if p.ScreenWidth > 0 {
+		p.Class = d.screen.Class
+		if p.Class == "" {
+			p.Class = DeviceDesktop
+		}
+	}
+	p.DPR, _ = strconv.ParseFloat(d.DPR, 64)
+	return p
+}
+
+// Device возвращает устройство, для которого сгенерирован набор (см. DeviceProfile)
+func (hs *HeaderSet) Device() DeviceProfile {
+	return hs.device
+}
+
+// Device возвращает устройство сессии: экран, вьюпорт и DPR не меняются от запроса к запросу
+func (s *Session) Device() DeviceProfile {
+	return deviceProfile(s.fp)
+}
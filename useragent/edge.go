@@ -0,0 +1,95 @@
+// edge.go версии Microsoft Edge: собственные номера сборок Edge из репозитория Microsoft,
+// которые в User-Agent и подсказках клиента идут в паре с версией Chromium той же мажорной версии
+
+package useragent
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+)
+
+// secondSourceGrace сколько после ответа первого источника ждать второй: Google дает версии Chromium,
+// Microsoft - сборки Edge, и без ответа Microsoft Edge получает версию Chromium
+const secondSourceGrace = 2 * time.Second
+
+// setEdgeVersions заменяет набор сборок Edge
+func (g *Generator) setEdgeVersions(versions []string) {
+	g.mu.Lock()
+	g.edgeVersions = versions
+	g.mu.Unlock()
+}
+
+// randomEdgeVersions выбирает сборку Edge и версию Chromium для нее, вызывается под g.mu.RLock:
+// сборка - случайная из набора Edge, Chromium - случайная версия набора Chromium с той же мажорной версией.
+// без набора Edge (источник Microsoft не ответил) обе версии равны fallback, как до разделения наборов
+func (g *Generator) randomEdgeVersions(r *rand.Rand, fallback string) (chromium, edge string) {
+	if len(g.edgeVersions) == 0 {
+		return fallback, fallback
+	}
+	edge = g.edgeVersions[r.IntN(len(g.edgeVersions))]
+	return cmp.Or(pickSameMajor(r, g.versions, edge), edge), edge
+}
+
+// edgeFullVersionFor восстанавливает сборку Edge для сокращенного User-Agent так же, как fullVersionFor
+// восстанавливает версию Chromium: случайная сборка набора Edge с той же мажорной версией.
+// пустая строка - сборка неизвестна, и бренд Edge получает версию Chromium
+func (g *Generator) edgeFullVersionFor(r *rand.Rand, info browserInfo) string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return pickSameMajor(r, g.edgeVersions, info.MajorVersion+".")
+}
+
+// latestEdgeVersion возвращает самую свежую сборку Edge с мажорной версией chromium (набор упорядочен
+// от новых к старым, как и набор Chromium) или chromium, если такой сборки нет
+func (g *Generator) latestEdgeVersion(chromium string) string {
+	major, _, _ := strings.Cut(chromium, ".")
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	for _, v := range g.edgeVersions {
+		if strings.HasPrefix(v, major+".") {
+			return v
+		}
+	}
+	return chromium
+}
+
+// pickSameMajor возвращает случайную версию pool с той же мажорной версией, что у version
+// (version может быть и префиксом "142."), пустую строку - если таких нет
+func pickSameMajor(r *rand.Rand, pool []string, version string) string {
+	// два прохода вместо среза подходящих версий: выбор стоит в горячем пути Get и не выделяет память
+	major, _, _ := strings.Cut(version, ".")
+	var n int
+	for _, v := range pool {
+		if hasMajor(v, major) {
+			n++
+		}
+	}
+	if n == 0 {
+		return ""
+	}
+	i := r.IntN(n)
+	for _, v := range pool {
+		if hasMajor(v, major) {
+			if i == 0 {
+				return v
+			}
+			i--
+		}
+	}
+	return ""
+}
+
+// hasMajor сообщает, что версия v относится к мажорной версии major ("142.0.7444.59" и "142")
+func hasMajor(v, major string) bool {
+	return len(v) > len(major) && v[len(major)] == '.' && strings.HasPrefix(v, major)
+}
+
+// edgeVersionsCopy возвращает копию набора сборок Edge
+func (g *Generator) edgeVersionsCopy() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return slices.Clone(g.edgeVersions)
+}
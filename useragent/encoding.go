@@ -0,0 +1,93 @@
+// encoding.go сжатие ответов сетевых источников (явный Accept-Encoding и распаковка по Content-Encoding)
+// и accept-encoding в заголовках браузера
+
+package useragent
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// поддерживаемые кодировки ответов источников, в порядке предпочтения.
+// brotli и zstd не поддерживаются стандартной библиотекой, а пакет не использует внешних зависимостей
+const (
+	encodingGzip     = "gzip"
+	encodingDeflate  = "deflate"
+	encodingIdentity = "identity"
+)
+
+// defaultSourceEncodings кодировки, которые генератор запрашивает у источников по умолчанию:
+// страница репозитория Microsoft Edge сжимается gzip в несколько раз
+var defaultSourceEncodings = []string{encodingGzip, encodingDeflate}
+
+// WithSourceEncodings задает кодировки сжатия, которые генератор запрашивает у источников версий
+// в Accept-Encoding, в порядке предпочтения: "gzip", "deflate" или "identity" (без сжатия).
+// ответ распаковывается самим генератором по Content-Encoding, поэтому поведение не зависит
+// от настроек транспорта пользовательского http.Client. неизвестные значения игнорируются.
+// по умолчанию - "gzip, deflate".
+func WithSourceEncodings(encodings ...string) Option {
+	return func(g *Generator) {
+		var accepted []string
+		for _, e := range encodings {
+			e = strings.ToLower(strings.TrimSpace(e))
+			if (e == encodingGzip || e == encodingDeflate || e == encodingIdentity) && !slices.Contains(accepted, e) {
+				accepted = append(accepted, e)
+			}
+		}
+		if len(accepted) > 0 {
+			g.sourceEncodings = accepted
+		}
+	}
+}
+
+// WithBrowserEncoding добавляет в заголовки accept-encoding, как у браузера заявленной версии:
+// "gzip, deflate, br, zstd" у Chrome и Edge с версии 123 и Firefox с версии 126, без zstd - у более
+// ранних версий и Safari. с явным accept-encoding net/http не распаковывает ответ сам, а brotli и zstd
+// стандартная библиотека не поддерживает, поэтому клиент должен распаковывать ответы самостоятельно.
+// по умолчанию заголовок не отправляется и сжатие выбирает транспорт.
+func WithBrowserEncoding() Option {
+	return func(g *Generator) {
+		g.browserEncoding = true
+	}
+}
+
+// acceptEncoding возвращает значение Accept-Encoding для запросов к источникам
+func (g *Generator) acceptEncoding() string {
+	if len(g.sourceEncodings) == 0 {
+		return strings.Join(defaultSourceEncodings, ", ")
+	}
+	return strings.Join(g.sourceEncodings, ", ")
+}
+
+// decodeBody возвращает распакованное тело ответа по значению Content-Encoding
+func decodeBody(body io.Reader, contentEncoding string) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "", encodingIdentity:
+		return io.NopCloser(body), nil
+	case encodingGzip, "x-gzip":
+		r, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось распаковать gzip: %w", err)
+		}
+		return r, nil
+	case encodingDeflate:
+		// по RFC 9110 deflate - это поток zlib, но часть серверов отдает "сырой" deflate без заголовка
+		br := bufio.NewReader(body)
+		if header, err := br.Peek(2); err == nil && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 && header[0]&0x0f == 8 {
+			r, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, fmt.Errorf("не удалось распаковать deflate: %w", err)
+			}
+			return r, nil
+		}
+		return flate.NewReader(br), nil
+	default:
+		return nil, fmt.Errorf("неподдерживаемая кодировка ответа: %q", contentEncoding)
+	}
+}
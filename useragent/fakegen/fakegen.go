@@ -0,0 +1,139 @@
+// fakegen.go реализация useragent.Provider с фиксированным результатом для модульных тестов
+
+// Package fakegen содержит генератор с заранее заданным результатом: код, зависящий от useragent.Provider,
+// можно тестировать без сети и случайности
+//
+//	gen := fakegen.New()
+//	client := NewScraper(gen) // func NewScraper(ua useragent.Provider) *Scraper
+//	// ... проверки с gen.UserAgent и gen.Targets()
+package fakegen
+
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/imbecility/go-fake-useragent/useragent"
+)
+
+const (
+	// DefaultUserAgent User-Agent, который возвращает New
+	DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/142.0.0.0 Safari/537.36"
+
+	// DefaultCrawlerUserAgent User-Agent поискового бота, для которого не задан CrawlerHeaders
+	DefaultCrawlerUserAgent = "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; Googlebot/2.1; +http://www.google.com/bot.html) Chrome/142.0.0.0 Safari/537.36"
+)
+
+// Generator генератор с фиксированным или заданным тестом результатом, реализует useragent.Provider.
+// поля можно менять до начала использования, методы безопасны для конкурентного вызова
+type Generator struct {
+	// UserAgent возвращается Get и подставляется в заголовок user-agent GetHeaders, если UserAgents пуст
+	UserAgent string
+	// UserAgents сценарий ротации: каждый вызов Get и GetHeaders берет следующую строку по кругу
+	UserAgents []string
+	// Headers заголовки GetHeaders (без user-agent)
+	Headers map[string]string
+	// HeadersFunc, если задана, формирует заголовки GetHeaders для адреса запроса вместо Headers
+	// (user-agent добавляется, только если функция его не вернула)
+	HeadersFunc func(targetURL string) map[string]string
+	// CrawlerHeaders заголовки GetCrawlerHeaders по типу бота, для остальных типов -
+	// заголовки с DefaultCrawlerUserAgent
+	CrawlerHeaders map[useragent.CrawlerType]map[string]string
+
+	mu      sync.Mutex
+	targets []string // адреса вызовов GetHeaders
+	next    int      // позиция в UserAgents
+}
+
+var _ useragent.Provider = (*Generator)(nil)
+
+// New создает генератор с User-Agent Chrome для Windows и типичными заголовками навигации
+func New() *Generator {
+	return &Generator{
+		UserAgent: DefaultUserAgent,
+		Headers: map[string]string{
+			"accept":             "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8",
+			"accept-language":    "ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7",
+			"sec-ch-ua":          `"Chromium";v="142", "Google Chrome";v="142", "Not_A Brand";v="99"`,
+			"sec-ch-ua-mobile":   "?0",
+			"sec-ch-ua-platform": `"Windows"`,
+			"sec-fetch-dest":     "document",
+			"sec-fetch-mode":     "navigate",
+			"sec-fetch-site":     "none",
+			"sec-fetch-user":     "?1",
+
+			"upgrade-insecure-requests": "1",
+		},
+	}
+}
+
+// NewSequence создает генератор, который отдает строки userAgents по очереди (по кругу),
+// например для проверки ротации User-Agent в коде, зависящем от useragent.Provider
+func NewSequence(userAgents ...string) *Generator {
+	g := New()
+	g.UserAgents = slices.Clone(userAgents)
+	return g
+}
+
+// Get возвращает следующую строку UserAgents или UserAgent, если сценарий не задан
+func (g *Generator) Get() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.nextUserAgent()
+}
+
+// nextUserAgent продвигает сценарий UserAgents, вызывается под g.mu
+func (g *Generator) nextUserAgent() string {
+	if len(g.UserAgents) == 0 {
+		return g.UserAgent
+	}
+	ua := g.UserAgents[g.next%len(g.UserAgents)]
+	g.next++
+	return ua
+}
+
+// GetHeaders возвращает копию Headers (или результат HeadersFunc) с заголовком user-agent
+// и запоминает адрес запроса
+func (g *Generator) GetHeaders(targetURL ...string) map[string]string {
+	target := ""
+	if len(targetURL) > 0 {
+		target = targetURL[0]
+	}
+	g.mu.Lock()
+	g.targets = append(g.targets, target)
+	ua := g.nextUserAgent()
+	g.mu.Unlock()
+
+	var headers map[string]string
+	if g.HeadersFunc != nil {
+		headers = maps.Clone(g.HeadersFunc(target))
+	} else {
+		headers = maps.Clone(g.Headers)
+	}
+	if headers == nil {
+		headers = make(map[string]string, 1)
+	}
+	if _, ok := headers["user-agent"]; !ok {
+		headers["user-agent"] = ua
+	}
+	return headers
+}
+
+// GetCrawlerHeaders возвращает копию CrawlerHeaders для типа бота
+func (g *Generator) GetCrawlerHeaders(crawlerType useragent.CrawlerType) map[string]string {
+	if headers, ok := g.CrawlerHeaders[crawlerType]; ok {
+		return maps.Clone(headers)
+	}
+	return map[string]string{
+		"accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+		"accept-language": "en-US,en;q=0.9",
+		"user-agent":      DefaultCrawlerUserAgent,
+	}
+}
+
+// Targets возвращает адреса всех вызовов GetHeaders по порядку (пустая строка - вызов без адреса)
+func (g *Generator) Targets() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return slices.Clone(g.targets)
+}
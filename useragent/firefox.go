@@ -0,0 +1,223 @@
+// firefox.go версии и заголовки Firefox: источник Mozilla product-details, аппроксимация и набор заголовков Gecko
+
+package useragent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// история мажорных релизов Firefox: {"142.0": "2025-08-19", ...}
+	mozillaProductDetailsURL = "https://product-details.mozilla.org/1.0/firefox_history_major_releases.json"
+
+	// количество мажорных версий Firefox в пуле
+	versionsToKeepFromMozilla = 5
+
+	// шаблон User-Agent Firefox: токен платформы (см. platformProfiles),
+	// в токенах rv: и Firefox/ всегда только мажорная версия ("142.0")
+	firefoxUATemplate = "Mozilla/5.0 (%s; rv:%s) Gecko/20100101 Firefox/%s"
+
+	// первая версия Firefox с zstd в accept-encoding (WithBrowserEncoding)
+	firefoxZstdMajor = 126
+)
+
+// firefoxMajorRegex мажорная версия в токене Firefox/ строки User-Agent
+var firefoxMajorRegex = regexp.MustCompile(`Firefox/(\d{1,4})`)
+
+// WithFirefoxProbability включает генерацию User-Agent Firefox с вероятностью p (от 0 до 1):
+// версии Firefox получаются из Mozilla product-details с тем же кэшированием и фоллбэком на аппроксимацию,
+// что и версии Chrome/Edge. по умолчанию Firefox не генерируется.
+func WithFirefoxProbability(p float64) Option {
+	return func(g *Generator) {
+		if math.IsNaN(p) {
+			return
+		}
+		g.firefoxProbability = min(max(p, 0), 1)
+	}
+}
+
+// firefoxEnabled сообщает, может ли генератор выдавать User-Agent Firefox
+func (g *Generator) firefoxEnabled() bool {
+	return g.browserWeight(BrowserFirefox) > 0
+}
+
+// randomFirefoxUA возвращает User-Agent Firefox со случайной версией из пула для одной из платформ WithPlatforms
+func (g *Generator) randomFirefoxUA(r *rand.Rand) string {
+	version := approximateFirefoxVersionForDate(time.Now())
+	if len(g.firefoxVersions) > 0 {
+		version = g.firefoxVersions[r.IntN(len(g.firefoxVersions))]
+	}
+	return g.pickPlatform(r).randomFirefoxUA(r, version)
+}
+
+// approximateFirefoxVersionForDate вычисляет мажорную версию Firefox на дату:
+// релизы выходят каждые 4 недели, опорная точка - Firefox 142 (19.08.2025)
+func approximateFirefoxVersionForDate(d time.Time) string {
+	t0 := time.Date(2025, 8, 19, 0, 0, 0, 0, time.UTC)
+	days := d.Sub(t0).Hours() / 24
+	major := 142 + int(math.Floor(days/28))
+	return fmt.Sprintf("%d.0", major)
+}
+
+// approximateFirefoxVersions генерирует набор правдоподобных версий Firefox на текущую дату
+func (g *Generator) approximateFirefoxVersions() []string {
+	versions := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		versions = append(versions, approximateFirefoxVersionForDate(time.Now().AddDate(0, 0, -i*28)))
+	}
+	return versions
+}
+
+// fetchFirefoxVersions получает последние мажорные версии Firefox из Mozilla product-details или его зеркал
+func (g *Generator) fetchFirefoxVersions(ctx context.Context) ([]string, error) {
+	return g.fetchWithMirrors(ctx, SourceMozilla, g.fetchFirefoxVersionsFrom)
+}
+
+// fetchFirefoxVersionsFrom разбирает историю мажорных релизов Firefox по указанному адресу
+func (g *Generator) fetchFirefoxVersionsFrom(ctx context.Context, url string) ([]string, error) {
+	var history map[string]string
+	err := g.executeGet(ctx, url, func(body io.Reader) error {
+		if err := json.NewDecoder(body).Decode(&history); err != nil {
+			return fmt.Errorf("не удалось декодировать JSON-ответ: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	majors := make([]int, 0, len(history))
+	for version, date := range history {
+		released, err := time.Parse(time.DateOnly, date)
+		if err != nil || released.After(now) {
+			continue
+		}
+		major, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+		if err != nil {
+			continue
+		}
+		majors = append(majors, major)
+	}
+	if len(majors) == 0 {
+		return nil, errors.New("не удалось найти ни одной версии Firefox в ответе product-details")
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(majors)))
+	limit := min(versionsToKeepFromMozilla, len(majors))
+	versions := make([]string, 0, limit)
+	for _, major := range majors[:limit] {
+		versions = append(versions, fmt.Sprintf("%d.0", major))
+	}
+	return versions, nil
+}
+
+// updateFirefoxVersions получает версии Firefox из сети или аппроксимирует их при ошибке,
+// в строгом режиме WithStrictSources вместо аппроксимации возвращает ошибку
+func (g *Generator) updateFirefoxVersions(ctx context.Context) error {
+	var versions []string
+	err := errSourcesOffline
+	if !g.offline {
+		versions, err = g.fetchFirefoxPool(ctx)
+	}
+	if err != nil && g.strictSources {
+		return fmt.Errorf("%w: %w", errStrictSources, err)
+	}
+	g.setFirefoxVersions(versions)
+	return nil
+}
+
+// initFirefoxVersions получает версии Firefox при создании генератора с учетом WithInitDeadline
+func (g *Generator) initFirefoxVersions() error {
+	if g.offline || g.initDeadline <= 0 {
+		return g.updateFirefoxVersions(context.Background())
+	}
+	fallback := func() { g.setFirefoxVersions(nil) }
+	if g.strictSources {
+		fallback = func() {}
+	}
+	versions, err := g.awaitInit(func() ([]string, error) { return g.fetchFirefoxPool(context.Background()) }, fallback, g.lateFirefoxVersions)
+	switch {
+	case err != nil && g.strictSources:
+		return fmt.Errorf("%w: %w", errStrictSources, err)
+	case errors.Is(err, errInitDeadline):
+		return nil
+	}
+	g.setFirefoxVersions(versions)
+	return nil
+}
+
+// fetchFirefoxPool запрашивает версии Firefox у сетевого источника и записывает результат в лог
+func (g *Generator) fetchFirefoxPool(parent context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(parent, g.sourcesTimeout(SourceMozilla))
+	defer cancel()
+	started := time.Now()
+	versions, err := g.fetchFirefoxVersions(ctx)
+	if err != nil {
+		g.logger.WarnContext(ctx, "не удалось получить данные от источника", "event", eventSourceFetchFailed,
+			"source", SourceMozilla.String(), "duration", time.Since(started), "error", err)
+		return nil, err
+	}
+	g.logger.DebugContext(ctx, "получение версий браузеров через источник прошло успешно", "event", eventSourceFetchOK,
+		"source", SourceMozilla.String(), "duration", time.Since(started))
+	return versions, nil
+}
+
+// setFirefoxVersions заменяет пул версий Firefox, пустой набор заменяется аппроксимацией
+func (g *Generator) setFirefoxVersions(versions []string) {
+	if len(versions) == 0 {
+		g.logger.Warn("фоллбэк на аппроксимацию версий Firefox", "event", eventFallback, "fallback", true)
+		versions = g.approximateFirefoxVersions()
+	}
+
+	g.mu.Lock()
+	g.firefoxVersions = versions
+	g.mu.Unlock()
+}
+
+// firefoxHeaders генерирует набор заголовков, который отправляет Firefox при навигации:
+// Firefox не поддерживает User-Agent Client Hints, поэтому sec-ch-* и связанные заголовки не отправляются
+func firefoxHeaders(fp fingerprint, nav navigation) map[string]string {
+	headers := map[string]string{
+		"user-agent":                fp.ua,
+		"accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+		"accept-language":           fp.acceptLanguage,
+		"upgrade-insecure-requests": "1",
+		"sec-fetch-dest":            "document",
+		"sec-fetch-mode":            "navigate",
+		"sec-fetch-site":            nav.secFetchSite(),
+		"sec-fetch-user":            "?1",
+		"priority":                  "u=0, i",
+	}
+	if fp.encoding {
+		headers["accept-encoding"] = firefoxAcceptEncoding(fp.ua)
+	}
+	if referer := nav.referrer(); referer != "" {
+		headers["referer"] = referer
+	}
+	if nav.origin != "" {
+		headers["origin"] = nav.origin
+	}
+	return headers
+}
+
+// firefoxAcceptEncoding значение accept-encoding Firefox с WithBrowserEncoding: zstd включен с Firefox 126
+func firefoxAcceptEncoding(ua string) string {
+	if match := firefoxMajorRegex.FindStringSubmatch(ua); len(match) > 1 {
+		if major, _ := strconv.Atoi(match[1]); major >= firefoxZstdMajor {
+			return "gzip, deflate, br, zstd"
+		}
+	}
+	return "gzip, deflate, br"
+}
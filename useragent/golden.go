@@ -0,0 +1,147 @@
+// golden.go эталонные наборы заголовков реальных браузеров и самопроверка реалистичности
+
+package useragent
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// goldenProfilesJSON - наборы заголовков, снятые с реальных Chrome/Edge (навигация из поиска Google)
+//
+//go:embed data/golden_profiles.json
+var goldenProfilesJSON []byte
+
+// secChUaFormatRegex формат значения sec-ch-ua: три бренда вида "Brand";v="N"
+var secChUaFormatRegex = regexp.MustCompile(`^"[^"]+";v="\d+"(, "[^"]+";v="\d+"){2}$`)
+
+// goldenProfile эталонный набор заголовков для диапазона мажорных версий одного бренда
+type goldenProfile struct {
+	Name     string            `json:"name"`
+	Brand    string            `json:"brand"`
+	MinMajor int               `json:"min_major"`
+	MaxMajor int               `json:"max_major"` // 0 - без верхней границы
+	Headers  map[string]string `json:"headers"`   // заголовки, которые реальный браузер отправляет всегда
+	Optional []string          `json:"optional"`  // заголовки, которые браузер отправляет по запросу сервера (Accept-CH) или транспорт
+	Exact    []string          `json:"exact"`     // заголовки, значения которых должны совпадать с эталоном
+}
+
+// matches проверяет, попадает ли мажорная версия в диапазон профиля
+func (p goldenProfile) matches(major int) bool {
+	return major >= p.MinMajor && (p.MaxMajor == 0 || major <= p.MaxMajor)
+}
+
+var (
+	goldenOnce     sync.Once
+	goldenProfiles []goldenProfile
+	goldenErr      error
+)
+
+// loadGoldenProfiles однократно разбирает встроенный корпус эталонных профилей
+func loadGoldenProfiles() ([]goldenProfile, error) {
+	goldenOnce.Do(func() {
+		goldenErr = json.Unmarshal(goldenProfilesJSON, &goldenProfiles)
+	})
+	return goldenProfiles, goldenErr
+}
+
+// SelfTestIssue описывает одно расхождение сгенерированных заголовков с эталоном
+type SelfTestIssue struct {
+	Profile  string // имя эталонного профиля
+	Header   string // имя заголовка
+	Kind     string // "missing" - нет заголовка, "extra" - лишний заголовок, "value" - неверное значение
+	Expected string // ожидаемое значение (для "missing" и "value")
+	Got      string // сгенерированное значение (для "extra" и "value")
+}
+
+// String возвращает расхождение в читаемом виде
+func (i SelfTestIssue) String() string {
+	switch i.Kind {
+	case "missing":
+		return fmt.Sprintf("%s: нет заголовка %q (ожидалось %q)", i.Profile, i.Header, i.Expected)
+	case "extra":
+		return fmt.Sprintf("%s: лишний заголовок %q: %q", i.Profile, i.Header, i.Got)
+	default:
+		return fmt.Sprintf("%s: %q = %q, ожидалось %q", i.Profile, i.Header, i.Got, i.Expected)
+	}
+}
+
+// SelfTest сравнивает заголовки, которые генерирует пакет, со встроенным корпусом
+// заголовков реальных браузеров и возвращает найденные расхождения:
+// пустой результат означает, что регрессий реалистичности не обнаружено.
+func (g *Generator) SelfTest() ([]SelfTestIssue, error) {
+	profiles, err := loadGoldenProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось разобрать эталонные профили: %w", err)
+	}
+
+	version := g.latestVersion()
+	major, _ := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+
+	var issues []SelfTestIssue
+	for _, p := range profiles {
+		if !p.matches(major) {
+			continue
+		}
+		var ua string
+		switch p.Brand {
+		case "Microsoft Edge":
+			ua = profileFor("Windows").edgeUA(g.uaVersion(version), g.uaVersion(g.latestEdgeVersion(version)))
+		default:
+			ua = profileFor("Windows").chromeUA(g.uaVersion(version))
+		}
+		issues = append(issues, p.diff(g.headersForUA(ua), major)...)
+	}
+	return issues, nil
+}
+
+// diff сравнивает сгенерированный набор заголовков с эталоном
+func (p goldenProfile) diff(headers map[string]string, major int) []SelfTestIssue {
+	var issues []SelfTestIssue
+
+	exact := make(map[string]struct{}, len(p.Exact))
+	for _, k := range p.Exact {
+		exact[k] = struct{}{}
+	}
+	for k, expected := range p.Headers {
+		got, ok := headers[k]
+		if !ok {
+			issues = append(issues, SelfTestIssue{Profile: p.Name, Header: k, Kind: "missing", Expected: expected})
+			continue
+		}
+		if _, ok := exact[k]; ok && got != expected {
+			issues = append(issues, SelfTestIssue{Profile: p.Name, Header: k, Kind: "value", Expected: expected, Got: got})
+		}
+	}
+
+	optional := make(map[string]struct{}, len(p.Optional))
+	for _, k := range p.Optional {
+		optional[k] = struct{}{}
+	}
+	for k, got := range headers {
+		_, known := p.Headers[k]
+		_, opt := optional[k]
+		if !known && !opt {
+			issues = append(issues, SelfTestIssue{Profile: p.Name, Header: k, Kind: "extra", Got: got})
+		}
+	}
+
+	// формат и согласованность sec-ch-ua с заявленной версией
+	if got, ok := headers["sec-ch-ua"]; ok {
+		majorToken := fmt.Sprintf(`"Chromium";v="%d"`, major)
+		if !secChUaFormatRegex.MatchString(got) || !strings.Contains(got, majorToken) || !strings.Contains(got, `"`+p.Brand+`"`) {
+			issues = append(issues, SelfTestIssue{
+				Profile: p.Name, Header: "sec-ch-ua", Kind: "value", Expected: p.Headers["sec-ch-ua"], Got: got,
+			})
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Header < issues[j].Header })
+	return issues
+}
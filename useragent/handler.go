@@ -0,0 +1,192 @@
+// handler.go HTTP-обработчик для запуска генератора отдельным сервисом (sidecar) для клиентов на других языках
+
+package useragent
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// healthStaleAge возраст набора версий, после которого /healthz сообщает о деградации
+const healthStaleAge = 7 * 24 * time.Hour
+
+// handlerCrawlers имена роботов в адресе /crawler/{name}
+var handlerCrawlers = map[string]CrawlerType{
+	"google":     GoogleBot,
+	"bing":       BingBot,
+	"yandex":     YandexBot,
+	"duckduckgo": DuckDuckBot,
+	"baidu":      BaiduSpider,
+	"apple":      AppleBot,
+	"sogou":      SogouSpider,
+}
+
+// handlerHeader заголовок в ответе обработчика
+type handlerHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// handlerHeadersResponse ответ /headers: заголовки в порядке отправки и сведения о браузере
+type handlerHeadersResponse struct {
+	UserAgent string          `json:"user_agent"`
+	Browser   string          `json:"browser"`
+	Version   string          `json:"version"`
+	Platform  string          `json:"platform"`
+	Mobile    bool            `json:"mobile"`
+	Persona   string          `json:"persona,omitempty"`
+	Device    *DeviceProfile  `json:"device,omitempty"`
+	Headers   []handlerHeader `json:"headers"`
+}
+
+// handlerHealthResponse ответ /healthz: свежесть набора версий и результат последнего опроса источников
+type handlerHealthResponse struct {
+	Status         string     `json:"status"` // ok или degraded
+	Versions       int        `json:"versions"`
+	VersionsOrigin string     `json:"versions_origin,omitempty"`
+	VersionsAt     *time.Time `json:"versions_at,omitempty"`
+	VersionsAge    string     `json:"versions_age,omitempty"`
+	LastFetchAt    *time.Time `json:"last_fetch_at,omitempty"`
+	LastFetchError string     `json:"last_fetch_error,omitempty"`
+}
+
+// NewHandler возвращает http.Handler, отдающий User-Agent и заголовки генератора в формате JSON:
+// позволяет запустить генератор отдельным сервисом с общим кэшем версий для парсеров на любых языках.
+//
+//	GET /ua                            {"user_agent": "..."}
+//	GET /headers?url=...&persona=...   заголовки браузера в порядке отправки и устройство (persona - seed PersonaFromSeed)
+//	GET /crawler/{name}                заголовки робота: google, bing, yandex, duckduckgo, baidu, apple, sogou
+//	GET /versions                      текущие версии Chrome/Edge
+//	GET /healthz                       свежесть версий и доступность источников, всегда 200 (status: ok или degraded)
+//	GET /readyz                        200, когда версии загружены и генератор готов отвечать, иначе 503
+//
+// /healthz сообщает degraded, если последний опрос источников завершился ошибкой, версии получены
+// аппроксимацией без WithOfflineMode или старше недели (кроме закрепленных): генератор при этом продолжает работать, поэтому код ответа не меняется
+// и liveness-проба Kubernetes не перезапускает сервис из-за недоступности источников.
+//
+// вместо *Generator можно передать любой Provider, например fakegen.Generator в тестах:
+// персоны, сведения о браузере и устройстве и /versions доступны только у *Generator, заголовки остальных
+// реализаций упорядочиваются, как у навигационного запроса Chrome.
+//
+// обработчик можно подключить к своему mux с префиксом через http.StripPrefix.
+// аутентификации нет: сервис не следует открывать за пределы доверенной сети.
+func NewHandler(p Provider) http.Handler {
+	mux := http.NewServeMux()
+	g, _ := p.(*Generator)
+
+	mux.HandleFunc("GET /ua", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"user_agent": p.Get()})
+	})
+
+	mux.HandleFunc("GET /headers", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		targetURL, persona := query.Get("url"), query.Get("persona")
+		if targetURL != "" && parseAbsoluteURL(targetURL) == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "url должен быть абсолютным http(s) адресом"})
+			return
+		}
+
+		var targets []string
+		if targetURL != "" {
+			targets = []string{targetURL}
+		}
+		var hs *HeaderSet
+		switch {
+		case g == nil:
+			hs = newHeaderSet(p.GetHeaders(targets...))
+		case persona != "":
+			hs = g.PersonaFromSeed(persona).GetHeaderSet(targets...)
+		default:
+			hs = g.GetHeaderSet(targets...)
+		}
+
+		meta := hs.Meta()
+		resp := handlerHeadersResponse{
+			Browser:  meta.Browser,
+			Version:  meta.Version,
+			Platform: meta.Platform,
+			Mobile:   meta.Mobile,
+			Persona:  meta.Persona,
+			Headers:  make([]handlerHeader, 0, hs.Len()),
+		}
+		if g != nil {
+			device := hs.Device()
+			resp.Device = &device
+		}
+		resp.UserAgent, _ = hs.Get("user-agent")
+		for _, h := range hs.Headers() {
+			resp.Headers = append(resp.Headers, handlerHeader(h))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+
+	mux.HandleFunc("GET /crawler/{name}", func(w http.ResponseWriter, r *http.Request) {
+		crawler, ok := handlerCrawlers[strings.ToLower(r.PathValue("name"))]
+		if !ok {
+			names := make([]string, 0, len(handlerCrawlers))
+			for name := range handlerCrawlers {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "неизвестный робот, доступны: " + strings.Join(names, ", ")})
+			return
+		}
+		headers := p.GetCrawlerHeaders(crawler)
+		writeJSON(w, http.StatusOK, map[string]any{"user_agent": headers["user-agent"], "headers": headers})
+	})
+
+	mux.HandleFunc("GET /versions", func(w http.ResponseWriter, r *http.Request) {
+		if g == nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "версии доступны только у *Generator"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string][]string{"versions": g.GetVersions()})
+	})
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		if g == nil {
+			writeJSON(w, http.StatusOK, handlerHealthResponse{Status: "ok"})
+			return
+		}
+		stats := g.Stats()
+		resp := handlerHealthResponse{
+			Status:         "ok",
+			Versions:       stats.Versions,
+			VersionsOrigin: stats.VersionsOrigin,
+			LastFetchError: stats.LastFetchError,
+		}
+		if !stats.VersionsAt.IsZero() {
+			resp.VersionsAt = &stats.VersionsAt
+			resp.VersionsAge = time.Since(stats.VersionsAt).Round(time.Second).String()
+		}
+		if !stats.LastFetchAt.IsZero() {
+			resp.LastFetchAt = &stats.LastFetchAt
+		}
+		stale := stats.VersionsOrigin != OriginStatic && time.Since(stats.VersionsAt) > healthStaleAge
+		if stats.LastFetchError != "" || (stats.VersionsOrigin == OriginApproximation && !g.offline) || stale {
+			resp.Status = "degraded"
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if g != nil && g.Stats().Versions == 0 {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+	})
+
+	return mux
+}
+
+// writeJSON отправляет значение в формате JSON с указанным статусом
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,92 @@
+// headerrules.go таблица заголовков Chromium по версиям: какие заголовки и подсказки клиента существуют в заявленной версии
+
+package useragent
+
+// headerRule правило наличия заголовка в диапазоне мажорных версий Chromium
+type headerRule struct {
+	name  string
+	since int // первая версия, в которой заголовок отправляется
+	until int // последняя версия, в которой заголовок отправлялся, 0 - отправляется до сих пор
+
+	// add вычисляет значение заголовка, который добавляется правилом, и признак его применимости к запросу,
+	// nil - правило только удаляет заголовок вне диапазона версий
+	add func(fp fingerprint, nav navigation) (string, bool)
+}
+
+// chromiumHeaderRules заголовки и подсказки клиента с версиями Chromium, в которых они появились
+// (https://chromestatus.com), заголовки без правила считаются существующими во всех поддерживаемых версиях
+var chromiumHeaderRules = []headerRule{
+	{name: "device-memory", since: 63},
+	{name: "downlink", since: 67},
+	{name: "ect", since: 67},
+	{name: "rtt", since: 67},
+	{name: "sec-ch-ua-arch", since: 89},
+	{name: "sec-ch-ua-model", since: 89},
+	{name: "sec-ch-ua-platform-version", since: 89},
+	{name: "sec-ch-ua-full-version", since: 89},
+	{name: "sec-ch-ua-bitness", since: 93},
+	{name: "sec-ch-ua-full-version-list", since: 98},
+	{name: "sec-ch-ua-wow64", since: 100},
+	{name: "sec-ch-viewport-width", since: 100},
+	{name: "sec-ch-viewport-height", since: 108},
+	{name: "priority", since: 124},
+	{name: "sec-ch-ua-form-factors", since: chromeFormFactorsMajor, add: formFactors},
+	{name: "sec-fetch-storage-access", since: chromeStorageAccessMajor, add: storageAccess},
+	{name: "accept-encoding", add: chromiumAcceptEncoding},
+}
+
+// первые версии Chrome, отправляющие sec-ch-ua-form-factors, sec-fetch-storage-access и zstd в accept-encoding
+const (
+	chromeFormFactorsMajor   = 129
+	chromeStorageAccessMajor = 133
+	chromeZstdMajor          = 123
+)
+
+// applyHeaderRules приводит набор заголовков Chromium в соответствие с заявленной версией:
+// удаляет заголовки, которых в этой версии еще (или уже) нет, и добавляет появившиеся
+func applyHeaderRules(headers map[string]string, fp fingerprint, nav navigation) {
+	major := fp.info.major()
+	if major == 0 {
+		return
+	}
+	for _, rule := range chromiumHeaderRules {
+		if major < rule.since || (rule.until > 0 && major > rule.until) {
+			delete(headers, rule.name)
+			continue
+		}
+		if rule.add == nil {
+			continue
+		}
+		if value, ok := rule.add(fp, nav); ok {
+			headers[rule.name] = value
+		}
+	}
+}
+
+// formFactors значение sec-ch-ua-form-factors по классу устройства
+func formFactors(fp fingerprint, _ navigation) (string, bool) {
+	if fp.device.Mobile {
+		return `"Mobile"`, true
+	}
+	return `"Desktop"`, true
+}
+
+// chromiumAcceptEncoding значение accept-encoding с WithBrowserEncoding: zstd включен по умолчанию с Chrome 123
+func chromiumAcceptEncoding(fp fingerprint, _ navigation) (string, bool) {
+	if !fp.encoding {
+		return "", false
+	}
+	if fp.info.major() >= chromeZstdMajor {
+		return "gzip, deflate, br, zstd", true
+	}
+	return "gzip, deflate, br", true
+}
+
+// storageAccess значение sec-fetch-storage-access: состояние разрешения на доступ к хранилищу
+// во встроенном межсайтовом контексте (подресурсы и фреймы), без выданного разрешения - none
+func storageAccess(_ fingerprint, nav navigation) (string, bool) {
+	if _, subresource := resourceProfiles[nav.resource]; !subresource || nav.secFetchSite() != "cross-site" {
+		return "", false
+	}
+	return "none", true
+}
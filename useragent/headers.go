@@ -3,9 +3,9 @@
 package useragent
 
 import (
+	"cmp"
 	"fmt"
 	"math/rand/v2"
-	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
@@ -14,6 +14,7 @@ import (
 var (
 	uaMajorVersionRegex = regexp.MustCompile(`Chrome/(\d+)`)
 	uaFullVersionRegex  = regexp.MustCompile(`Chrome/(\d+\.\d+\.\d+\.\d+)`)
+	uaEdgeVersionRegex  = regexp.MustCompile(`Edg/(\d+\.\d+\.\d+\.\d+)`)
 	uaPlatformRegex     = regexp.MustCompile(`\(([^;]+)`)
 	deviceMemories      = []string{"4", "8", "16", "32"}
 	dprs                = []string{"1.0", "1.25", "1.5", "2.0"}
@@ -21,67 +22,203 @@ var (
 	downlinks           = []string{"1.5", "2.0", "5.8", "8.0", "9.9", "10.0"}
 )
 
-// greaseChars содержит разрешенные символы в GREASE-бренде.
-const greaseChars = ` ;;:/??==()__-,."` // повторы для повышения вероятности выбора
-
-// generateGreaseBrand создает случайный "GREASE" бренд для заголовка sec-ch-ua,
-// что делает отпечаток менее статичным и более похожим на реальный браузер.
-// подробнее: https://wicg.github.io/ua-client-hints/#grease
-// https://chromium-review.googlesource.com/c/chromium/src/+/2181733
-func generateGreaseBrand() (brand string, version string) {
-	// 99 встречается чаще, повторы для повышения вероятности выбора
-	greaseVersions := []string{"8", "24", "99", "99", "99", "99"}
-	version = greaseVersions[rand.IntN(len(greaseVersions))]
-	// случайное имя бренда, заменяя пробелы спецсимволами
-	baseBrand := "Not A Brand"
-	var sb strings.Builder
-	sb.Grow(len(baseBrand))
-
-	for _, char := range baseBrand {
-		if char == ' ' {
-			sb.WriteByte(greaseChars[rand.IntN(len(greaseChars))])
-		} else {
-			sb.WriteRune(char)
-		}
+// GREASE-бренд sec-ch-ua по алгоритму Chromium (components/embedder_support/user_agent_utils.cc):
+// символы, версия и положение бренда в списке определяются мажорной версией, поэтому у одной версии браузера
+// заголовок всегда одинаков, а порядок брендов меняется от версии к версии
+// https://wicg.github.io/ua-client-hints/#grease
+var (
+	greaseChars    = []string{" ", "(", ":", "-", ".", "/", ")", ";", "=", "?", "_"}
+	greaseVersions = []string{"8", "99", "24"}
+
+	// greaseOrders позиции GREASE-бренда, Chromium и бренда браузера в списке для major % 6
+	greaseOrders = [6][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+)
+
+// версии Chromium, в которых менялся алгоритм GREASE
+const (
+	chromeGreaseV2Major = 103 // бренд ".Not/A)Brand" вместо " Not A;Brand"
+	chromeGreaseV3Major = 105 // бренд, версия и порядок зависят от мажорной версии
+)
+
+// greaseBrand возвращает GREASE-бренд и его версию для мажорной версии Chromium
+func greaseBrand(major int) (brand, version string) {
+	switch {
+	case major < chromeGreaseV2Major:
+		return " Not A;Brand", "99"
+	case major < chromeGreaseV3Major:
+		return ".Not/A)Brand", "99"
+	}
+	n := len(greaseChars)
+	return "Not" + greaseChars[major%n] + "A" + greaseChars[(major+1)%n] + "Brand", greaseVersions[major%len(greaseVersions)]
+}
+
+// brandList строит значение sec-ch-ua (full = false) или sec-ch-ua-full-version-list (full = true)
+// с порядком брендов, как у Chromium заявленной версии
+func brandList(info browserInfo, full bool) string {
+	brands := brandVersions(info, full)
+	parts := make([]string, len(brands))
+	for i, b := range brands {
+		parts[i] = fmt.Sprintf(`"%s";v="%s"`, b.Brand, b.Version)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// brandVersions возвращает бренды sec-ch-ua (full = false) или sec-ch-ua-full-version-list (full = true)
+// в порядке, как у Chromium заявленной версии
+func brandVersions(info browserInfo, full bool) []BrandVersion {
+	major := info.major()
+	grease, greaseVersion := greaseBrand(major)
+	version, brandVersion := info.MajorVersion, info.MajorVersion
+	if full {
+		greaseVersion += ".0.0.0"
+		version, brandVersion = info.FullVersion, info.brandFullVersion()
+	}
+
+	// до Chromium 105 порядок был фиксированным: GREASE-бренд первым, затем Chromium (с 103 - после бренда браузера)
+	var order [3]int
+	switch {
+	case major < chromeGreaseV2Major:
+		order = [3]int{0, 1, 2}
+	case major < chromeGreaseV3Major:
+		order = [3]int{0, 2, 1}
+	default:
+		order = greaseOrders[major%len(greaseOrders)]
 	}
-	brand = sb.String()
-	return
+	brands := make([]BrandVersion, 3)
+	brands[order[0]] = BrandVersion{Brand: grease, Version: greaseVersion}
+	brands[order[1]] = BrandVersion{Brand: "Chromium", Version: version}
+	brands[order[2]] = BrandVersion{Brand: info.SecBrandName, Version: brandVersion}
+	return brands
 }
 
-// screenResolution описывает разрешение экрана
+// WithStableGrease фиксировала GREASE-бренд sec-ch-ua на всё время жизни генератора.
+//
+// Deprecated: GREASE-бренд и порядок брендов теперь вычисляются из мажорной версии Chromium, как в браузере,
+// и одинаковы для всех запросов без этой опции. опция ничего не делает.
+func WithStableGrease() Option {
+	return func(*Generator) {}
+}
+
+// DeviceClass класс устройства, по которому выбирается разрешение экрана
+type DeviceClass string
+
+const (
+	DeviceDesktop DeviceClass = "desktop" // настольный компьютер с внешним монитором
+	DeviceLaptop  DeviceClass = "laptop"  // ноутбук
+	DeviceMobile  DeviceClass = "mobile"  // смартфон
+	DeviceTablet  DeviceClass = "tablet"  // планшет
+)
+
+// screenResolution описывает разрешение экрана в CSS-пикселях
 type screenResolution struct {
-	Width  int
-	Height int
+	Width  int         `json:"width"`
+	Height int         `json:"height"`
+	Weight float64     `json:"weight,omitempty"` // относительная частота, 0 у всех - равновероятный выбор
+	Class  DeviceClass `json:"class,omitempty"`  // класс устройства, пусто - десктоп
+	DPR    string      `json:"dpr,omitempty"`    // типичный device pixel ratio, пусто - случайный из таблицы dprs
 }
 
-// commonResolutions содержит список популярных разрешений для десктопов.
+// commonResolutions содержит список популярных разрешений по классам устройств с весами из статистики.
+// разрешение указано в CSS-пикселях, поэтому DPR с ним связан: 1536x864 - это экран 1920x1080 с масштабом 125%.
 // https://gs.statcounter.com/screen-resolution-stats/desktop/worldwide
+// https://gs.statcounter.com/screen-resolution-stats/mobile/worldwide
 var commonResolutions = []screenResolution{
-	{1920, 1080}, // ~24%
-	{1366, 768},  // ~11%
-	{1536, 864},  // ~11%
-	{1280, 720},  // ~6%
-	{1440, 900},  // ~4%
-	{2560, 1440}, // ~3%
+	{Width: 1920, Height: 1080, Weight: 24, Class: DeviceDesktop, DPR: "1.0"},
+	{Width: 2560, Height: 1440, Weight: 3, Class: DeviceDesktop, DPR: "1.0"},
+	{Width: 1600, Height: 900, Weight: 2, Class: DeviceDesktop, DPR: "1.0"},
+	{Width: 1366, Height: 768, Weight: 11, Class: DeviceLaptop, DPR: "1.0"},
+	{Width: 1536, Height: 864, Weight: 11, Class: DeviceLaptop, DPR: "1.25"},
+	{Width: 1280, Height: 720, Weight: 6, Class: DeviceLaptop, DPR: "1.5"},
+	{Width: 1440, Height: 900, Weight: 4, Class: DeviceLaptop, DPR: "2.0"},
+	{Width: 360, Height: 780, Weight: 10, Class: DeviceMobile, DPR: "3"},
+	{Width: 412, Height: 915, Weight: 8, Class: DeviceMobile, DPR: "2.625"},
+	{Width: 393, Height: 873, Weight: 7, Class: DeviceMobile, DPR: "2.75"},
+	{Width: 384, Height: 854, Weight: 4, Class: DeviceMobile, DPR: "2.8125"},
+	{Width: 384, Height: 832, Weight: 3, Class: DeviceMobile, DPR: "2.8125"},
+	{Width: 800, Height: 1280, Weight: 3, Class: DeviceTablet, DPR: "1.5"},
+	{Width: 820, Height: 1180, Weight: 2, Class: DeviceTablet, DPR: "2.0"},
 }
 
+// desktopClasses классы устройств, разрешения которых используются для десктопных браузеров
+var desktopClasses = []DeviceClass{DeviceDesktop, DeviceLaptop}
+
 // вьюпорт (viewport, с англ. — «окно просмотра») никогда не может быть равен размерам экрана, он всегда меньше, и нужно учесть:
-// типичный заголовок окна с панелью поиска/инструментов: 90px в Edge или 128 в Chrome +
-// 60px высота панели задач Windows 11
+// интерфейс браузера - вкладки и панель адреса: ~80-90px, с панелью закладок ~110-120px
+// (место, занятое панелями ОС, учитывается отдельно в модели платформы, см. viewport.go)
 // ширина окна браузера уменьшается за счет боковых панелей в Edge на 64px или 128px в если включены боковые вкладки
 var (
-	viewportHeightSubtractions = []int{90, 128, 150, 188} // панели инструментов/поиска, заголовки, панель задач ОС
-	viewportWidthSubtractions  = []int{2, 4, 64, 128}     // cкроллбар, боковые панели, рамки окна
+	viewportHeightSubtractions = []int{79, 87, 111, 119} // вкладки, панель адреса, панель закладок
+	viewportWidthSubtractions  = []int{2, 4, 64, 128}    // cкроллбар, боковые панели, рамки окна
 )
 
+// deviceHints согласованные между собой подсказки об устройстве для заголовков sec-ch-*
+type deviceHints struct {
+	Mobile          bool
+	Model           string // пусто для десктопов
+	PlatformVersion string
+	Arch            string
+	Bitness         string
+	Wow64           bool
+	DeviceMemory    string
+	DPR             string
+	ViewportWidth   int
+	ViewportHeight  int
+
+	screen screenResolution // выбранное разрешение экрана
+}
+
+// desktopHints выбирает железо, разрешение экрана и состояние окна десктопного браузера,
+// подсказки платформы (версия ОС, архитектура, разрядность) берутся из профиля платформы
+func (d *realismData) desktopHints(r *rand.Rand, profile platformProfile) deviceHints {
+
+	// случайное разрешение экрана с учетом весов и связанный с ним DPR
+	resolution := d.pickResolution(r, desktopClasses...)
+	dpr := resolution.DPR
+	if dpr == "" {
+		dpr = d.DPRs[r.IntN(len(d.DPRs))]
+	}
+
+	// состояние окна (развернуто или нет, масштаб) и вьюпорт с учетом панелей ОС и браузера
+	window := d.pickWindowState(r, profile, resolution)
+	return deviceHints{
+		PlatformVersion: profile.pickPlatformVersion(r),
+		Arch:            profile.pickArch(r),
+		Bitness:         profile.bitness,
+		Wow64:           profile.wow64,
+		DeviceMemory:    d.DeviceMemories[r.IntN(len(d.DeviceMemories))],
+		DPR:             scaleDPR(dpr, window.Zoom),
+		ViewportWidth:   window.Width,
+		ViewportHeight:  window.Height,
+		screen:          resolution,
+	}
+}
+
 // browserInfo хранит разобранные данные из строки User-Agent
 type browserInfo struct {
 	UserAgent    string
 	MajorVersion string
 	FullVersion  string
-	Platform     string // "Windows" || "Linux"
-	BrandName    string // "Google Chrome" || "Microsoft Edge"
-	SecBrandName string // "Google Chrome" || "Microsoft Edge"
+	// BrandFullVersion полная версия бренда, если она отличается от версии Chromium (сборка Edge), иначе пусто
+	BrandFullVersion string
+	Platform         string // "Windows" || "Linux" || "macOS" || "Android"
+	Mobile           bool   // мобильный браузер (токен Mobile в User-Agent)
+	BrandName        string // "Google Chrome" || "Microsoft Edge" || "Firefox" || "Safari"
+	SecBrandName     string // "Google Chrome" || "Microsoft Edge"
+}
+
+// major возвращает мажорную версию браузера числом, 0 - если версия неизвестна или неправдоподобна
+// (больше четырех цифр, как в chromiumVersionRegex): такие строки встречаются во внешних списках WithUserAgentList
+func (info browserInfo) major() int {
+	major, err := strconv.Atoi(info.MajorVersion)
+	if err != nil || major > 9999 {
+		return 0
+	}
+	return major
+}
+
+// brandFullVersion возвращает полную версию бренда для sec-ch-ua-full-version: сборку Edge или версию Chromium
+func (info browserInfo) brandFullVersion() string {
+	return cmp.Or(info.BrandFullVersion, info.FullVersion)
 }
 
 // parseUserAgent извлекает структурированную информацию из строки User-Agent
@@ -98,22 +235,28 @@ func parseUserAgent(ua string) browserInfo {
 		info.FullVersion = info.MajorVersion // фоллбэк на мажорную версию
 	}
 
-	// 2. извлечение платформы
-	if match := uaPlatformRegex.FindStringSubmatch(ua); len(match) > 1 {
-		platformStr := strings.Fields(match[1])[0]
-		if strings.EqualFold(platformStr, "windows") {
-			info.Platform = "Windows"
-		} else {
-			info.Platform = "Linux"
-		}
+	// 2. извлечение платформы (см. platformProfiles)
+	if uaPlatformRegex.MatchString(ua) {
+		info.Platform = detectPlatform(ua)
 	} else {
 		info.Platform = "Windows"
 	}
 
+	info.Mobile = strings.Contains(ua, " Mobile")
+
 	// 3. определение бренда
-	if strings.Contains(ua, "Edg/") {
+	if strings.Contains(ua, "Firefox/") && !strings.Contains(ua, "Chrome/") {
+		info.BrandName = "Firefox"
+		info.SecBrandName = "Firefox"
+	} else if strings.Contains(ua, "Version/") && strings.Contains(ua, "Safari/") && !strings.Contains(ua, "Chrome/") {
+		info.BrandName = "Safari"
+		info.SecBrandName = "Safari"
+	} else if strings.Contains(ua, "Edg/") {
 		info.BrandName = "Microsoft Edge"
 		info.SecBrandName = "Microsoft Edge"
+		if match := uaEdgeVersionRegex.FindStringSubmatch(ua); len(match) > 1 && match[1] != info.FullVersion {
+			info.BrandFullVersion = match[1]
+		}
 	} else {
 		info.BrandName = "Google Chrome"
 		info.SecBrandName = "Google Chrome"
@@ -124,93 +267,141 @@ func parseUserAgent(ua string) browserInfo {
 
 // GetHeaders генерирует набор правдоподобных HTTP-заголовков, имитирующих запрос браузера.
 // Он принимает необязательный URL, который используется для формирования заголовков
-// 'Referer' и 'Origin'. Если URL не указан, используется 'https://google.com'
+// 'Referer' и 'Origin'. Если URL не указан, используется 'https://www.google.com/'
 // в качестве запасного варианта для 'Referer', а 'Origin' опускается.
+// Referer формируется по политике WithReferrerPolicy (по умолчанию strict-origin-when-cross-origin),
+// страница, с которой совершается переход, выбирается по стратегии WithRefererStrategy.
 // Возвращаемая карта может быть безопасно изменена вызывающей стороной.
 func (g *Generator) GetHeaders(targetURL ...string) map[string]string {
-	ua := g.Get()
-	info := parseUserAgent(ua)
+	return g.headersFor(g.Get(), g.navigationFor(targetURL...))
+}
 
-	var referer, origin string
-	var hasURL bool
-	var secFetchSite = "none"
-	var u *url.URL
-	var err error
-	if len(targetURL) > 0 && targetURL[0] != "" {
-		u, err = url.Parse(targetURL[0])
-		hasURL = err == nil
-	}
+// headersForUA генерирует набор заголовков для заданной строки User-Agent
+func (g *Generator) headersForUA(ua string, targetURL ...string) map[string]string {
+	return g.headersFor(ua, g.navigationFor(targetURL...))
+}
 
-	if !hasURL {
-		u, _ = url.Parse("https://www.google.com/search?q=")
-		secFetchSite = "cross-site"
+// navigationFor описывает переход для GetHeaders по стратегии WithRefererStrategy: по умолчанию при наличии
+// адреса - внутри сайта с его главной страницы, иначе - из поисковой выдачи Google
+func (g *Generator) navigationFor(targetURL ...string) navigation {
+	nav := navigation{policy: g.referrerPolicy}
+	if len(targetURL) > 0 {
+		nav.to = parseAbsoluteURL(targetURL[0])
 	}
-
-	referer = fmt.Sprintf("%s://%s", u.Scheme, u.Host)
-	if hasURL {
-		origin = referer
-		secFetchSite = "same-origin"
+	var origin bool
+	nav.from, origin = g.refererPage(g.rng, nav.to)
+	if origin {
+		nav.origin = originOf(nav.to)
 	}
+	if g.refererStrategy == RefererNone {
+		nav.policy = NoReferrer
+	}
+	return nav
+}
 
-	// динамическая генерация sec-ch-ua
-	greaseBrand, greaseVersion := generateGreaseBrand()
-
-	// генерация полных версий для sec-ch-ua-full-version-list
-	greaseFullVersion := fmt.Sprintf("%s.0.0.0", greaseVersion)
+// fingerprint случайно выбранные характеристики браузера, из которых строятся заголовки:
+// выбирается заново для каждого вызова GetHeaders и один раз для Session
+type fingerprint struct {
+	ua       string
+	info     browserInfo
+	device   deviceHints
+	ect      string // пусто - подсказки о качестве сети не отправляются (WithNetworkHints)
+	rtt      string
+	downlink string
+	saveData bool // экономия трафика (WithSaveData)
+	gpc      bool // Global Privacy Control (WithGPC)
+	dnt      bool // Do Not Track (WithDoNotTrack)
+	encoding bool // accept-encoding браузера (WithBrowserEncoding)
 
-	secChUaFullList := fmt.Sprintf(
-		`"%s";v="%s", "%s";v="%s", "%s";v="%s"`,
-		info.SecBrandName, info.FullVersion,
-		greaseBrand, greaseFullVersion,
-		"Chromium", info.FullVersion,
-	)
+	acceptLanguage string // значение accept-language по языкам WithLocales
+}
 
-	secChUa := fmt.Sprintf(
-		`"%s";v="%s", "%s";v="%s", "%s";v="%s"`,
-		info.SecBrandName, info.MajorVersion,
-		greaseBrand, greaseVersion,
-		"Chromium", info.MajorVersion,
-	)
+// newFingerprint выбирает железо, сеть и экран для строки User-Agent
+func (g *Generator) newFingerprint(r *rand.Rand, ua string) fingerprint {
+	fp := fingerprint{ua: ua, info: parseUserAgent(ua)}
+	if fp.info.BrandName == "Microsoft Edge" && strings.HasSuffix(fp.info.FullVersion, reducedVersionSuffix) {
+		fp.info.BrandFullVersion = g.edgeFullVersionFor(r, fp.info)
+	}
+	fp.info.FullVersion = g.fullVersionFor(r, fp.info)
+	fp.acceptLanguage = acceptLanguage(fp.info.BrandName, g.locales)
+	fp.gpc, fp.dnt = g.pickPrivacySignals(r, fp.info)
+	fp.encoding = g.browserEncoding
+	if fp.info.BrandName == "Firefox" || fp.info.BrandName == "Safari" {
+		// набор заголовков Firefox и Safari не зависит от железа и экрана
+		return fp
+	}
 
 	// рандомизация железа и сети
-	deviceMemory := deviceMemories[rand.IntN(len(deviceMemories))]
-	dpr := dprs[rand.IntN(len(dprs))]
-	rtt := rtts[rand.IntN(len(rtts))]
-	downlink := downlinks[rand.IntN(len(downlinks))]
+	data := g.realism()
+
+	// устройство: смартфон для мобильного User-Agent (и планшет Android без токена Mobile), иначе десктоп
+	if fp.info.Mobile || fp.info.Platform == "Android" {
+		fp.device = data.androidHints(r)
+		fp.device.Mobile = fp.info.Mobile
+	} else {
+		fp.device = data.desktopHints(r, g.hintsProfile(r, fp.info))
+	}
+	alignHintsWithUA(ua, &fp.device)
+	if !g.noNetworkHints {
+		// на смартфоне медленное соединение встречается чаще, чем на десктопе
+		fp.ect, fp.rtt, fp.downlink = data.networkHints(r, fp.device.Mobile)
+	}
+	fp.saveData = g.pickSaveData(r, fp.ect)
+	return fp
+}
 
-	// случайное разрешение экрана
-	resolution := commonResolutions[rand.IntN(len(commonResolutions))]
+// headersFor генерирует набор заголовков для заданной строки User-Agent и перехода
+// и применяет к нему обработчики WithHeaderTransformer
+func (g *Generator) headersFor(ua string, nav navigation) map[string]string {
+	return g.transformHeaders(requestHeaders(g.newFingerprint(g.rng, ua), nav))
+}
 
-	// случайное значение для панелей инструментов и т.д.
-	heightSubtraction := viewportHeightSubtractions[rand.IntN(len(viewportHeightSubtractions))]
-	widthSubtraction := viewportWidthSubtractions[rand.IntN(len(viewportWidthSubtractions))]
+// browserHeaders генерирует набор заголовков браузера по выбранным характеристикам и переходу
+func browserHeaders(fp fingerprint, nav navigation) map[string]string {
+	ua, info, device := fp.ua, fp.info, fp.device
+	switch info.BrandName {
+	case "Firefox":
+		return firefoxHeaders(fp, nav)
+	case "Safari":
+		return safariHeaders(fp, nav)
+	}
 
-	// вычисление размеров вьюпорта
-	viewportHeight := strconv.Itoa(resolution.Height - heightSubtraction)
-	viewportWidth := strconv.Itoa(resolution.Width - widthSubtraction)
+	referer := nav.referrer()
+	secFetchSite := nav.secFetchSite()
+	origin := nav.origin
+
+	secChUa := brandList(info, false)
+	secChUaFullList := brandList(info, true)
+
+	viewportHeight := strconv.Itoa(device.ViewportHeight)
+	viewportWidth := strconv.Itoa(device.ViewportWidth)
+	mobile := "?0"
+	if device.Mobile {
+		mobile = "?1"
+	}
+	wow64 := "?0"
+	if device.Wow64 {
+		wow64 = "?1"
+	}
 
 	headers := map[string]string{
 		"user-agent":                  ua,
 		"accept":                      "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
-		"accept-language":             "ru-RU,ru;q=0.9,en-US;q=0.8,en;q=0.7",
-		"device-memory":               deviceMemory,
-		"downlink":                    downlink,
-		"dpr":                         dpr,
-		"ect":                         "4g",
-		"rtt":                         rtt,
-		"referer":                     referer,
+		"accept-language":             fp.acceptLanguage,
+		"device-memory":               device.DeviceMemory,
+		"dpr":                         device.DPR,
 		"cache-control":               "no-cache",
 		"pragma":                      "no-cache",
 		"sec-ch-ua":                   secChUa,
-		"sec-ch-ua-arch":              `"x86"`,
-		"sec-ch-ua-bitness":           `"64"`,
-		"sec-ch-ua-full-version":      fmt.Sprintf(`"%s"`, info.FullVersion),
+		"sec-ch-ua-arch":              fmt.Sprintf(`"%s"`, device.Arch),
+		"sec-ch-ua-bitness":           fmt.Sprintf(`"%s"`, device.Bitness),
+		"sec-ch-ua-full-version":      fmt.Sprintf(`"%s"`, info.brandFullVersion()),
 		"sec-ch-ua-full-version-list": secChUaFullList,
-		"sec-ch-ua-mobile":            "?0",
-		"sec-ch-ua-model":             `""`,
+		"sec-ch-ua-mobile":            mobile,
+		"sec-ch-ua-model":             fmt.Sprintf(`"%s"`, device.Model),
 		"sec-ch-ua-platform":          fmt.Sprintf(`"%s"`, info.Platform),
-		"sec-ch-ua-platform-version":  `"19.0.0"`,
-		"sec-ch-ua-wow64":             "?0",
+		"sec-ch-ua-platform-version":  fmt.Sprintf(`"%s"`, device.PlatformVersion),
+		"sec-ch-ua-wow64":             wow64,
 		"sec-ch-viewport-height":      viewportHeight,
 		"sec-ch-viewport-width":       viewportWidth,
 		"viewport-width":              viewportWidth,
@@ -222,6 +413,17 @@ func (g *Generator) GetHeaders(targetURL ...string) map[string]string {
 		"priority":                    "u=0, i",
 	}
 
+	if fp.ect != "" {
+		headers["downlink"] = fp.downlink
+		headers["ect"] = fp.ect
+		headers["rtt"] = fp.rtt
+	}
+	if fp.saveData {
+		headers["save-data"] = "on"
+	}
+	if referer != "" {
+		headers["referer"] = referer
+	}
 	if origin != "" {
 		headers["origin"] = origin
 	}
@@ -0,0 +1,27 @@
+// headers_test.go фаззинг разбора строк User-Agent, в том числе из внешних списков WithUserAgentList
+
+package useragent
+
+import "testing"
+
+// FuzzParseUserAgent произвольная строка User-Agent не роняет разбор и построение подсказок клиента
+func FuzzParseUserAgent(f *testing.F) {
+	f.Add("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/142.0.0.0 Safari/537.36 Edg/142.0.0.0")
+	f.Add("Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/26.0 Safari/605.1.15")
+	f.Add("Mozilla/5.0 (X11; Linux x86_64; rv:144.0) Gecko/20100101 Firefox/144.0")
+	f.Add("Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/142.0.0.0 Mobile Safari/537.36")
+	f.Add("Chrome/99999999999999999999.0")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, ua string) {
+		info := parseUserAgent(ua)
+		if info.UserAgent != ua {
+			t.Fatalf("разбор изменил строку User-Agent: %q", info.UserAgent)
+		}
+		if info.major() < 0 {
+			t.Fatalf("отрицательная мажорная версия в %q", ua)
+		}
+		_ = fullVersion(info)
+		_ = brandVersions(info, true)
+	})
+}
@@ -0,0 +1,254 @@
+// headerset.go упорядоченный набор заголовков и пользовательские обработчики сгенерированных заголовков
+
+package useragent
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Header заголовок запроса: имя в нижнем регистре и значение
+type Header struct {
+	Name  string
+	Value string
+}
+
+// HeaderSet упорядоченный набор заголовков: порядок соответствует порядку отправки браузером.
+// наборы, возвращаемые GetHeaderSet, дополнительно содержат сведения о выбранном браузере (Meta) и устройстве (Device)
+type HeaderSet struct {
+	headers []Header
+	meta    HeaderMeta
+	device  DeviceProfile
+	ctx     context.Context // контекст вызова GetHeadersCtx, nil - вызов без контекста
+}
+
+// HeaderMeta сведения о браузере и запросе, для которых сгенерирован набор заголовков
+type HeaderMeta struct {
+	Browser  string       // семейство браузера: "Google Chrome", "Microsoft Edge", "Firefox", "Safari"
+	Version  string       // полная версия браузера (у Edge - собственная сборка Edge, а не версия Chromium)
+	Platform string       // платформа (значение sec-ch-ua-platform без кавычек)
+	Mobile   bool         // мобильный браузер
+	Persona  string       // seed персоны PersonaFromSeed, пусто для случайного отпечатка
+	Resource ResourceType // тип запроса, пусто - навигация GetHeaders
+}
+
+// headerOrder порядок заголовков навигационного запроса Chrome,
+// заголовки не из списка идут после них в алфавитном порядке
+var headerOrder = []string{
+	"cache-control", "pragma", "sec-ch-ua", "sec-ch-ua-mobile", "sec-ch-ua-platform", "origin",
+	"dnt", "upgrade-insecure-requests", "user-agent", "accept", "sec-fetch-site", "sec-fetch-mode", "sec-fetch-user",
+	"sec-fetch-dest", "sec-fetch-storage-access", "referer", "accept-encoding", "accept-language", "priority",
+}
+
+// newHeaderSet создает упорядоченный набор из карты заголовков
+func newHeaderSet(m map[string]string) *HeaderSet {
+	hs := &HeaderSet{headers: make([]Header, 0, len(m))}
+	for name, value := range m {
+		hs.headers = append(hs.headers, Header{Name: strings.ToLower(name), Value: value})
+	}
+	slices.SortFunc(hs.headers, func(a, b Header) int {
+		ia, ib := headerRank(a.Name), headerRank(b.Name)
+		if ia != ib {
+			return ia - ib
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return hs
+}
+
+// headerRank возвращает позицию заголовка в headerOrder или len(headerOrder) для остальных
+func headerRank(name string) int {
+	if i := slices.Index(headerOrder, name); i >= 0 {
+		return i
+	}
+	return len(headerOrder)
+}
+
+// Get возвращает значение заголовка (имя без учета регистра) и признак его наличия
+func (hs *HeaderSet) Get(name string) (string, bool) {
+	name = strings.ToLower(name)
+	for _, h := range hs.headers {
+		if h.Name == name {
+			return h.Value, true
+		}
+	}
+	return "", false
+}
+
+// Set заменяет значение существующего заголовка на его месте или добавляет новый в конец
+func (hs *HeaderSet) Set(name, value string) {
+	name = strings.ToLower(name)
+	for i := range hs.headers {
+		if hs.headers[i].Name == name {
+			hs.headers[i].Value = value
+			return
+		}
+	}
+	hs.headers = append(hs.headers, Header{Name: name, Value: value})
+}
+
+// Del удаляет заголовок
+func (hs *HeaderSet) Del(name string) {
+	name = strings.ToLower(name)
+	hs.headers = slices.DeleteFunc(hs.headers, func(h Header) bool { return h.Name == name })
+}
+
+// Len возвращает количество заголовков
+func (hs *HeaderSet) Len() int {
+	return len(hs.headers)
+}
+
+// Headers возвращает копию заголовков в порядке отправки
+func (hs *HeaderSet) Headers() []Header {
+	return slices.Clone(hs.headers)
+}
+
+// MoveFirst переставляет указанные заголовки в начало набора в заданном порядке,
+// отсутствующие имена пропускаются, порядок остальных заголовков сохраняется
+func (hs *HeaderSet) MoveFirst(names ...string) {
+	front := make([]Header, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if i := slices.IndexFunc(hs.headers, func(h Header) bool { return h.Name == name }); i >= 0 {
+			front = append(front, hs.headers[i])
+			hs.headers = slices.Delete(hs.headers, i, i+1)
+		}
+	}
+	hs.headers = append(front, hs.headers...)
+}
+
+// Meta возвращает сведения о браузере и запросе, для которых сгенерирован набор
+func (hs *HeaderSet) Meta() HeaderMeta {
+	return hs.meta
+}
+
+// ToOrderedPairs возвращает пары имя-значение в порядке отправки,
+// например для HTTP-клиентов, которые сохраняют порядок заголовков
+func (hs *HeaderSet) ToOrderedPairs() [][2]string {
+	pairs := make([][2]string, len(hs.headers))
+	for i, h := range hs.headers {
+		pairs[i] = [2]string{h.Name, h.Value}
+	}
+	return pairs
+}
+
+// ToHTTPHeader возвращает заголовки в виде http.Header для http.Request (порядок теряется)
+func (hs *HeaderSet) ToHTTPHeader() http.Header {
+	header := make(http.Header, len(hs.headers))
+	for _, h := range hs.headers {
+		header.Set(h.Name, h.Value)
+	}
+	return header
+}
+
+// ToCurl возвращает команду curl для запроса targetURL с заголовками набора в порядке отправки,
+// аргументы экранируются для POSIX-оболочки
+func (hs *HeaderSet) ToCurl(targetURL string) string {
+	var sb strings.Builder
+	sb.WriteString("curl ")
+	sb.WriteString(shellQuote(targetURL))
+	for _, h := range hs.headers {
+		sb.WriteString(" -H ")
+		sb.WriteString(shellQuote(h.Name + ": " + h.Value))
+	}
+	return sb.String()
+}
+
+// shellQuote заключает строку в одинарные кавычки для POSIX-оболочки
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// ToMap возвращает заголовки в виде карты (порядок теряется)
+func (hs *HeaderSet) ToMap() map[string]string {
+	m := make(map[string]string, len(hs.headers))
+	for _, h := range hs.headers {
+		m[h.Name] = h.Value
+	}
+	return m
+}
+
+// WithHeaderTransformer добавляет обработчик, вызываемый после генерации заголовков браузера
+// (GetHeaders, GetNavigationHeaders и т.д.): позволяет единообразно добавлять, удалять или переставлять
+// заголовки, например добавить токен партнера или убрать подсказки клиента.
+// обработчики вызываются в порядке добавления и должны быть безопасны для конкурентного вызова.
+func WithHeaderTransformer(transform func(*HeaderSet)) Option {
+	return func(g *Generator) {
+		if transform != nil {
+			g.transformers = append(g.transformers, transform)
+		}
+	}
+}
+
+// transformHeaders применяет обработчики WithHeaderTransformer к сгенерированным заголовкам
+func (g *Generator) transformHeaders(headers map[string]string) map[string]string {
+	return g.transformHeadersCtx(context.Background(), headers)
+}
+
+// transformHeadersCtx применяет обработчики WithHeaderTransformer, передавая им контекст вызова
+func (g *Generator) transformHeadersCtx(ctx context.Context, headers map[string]string) map[string]string {
+	if len(g.transformers) == 0 {
+		return headers
+	}
+	hs := newHeaderSet(headers)
+	hs.ctx = ctx
+	for _, transform := range g.transformers {
+		transform(hs)
+	}
+	return hs.ToMap()
+}
+
+// GetHeaderSet аналогичен GetHeaders, но возвращает упорядоченный набор заголовков со сведениями
+// о выбранном браузере: из него можно получить карту, http.Header, упорядоченные пары или команду curl
+func (g *Generator) GetHeaderSet(targetURL ...string) *HeaderSet {
+	fp := g.newFingerprint(g.rng, g.Get())
+	return g.headerSet(fp, "", g.navigationFor(targetURL...), nil)
+}
+
+// GetHeaderSetFor аналогичен GetHeadersFor, но возвращает упорядоченный набор заголовков
+func (g *Generator) GetHeaderSetFor(resource ResourceType, pageURL, resourceURL string) *HeaderSet {
+	fp := g.newFingerprint(g.rng, g.Get())
+	return g.headerSet(fp, "", g.resourceNavigation("", resource, pageURL, resourceURL), nil)
+}
+
+// GetHeaderSet аналогичен Generator.GetHeaderSet для браузера сессии
+func (s *Session) GetHeaderSet(targetURL ...string) *HeaderSet {
+	return s.g.headerSet(s.fp, s.persona, s.g.navigationFor(targetURL...), s.applyCache)
+}
+
+// GetNavigationHeaderSet аналогичен GetNavigationHeaders, но возвращает упорядоченный набор заголовков:
+// последовательные вызовы с адресом предыдущей страницы в fromURL воспроизводят цепочку переходов
+func (s *Session) GetNavigationHeaderSet(fromURL, toURL string) *HeaderSet {
+	return s.g.headerSet(s.fp, s.persona, s.g.linkNavigation("", fromURL, toURL), s.applyCache)
+}
+
+// GetHeaderSetFor аналогичен Generator.GetHeaderSetFor для браузера сессии
+func (s *Session) GetHeaderSetFor(resource ResourceType, pageURL, resourceURL string) *HeaderSet {
+	return s.g.headerSet(s.fp, s.persona, s.g.resourceNavigation("", resource, pageURL, resourceURL), s.applyCache)
+}
+
+// headerSet генерирует упорядоченный набор заголовков с метаданными и применяет к нему
+// обработчики WithHeaderTransformer, adjust (если задан) изменяет заголовки до упорядочивания
+func (g *Generator) headerSet(fp fingerprint, persona string, nav navigation, adjust func(*url.URL, map[string]string)) *HeaderSet {
+	headers := requestHeaders(fp, nav)
+	if adjust != nil {
+		adjust(nav.to, headers)
+	}
+	hs := newHeaderSet(headers)
+	hs.meta = HeaderMeta{
+		Browser:  fp.info.BrandName,
+		Version:  fp.info.brandFullVersion(),
+		Platform: fp.info.Platform,
+		Mobile:   fp.info.Mobile,
+		Persona:  persona,
+		Resource: nav.resource,
+	}
+	hs.device = deviceProfile(fp)
+	for _, transform := range g.transformers {
+		transform(hs)
+	}
+	return hs
+}
@@ -0,0 +1,191 @@
+// httpmw.go подстановка заголовков браузера в запросы net/http: обертки для http.Client, http.RoundTripper и http.Handler
+
+// Package httpmw подключает генератор к net/http декларативно: запросы клиента (или входящие запросы
+// обработчика, например перед httputil.ReverseProxy) получают заголовки браузера без ручного кода
+//
+//	gen, _ := useragent.NewGenerator()
+//	mw := httpmw.New(gen, httpmw.WithStickySessions(), httpmw.WithExclude("api.example.com"))
+//	client := mw.Client(nil)
+//	resp, err := client.Get("https://shop.example.com/")
+package httpmw
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/imbecility/go-fake-useragent/useragent"
+)
+
+// DefaultMaxStickyHosts предел количества хостов WithStickySessions по умолчанию
+const DefaultMaxStickyHosts = 10000
+
+// Option настраивает Decorator
+type Option func(*Decorator)
+
+// Decorator подставляет заголовки браузера в HTTP-запросы. безопасен для конкурентного использования
+type Decorator struct {
+	p          useragent.Provider
+	identities *useragent.Identities // сессии по хостам WithStickySessions, nil - новый браузер на каждый запрос
+	sticky     bool                  // закреплять браузер за хостом (WithStickySessions)
+	maxHosts   int                   // предел количества хостов с закрепленным браузером (WithMaxStickyHosts)
+	exclude    []string              // хосты WithExclude: точные имена и суффиксы ".example.com"
+	override   bool                  // заменять заголовки, уже заданные в запросе (WithOverride)
+}
+
+// New создает Decorator для генератора p: вместо *useragent.Generator можно передать любой Provider,
+// например fakegen.Generator в тестах (тогда WithStickySessions не действует)
+func New(p useragent.Provider, opts ...Option) *Decorator {
+	d := &Decorator{p: p, maxHosts: DefaultMaxStickyHosts}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if g, ok := p.(*useragent.Generator); ok && d.sticky {
+		d.identities = g.NewIdentities(useragent.WithIdentityLimit(d.maxHosts))
+	}
+	return d
+}
+
+// WithStickySessions закрепляет за каждым хостом один браузер (сессию useragent.Session): все запросы
+// к хосту уходят с тем же User-Agent, экраном и железом, как у реального пользователя, а разные хосты
+// получают разные браузеры. без опции каждый запрос получает новый браузер, как GetHeaders.
+// хостов с закрепленным браузером не больше WithMaxStickyHosts (по умолчанию DefaultMaxStickyHosts):
+// сверх предела вытесняется хост, к которому дольше всего не было запросов, и при следующем запросе
+// он получит новый браузер
+func WithStickySessions() Option {
+	return func(d *Decorator) {
+		d.sticky = true
+	}
+}
+
+// WithMaxStickyHosts задает предел количества хостов WithStickySessions, n <= 0 - без предела
+// (память растет с каждым новым хостом, поэтому без предела стоит работать только с известным списком хостов)
+func WithMaxStickyHosts(n int) Option {
+	return func(d *Decorator) {
+		d.maxHosts = max(n, 0)
+	}
+}
+
+// WithExclude задает хосты, запросы к которым не изменяются (например, собственный API или сервис
+// с ключом доступа): "api.example.com" - только этот хост, ".example.com" или "*.example.com" -
+// домен и все его поддомены. регистр не учитывается, пустые значения пропускаются
+func WithExclude(hosts ...string) Option {
+	return func(d *Decorator) {
+		for _, host := range hosts {
+			host = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(host), "*"))
+			if host != "" && host != "." {
+				d.exclude = append(d.exclude, host)
+			}
+		}
+	}
+}
+
+// WithOverride заменяет заголовки, которые уже заданы в запросе: по умолчанию значения вызывающего кода
+// (например, собственный accept для API) сохраняются, а подставляются только недостающие
+func WithOverride() Option {
+	return func(d *Decorator) {
+		d.override = true
+	}
+}
+
+// Transport оборачивает base (nil - http.DefaultTransport): запросы уходят с заголовками браузера,
+// исходный запрос не изменяется
+func (d *Decorator) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripper{d: d, base: base}
+}
+
+// Client возвращает копию c (nil - пустой клиент) с транспортом, подставляющим заголовки браузера
+func (d *Decorator) Client(c *http.Client) *http.Client {
+	var client http.Client
+	if c != nil {
+		client = *c
+	}
+	client.Transport = d.Transport(client.Transport)
+	return &client
+}
+
+// Handler оборачивает next: входящий запрос получает заголовки браузера для своего адреса перед вызовом next,
+// например чтобы httputil.ReverseProxy отправлял их дальше. адрес берется из r.URL или из Host запроса
+func (d *Decorator) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, d.decorate(r))
+	})
+}
+
+// roundTripper транспорт Decorator.Transport
+type roundTripper struct {
+	d    *Decorator
+	base http.RoundTripper
+}
+
+// RoundTrip отправляет копию запроса с заголовками браузера
+func (rt roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt.base.RoundTrip(rt.d.decorate(req))
+}
+
+// Apply подставляет заголовки браузера прямо в r, без копии: для хуков HTTP-клиентов, которые передают
+// готовый *http.Request, например SetPreRequestHook в go-resty (см. README). запросы к исключенным
+// хостам не изменяются
+func (d *Decorator) Apply(r *http.Request) {
+	if headers := d.headersFor(r); headers != nil {
+		d.setHeaders(r.Header, headers)
+	}
+}
+
+// decorate возвращает копию запроса с заголовками браузера, исключенные хосты - без изменений
+func (d *Decorator) decorate(r *http.Request) *http.Request {
+	headers := d.headersFor(r)
+	if headers == nil {
+		return r
+	}
+	out := r.Clone(r.Context())
+	d.setHeaders(out.Header, headers)
+	return out
+}
+
+// headersFor генерирует заголовки браузера для адреса запроса, nil - хост исключен
+func (d *Decorator) headersFor(r *http.Request) map[string]string {
+	target := *r.URL
+	if target.Host == "" {
+		target.Host = r.Host
+	}
+	if target.Scheme == "" {
+		target.Scheme = "http"
+		if r.TLS != nil {
+			target.Scheme = "https"
+		}
+	}
+	host := strings.ToLower(target.Hostname())
+	if d.excluded(host) {
+		return nil
+	}
+	if d.identities != nil {
+		return d.identities.Get(host).GetHeaders(target.String())
+	}
+	return d.p.GetHeaders(target.String())
+}
+
+// setHeaders переносит заголовки в h, заданные вызывающим кодом значения сохраняются без WithOverride
+func (d *Decorator) setHeaders(h http.Header, headers map[string]string) {
+	for name, value := range headers {
+		if !d.override && h.Get(name) != "" {
+			continue
+		}
+		h.Set(name, value)
+	}
+}
+
+// excluded сообщает, что запросы к host не изменяются
+func (d *Decorator) excluded(host string) bool {
+	for _, pattern := range d.exclude {
+		if host == pattern {
+			return true
+		}
+		if domain, ok := strings.CutPrefix(pattern, "."); ok && (host == domain || strings.HasSuffix(host, pattern)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+// identity.go привязка личностей к ключам: один и тот же браузер для прокси или аккаунта до явной смены
+
+package useragent
+
+import (
+	"container/list"
+	"slices"
+	"sync"
+)
+
+// Identities реестр личностей (сессий), привязанных к произвольным ключам - идентификатору прокси,
+// аккаунта или воркера: для одного ключа возвращается одна и та же сессия с тем же User-Agent,
+// заголовками и устройством, пока личность не сменят Rotate или Remove.
+//
+// в отличие от PersonaFromSeed, личность выбирается случайно и хранится в памяти: она не зависит
+// от обновления версий, но не переживает перезапуск процесса. без WithIdentityLimit реестр не ограничен,
+// поэтому для ключей из внешнего мира (хостов, адресов клиентов) стоит задать предел.
+//
+// Identities безопасен для конкурентного использования.
+type Identities struct {
+	g     *Generator
+	limit int // предел количества личностей WithIdentityLimit, 0 - без предела
+
+	mu       sync.Mutex
+	sessions map[string]*list.Element // значения - *identity
+	order    *list.List               // от недавно использованных к давно неиспользуемым
+}
+
+// identity личность с ключом в списке давности использования
+type identity struct {
+	key     string
+	session *Session
+}
+
+// IdentitiesOption настраивает реестр NewIdentities
+type IdentitiesOption func(*Identities)
+
+// WithIdentityLimit ограничивает реестр n личностями: при привязке новой личности сверх предела
+// вытесняется та, к которой дольше всего не обращались (LRU), и следующий Get для ее ключа выберет новую.
+// n <= 0 - без предела
+func WithIdentityLimit(n int) IdentitiesOption {
+	return func(ids *Identities) {
+		ids.limit = max(n, 0)
+	}
+}
+
+// NewIdentities создает пустой реестр личностей
+func (g *Generator) NewIdentities(opts ...IdentitiesOption) *Identities {
+	ids := &Identities{g: g, sessions: make(map[string]*list.Element), order: list.New()}
+	for _, opt := range opts {
+		opt(ids)
+	}
+	return ids
+}
+
+// Get возвращает личность, привязанную к key, при первом обращении она выбирается по настройкам генератора
+func (ids *Identities) Get(key string) *Session {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	if elem, ok := ids.sessions[key]; ok {
+		ids.order.MoveToFront(elem)
+		return elem.Value.(*identity).session
+	}
+	s := ids.g.NewSession()
+	ids.bind(key, s)
+	return s
+}
+
+// Rotate заменяет личность key новой, например после блокировки прокси или аккаунта, и возвращает ее
+func (ids *Identities) Rotate(key string) *Session {
+	s := ids.g.NewSession()
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	if elem, ok := ids.sessions[key]; ok {
+		elem.Value.(*identity).session = s
+		ids.order.MoveToFront(elem)
+		return s
+	}
+	ids.bind(key, s)
+	return s
+}
+
+// bind привязывает к key новую личность и вытесняет давно неиспользуемые сверх предела, вызывается под mu
+func (ids *Identities) bind(key string, s *Session) {
+	ids.sessions[key] = ids.order.PushFront(&identity{key: key, session: s})
+	for ids.limit > 0 && ids.order.Len() > ids.limit {
+		oldest := ids.order.Back()
+		ids.order.Remove(oldest)
+		delete(ids.sessions, oldest.Value.(*identity).key)
+	}
+}
+
+// Remove отвязывает личность от key: следующий Get выберет новую
+func (ids *Identities) Remove(key string) {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	if elem, ok := ids.sessions[key]; ok {
+		ids.order.Remove(elem)
+		delete(ids.sessions, key)
+	}
+}
+
+// Keys возвращает отсортированные ключи, к которым привязаны личности
+func (ids *Identities) Keys() []string {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	keys := make([]string, 0, len(ids.sessions))
+	for key := range ids.sessions {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Len возвращает количество привязанных личностей
+func (ids *Identities) Len() int {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	return len(ids.sessions)
+}
@@ -0,0 +1,150 @@
+// inventory.go выгрузка всех User-Agent, которые генератор может выдать в текущий момент
+
+package useragent
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// InventoryFormat формат выгрузки ExportInventory
+type InventoryFormat string
+
+const (
+	InventoryJSON         InventoryFormat = "json"          // JSON-массив записей без заголовков
+	InventoryCSV          InventoryFormat = "csv"           // CSV с колонками browser,platform,version,user_agent
+	InventoryProfilesJSON InventoryFormat = "profiles-json" // JSON-массив записей с примером полного набора заголовков
+)
+
+// InventoryEntry одна строка User-Agent из инвентаря генератора
+type InventoryEntry struct {
+	Browser   string            `json:"browser"`           // "Google Chrome", "Microsoft Edge", "Firefox", "Safari"
+	Platform  string            `json:"platform"`          // значение sec-ch-ua-platform без кавычек
+	Version   string            `json:"version"`           // версия браузера из User-Agent
+	UserAgent string            `json:"user_agent"`        // строка User-Agent
+	Headers   map[string]string `json:"headers,omitempty"` // пример заголовков GetHeaders (только InventoryProfilesJSON)
+}
+
+// ExportInventory выгружает в w все строки User-Agent, которые генератор может выдать прямо сейчас
+// с учетом текущих версий и включенных браузеров: для WAF-allowlist, тестовых матриц и конвейеров данных.
+//
+// для InventoryProfilesJSON к каждой строке добавляется пример полного набора заголовков;
+// значения, выбираемые случайно (разрешение экрана, вьюпорт и т.д.), в нём - лишь одна из возможных реализаций.
+func (g *Generator) ExportInventory(w io.Writer, format InventoryFormat) error {
+	entries := g.inventory()
+
+	switch format {
+	case InventoryJSON, InventoryProfilesJSON:
+		if format == InventoryProfilesJSON {
+			for i := range entries {
+				entries[i].Headers = g.headersForUA(entries[i].UserAgent)
+			}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	case InventoryCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"browser", "platform", "version", "user_agent"}); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := cw.Write([]string{e.Browser, e.Platform, e.Version, e.UserAgent}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return fmt.Errorf("неизвестный формат выгрузки %q", format)
+	}
+}
+
+// inventory перечисляет все варианты, которые может вернуть Get
+func (g *Generator) inventory() []InventoryEntry {
+	g.mu.RLock()
+	versions := slices.Clone(g.versions)
+	firefoxVersions := slices.Clone(g.firefoxVersions)
+	edgeVersions := slices.Clone(g.edgeVersions)
+	uaList := slices.Clone(g.uaList)
+	g.mu.RUnlock()
+
+	if len(versions) == 0 {
+		versions = []string{approximateVersionForDate(time.Now())}
+	}
+
+	var entries []InventoryEntry
+	seen := make(map[string]bool)
+	add := func(ua, version string) {
+		if seen[ua] {
+			return
+		}
+		seen[ua] = true
+		info := parseUserAgent(ua)
+		entries = append(entries, InventoryEntry{Browser: info.BrandName, Platform: info.Platform, Version: version, UserAgent: ua})
+	}
+
+	chrome := g.browserWeight(BrowserChrome) > 0
+	edge := g.browserWeight(BrowserEdge) > 0
+	platforms := g.platforms
+	if len(platforms) == 0 {
+		platforms = platformProfiles[:1]
+	}
+	for _, v := range versions {
+		v = g.uaVersion(v) // сокращенные версии одной мажорной версии дают одну строку
+		for _, p := range platforms {
+			if (chrome && g.mobileProbability < 1) || (edge && !p.edge) {
+				add(p.chromeUA(v), v)
+			}
+			if edge && p.edge && len(edgeVersions) == 0 {
+				add(p.edgeUA(v, v), v)
+			}
+		}
+		if chrome && g.mobileProbability > 0 {
+			add(mobileUA(v), v)
+		}
+	}
+	// сборки Edge в паре с каждой версией Chromium той же мажорной версии (см. randomEdgeVersions)
+	for _, e := range edgeVersions {
+		major, _, _ := strings.Cut(e, ".")
+		chromium := slices.DeleteFunc(slices.Clone(versions), func(v string) bool { return !strings.HasPrefix(v, major+".") })
+		if len(chromium) == 0 {
+			chromium = []string{e}
+		}
+		for _, c := range chromium {
+			for _, p := range platforms {
+				if edge && p.edge {
+					add(p.edgeUA(g.uaVersion(c), g.uaVersion(e)), g.uaVersion(e))
+				}
+			}
+		}
+	}
+	if g.firefoxEnabled() {
+		if len(firefoxVersions) == 0 {
+			firefoxVersions = []string{approximateFirefoxVersionForDate(time.Now())}
+		}
+		for _, v := range firefoxVersions {
+			for _, p := range platforms {
+				for _, ua := range p.firefoxUAVariants(v) {
+					add(ua, v)
+				}
+			}
+		}
+	}
+	if g.safariEnabled() {
+		for _, v := range safariVersionsAt(time.Now()) {
+			add(fmt.Sprintf(safariUATemplate, v), v)
+		}
+	}
+	if g.uaListWeight > 0 {
+		for _, ua := range uaList {
+			add(ua, parseUserAgent(ua).FullVersion)
+		}
+	}
+	return entries
+}
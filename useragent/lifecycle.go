@@ -0,0 +1,33 @@
+// lifecycle.go завершение работы генератора: остановка фоновых горутин и освобождение ресурсов
+
+package useragent
+
+// Close останавливает фоновое обновление версий, дожидается завершения уже начатого обновления
+// (вместе с записью дискового кэша) и закрывает каналы подписчиков Subscribe.
+//
+// после Close генератор продолжает выдавать User-Agent и заголовки из текущего набора версий,
+// но больше не обновляет его. повторные вызовы безопасны и ничего не делают.
+// генераторы, созданные в тестах или на короткое время, следует закрывать,
+// чтобы не оставлять работающих горутин.
+func (g *Generator) Close() error {
+	g.closeOnce.Do(func() {
+		close(g.done)
+		g.background.Wait()
+
+		g.subsMu.Lock()
+		for _, sub := range g.subscribers {
+			close(sub)
+		}
+		g.subscribers = nil
+		g.subsMu.Unlock()
+	})
+	return nil
+}
+
+// abort останавливает фоновые горутины генератора, который NewGenerator не вернул из-за ошибки:
+// запросы, продолжающиеся после WithInitDeadline, завершатся сами, поэтому их завершения не ждет
+func (g *Generator) abort() {
+	g.closeOnce.Do(func() {
+		close(g.done)
+	})
+}
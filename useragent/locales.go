@@ -0,0 +1,174 @@
+// locales.go языки браузера и вычисление заголовка accept-language
+
+package useragent
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// defaultLocales языки браузера, если WithLocales не задан
+var defaultLocales = []string{"ru-RU", "en-US"}
+
+// typicalSecondaryLanguages языки, которые обычно стоят в настройках браузера после основного (по базовому языку):
+// например, в браузерах на украинском языке часто добавлены русский и английский
+var typicalSecondaryLanguages = map[string][]string{
+	"ru": {"en-US"}, "uk": {"ru", "en-US"}, "be": {"ru", "en-US"}, "kk": {"ru", "en-US"},
+	"de": {"en-US"}, "fr": {"en-US"}, "es": {"en-US"}, "it": {"en-US"}, "pt": {"en-US"}, "pl": {"en-US"},
+	"nl": {"en-US"}, "tr": {"en-US"}, "cs": {"en-US"}, "sv": {"en-US"}, "ja": {"en-US"}, "ko": {"en-US"},
+	"ca": {"es-ES", "en-US"}, "eu": {"es-ES", "en-US"}, "en": {"en-US"},
+}
+
+// WithLocales задает языки браузера в порядке предпочтения, например WithLocales("de-DE", "en-US"):
+// из них строится accept-language с весами q, как у соответствующего браузера
+// (de-DE,de;q=0.9,en-US;q=0.8,en;q=0.7 для Chrome). после каждого регионального языка
+// добавляется базовый язык, если его нет в списке.
+// если указан один язык, к нему добавляются типичные для него дополнительные языки
+// (WithLocales("uk-UA") - украинский, русский и английский), как в настройках реальных браузеров.
+// некорректные теги игнорируются, по умолчанию используются ru-RU и en-US.
+func WithLocales(locales ...string) Option {
+	return func(g *Generator) {
+		if valid := validLocales(locales); len(valid) > 0 {
+			g.locales = valid
+		}
+	}
+}
+
+// AcceptLanguage возвращает accept-language, который браузер из строки userAgent отправляет с языками locales
+// (по тем же правилам, что WithLocales): позволяет сменить язык отдельного запроса, не меняя генератор
+//
+//	headers["accept-language"] = useragent.AcceptLanguage(headers["user-agent"], "de-DE")
+//
+// при пустом списке или только некорректных тегах возвращает пустую строку
+func AcceptLanguage(userAgent string, locales ...string) string {
+	valid := validLocales(locales)
+	if len(valid) == 0 {
+		return ""
+	}
+	return acceptLanguage(parseUserAgent(userAgent).BrandName, valid)
+}
+
+// validLocales нормализует языковые теги, отбрасывая некорректные и повторы,
+// и дополняет единственный язык типичными для него дополнительными
+func validLocales(locales []string) []string {
+	var valid []string
+	for _, l := range locales {
+		if tag, ok := normalizeLocale(l); ok && !containsFold(valid, tag) {
+			valid = append(valid, tag)
+		}
+	}
+	if len(valid) == 1 {
+		base, _, _ := strings.Cut(valid[0], "-")
+		for _, secondary := range typicalSecondaryLanguages[base] {
+			if !containsFold(valid, secondary) {
+				valid = append(valid, secondary)
+			}
+		}
+	}
+	return valid
+}
+
+// normalizeLocale проверяет языковой тег BCP 47 и приводит его к виду ll-RR ("ru_ru" -> "ru-RU")
+func normalizeLocale(locale string) (string, bool) {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	if len(parts[0]) < 2 || len(parts[0]) > 3 || !isAlpha(parts[0]) {
+		return "", false
+	}
+	parts[0] = strings.ToLower(parts[0])
+	for i, p := range parts[1:] {
+		if p == "" || len(p) > 8 || !isAlnum(p) {
+			return "", false
+		}
+		switch {
+		case len(p) == 2 && isAlpha(p):
+			parts[i+1] = strings.ToUpper(p) // регион
+		case len(p) == 4 && isAlpha(p):
+			parts[i+1] = strings.ToUpper(p[:1]) + strings.ToLower(p[1:]) // письменность
+		}
+	}
+	return strings.Join(parts, "-"), true
+}
+
+// expandLocales дополняет список базовыми языками: ru-RU, en-US -> ru-RU, ru, en-US, en
+func expandLocales(locales []string) []string {
+	expanded := make([]string, 0, len(locales)*2)
+	for i, l := range locales {
+		expanded = append(expanded, l)
+		base, _, regional := strings.Cut(l, "-")
+		if regional && !containsFold(locales, base) && !containsFold(expanded, base) {
+			// базовый язык ставится после последнего регионального варианта подряд (en-US, en-GB, en)
+			if i+1 < len(locales) && strings.HasPrefix(locales[i+1], base+"-") {
+				continue
+			}
+			expanded = append(expanded, base)
+		}
+	}
+	return expanded
+}
+
+// acceptLanguage строит заголовок accept-language для семейства браузера
+func acceptLanguage(brand string, locales []string) string {
+	if len(locales) == 0 {
+		locales = defaultLocales
+	}
+	languages := expandLocales(locales)
+
+	var sb strings.Builder
+	for i, l := range languages {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(l)
+		if i == 0 {
+			continue
+		}
+		if brand == "Firefox" {
+			sb.WriteString(firefoxQuality(i, len(languages)))
+		} else {
+			// Chromium и WebKit уменьшают вес на 0.1, не опускаясь ниже 0.1
+			fmt.Fprintf(&sb, ";q=%.1f", max(1-float64(i)/10, 0.1))
+		}
+	}
+	return sb.String()
+}
+
+// firefoxQuality вычисляет вес i-го языка из n как Firefox: равномерно от 1 до 1/n,
+// с одним знаком после запятой, а для длинных списков - с двумя
+func firefoxQuality(i, n int) string {
+	q := float64(n-i) / float64(n)
+	if n > 10 {
+		return fmt.Sprintf(";q=%.2f", math.Round(q*100)/100)
+	}
+	return fmt.Sprintf(";q=%.1f", math.Round(q*10)/10)
+}
+
+// containsFold сообщает, есть ли в списке строка без учета регистра
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAlpha сообщает, состоит ли строка только из латинских букв
+func isAlpha(s string) bool {
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlnum сообщает, состоит ли строка только из латинских букв и цифр
+func isAlnum(s string) bool {
+	for _, c := range s {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+// logging.go стабильные имена событий для машиночитаемых логов
+
+package useragent
+
+import (
+	"io"
+	"log/slog"
+)
+
+// имена событий в атрибуте "event": не зависят от текста сообщения и не меняются между версиями,
+// поэтому по ним можно строить алерты (например, на event=fallback_approximation)
+const (
+	eventCacheReadFailed     = "cache_read_failed"
+	eventCacheParseFailed    = "cache_parse_failed"
+	eventCacheBadSignature   = "cache_bad_signature"
+	eventCacheExpired        = "cache_expired"
+	eventCacheEmpty          = "cache_empty"
+	eventCacheLoaded         = "cache_loaded"
+	eventCacheSaved          = "cache_saved"
+	eventCacheSaveSkipped    = "cache_save_skipped"
+	eventCacheSaveFailed     = "cache_save_failed"
+	eventCacheLockWait       = "cache_lock_wait"
+	eventCacheLockStale      = "cache_lock_stale"
+	eventCacheLockFailed     = "cache_lock_failed"
+	eventSourceFetchStarted  = "source_fetch_started"
+	eventSourceFetchOK       = "source_fetch_ok"
+	eventSourceFetchFailed   = "source_fetch_failed"
+	eventSourceCanceled      = "source_canceled"
+	eventSourceMirrorFailed  = "source_mirror_failed"
+	eventSourceParseSkipped  = "source_parse_skipped"
+	eventSourceBody          = "source_body"
+	eventSourceNotModified   = "source_not_modified"
+	eventVersionsUpdated     = "versions_updated"
+	eventFallback            = "fallback_approximation"
+	eventCheckNoEcho         = "check_no_echo"
+	eventScheduleInvalid     = "schedule_invalid"
+	eventScheduleNext        = "schedule_next"
+	eventRefreshFailed       = "refresh_failed"
+	eventInitLateFailed      = "init_late_failed"
+	eventCrawlerRangesFailed = "crawler_ranges_failed"
+	eventManifestLoaded      = "manifest_loaded"
+	eventManifestFailed      = "manifest_failed"
+	eventRegistryLoaded      = "registry_loaded"
+	eventRegistryFailed      = "registry_failed"
+	eventVersionsImported    = "versions_imported"
+	eventUAListLoaded        = "ua_list_loaded"
+	eventUAListRejected      = "ua_list_rejected"
+	eventUAListFailed        = "ua_list_failed"
+	eventProxyIgnored        = "proxy_ignored"
+	eventVersionsPinned      = "versions_pinned"
+	eventPoolReshuffled      = "pool_reshuffled"
+	eventSnapshotLoaded      = "snapshot_loaded"
+	eventSnapshotExpired     = "snapshot_expired"
+	eventSnapshotInvalid     = "snapshot_invalid"
+	eventDataCacheRejected   = "data_cache_rejected"
+	eventDataCacheFailed     = "data_cache_save_failed"
+)
+
+// WithJSONLogs направляет логи генератора в w в формате JSON (slog.JSONHandler, уровень DEBUG):
+// каждая запись содержит стабильное имя события "event" и поля source, duration, cache_path, fallback,
+// что позволяет обрабатывать логи в пайплайнах, не разбирая текст сообщений
+func WithJSONLogs(w io.Writer) Option {
+	return WithLogger(slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})))
+}
@@ -0,0 +1,257 @@
+// manifest.go загрузка данных для реалистичности заголовков (разрешения, вьюпорты, веса) из JSON-манифеста
+
+package useragent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// шаблон имени файла кэша удаленного манифеста (с хэшем адреса)
+	manifestCacheFileTemplate = "go_ua_manifest_%x.json"
+
+	// maxManifestSize ограничивает размер загружаемого манифеста
+	maxManifestSize = 1 << 20
+)
+
+// realismData таблицы, из которых выбираются значения заголовков
+type realismData struct {
+	Resolutions                []screenResolution `json:"resolutions"`
+	ViewportHeightSubtractions []int              `json:"viewport_height_subtractions"`
+	ViewportWidthSubtractions  []int              `json:"viewport_width_subtractions"`
+	DeviceMemories             []string           `json:"device_memories"`
+	DPRs                       []string           `json:"dprs"`
+	RTTs                       []string           `json:"rtts"`
+	Downlinks                  []string           `json:"downlinks"`
+}
+
+// defaultRealismData возвращает встроенные в пакет таблицы
+func defaultRealismData() *realismData {
+	return &realismData{
+		Resolutions:                commonResolutions,
+		ViewportHeightSubtractions: viewportHeightSubtractions,
+		ViewportWidthSubtractions:  viewportWidthSubtractions,
+		DeviceMemories:             deviceMemories,
+		DPRs:                       dprs,
+		RTTs:                       rtts,
+		Downlinks:                  downlinks,
+	}
+}
+
+// merge заменяет таблицы значениями из манифеста: пустые поля манифеста не меняют встроенные данные
+func (d *realismData) merge(m *realismData) {
+	if len(m.Resolutions) > 0 {
+		d.Resolutions = m.Resolutions
+	}
+	if len(m.ViewportHeightSubtractions) > 0 {
+		d.ViewportHeightSubtractions = m.ViewportHeightSubtractions
+	}
+	if len(m.ViewportWidthSubtractions) > 0 {
+		d.ViewportWidthSubtractions = m.ViewportWidthSubtractions
+	}
+	if len(m.DeviceMemories) > 0 {
+		d.DeviceMemories = m.DeviceMemories
+	}
+	if len(m.DPRs) > 0 {
+		d.DPRs = m.DPRs
+	}
+	if len(m.RTTs) > 0 {
+		d.RTTs = m.RTTs
+	}
+	if len(m.Downlinks) > 0 {
+		d.Downlinks = m.Downlinks
+	}
+}
+
+// validate проверяет, что значения манифеста пригодны для генерации заголовков
+func (d *realismData) validate() error {
+	for _, r := range d.Resolutions {
+		if r.Width <= 0 || r.Height <= 0 || r.Weight < 0 {
+			return fmt.Errorf("неверное разрешение в манифесте: %dx%d (вес %v)", r.Width, r.Height, r.Weight)
+		}
+		switch r.Class {
+		case "", DeviceDesktop, DeviceLaptop, DeviceMobile, DeviceTablet:
+		default:
+			return fmt.Errorf("неизвестный класс устройства в манифесте: %q", r.Class)
+		}
+	}
+	return nil
+}
+
+// realism возвращает таблицы генератора, а при их отсутствии - встроенные
+func (g *Generator) realism() *realismData {
+	if g.data == nil {
+		return defaultRealismData()
+	}
+	return g.data
+}
+
+// resolutionShares возвращает ожидаемые доли разрешений, которые выбирает pickResolution для тех же классов
+func (d *realismData) resolutionShares(classes ...DeviceClass) map[string]float64 {
+	candidates := make([]screenResolution, 0, len(d.Resolutions))
+	for _, r := range d.Resolutions {
+		class := r.Class
+		if class == "" {
+			class = DeviceDesktop
+		}
+		if len(classes) == 0 || slices.Contains(classes, class) {
+			candidates = append(candidates, r)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = d.Resolutions
+	}
+
+	var total float64
+	for _, r := range candidates {
+		total += r.Weight
+	}
+	shares := make(map[string]float64, len(candidates))
+	for _, r := range candidates {
+		share := 1 / float64(len(candidates))
+		if total > 0 {
+			share = r.Weight / total
+		}
+		shares[r.key()] += share
+	}
+	return shares
+}
+
+// key возвращает разрешение в виде "1920x1080"
+func (r screenResolution) key() string {
+	return fmt.Sprintf("%dx%d", r.Width, r.Height)
+}
+
+// pickResolution выбирает разрешение одного из указанных классов устройств с учетом весов
+// (при отсутствии весов - равновероятно). разрешения без класса считаются десктопными,
+// если разрешений нужных классов нет, выбор идет из всей таблицы
+func (d *realismData) pickResolution(r *rand.Rand, classes ...DeviceClass) screenResolution {
+	candidates := make([]screenResolution, 0, len(d.Resolutions))
+	for _, r := range d.Resolutions {
+		class := r.Class
+		if class == "" {
+			class = DeviceDesktop
+		}
+		if len(classes) == 0 || slices.Contains(classes, class) {
+			candidates = append(candidates, r)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = d.Resolutions
+	}
+
+	var total float64
+	for _, r := range candidates {
+		total += r.Weight
+	}
+	if total <= 0 {
+		return candidates[r.IntN(len(candidates))]
+	}
+	x := r.Float64() * total
+	for _, r := range candidates {
+		if x < r.Weight {
+			return r
+		}
+		x -= r.Weight
+	}
+	return candidates[len(candidates)-1]
+}
+
+// WithDataManifest загружает таблицы разрешений экрана, вычитаемых из вьюпорта значений и
+// весов распределений из JSON-манифеста, чтобы обновлять данные для реалистичности без новых релизов пакета.
+//
+// location - путь к локальному файлу или адрес http(s)://. удаленный манифест кэшируется
+// в личном каталоге кэша пользователя на время ttl. формат манифеста:
+//
+//	{
+//	  "resolutions": [{"width": 1920, "height": 1080, "weight": 24, "class": "desktop", "dpr": "1.0"}, ...],
+//	  "viewport_height_subtractions": [90, 128],
+//	  "viewport_width_subtractions": [2, 64],
+//	  "device_memories": ["8", "16"], "dprs": ["1.0"], "rtts": ["50"], "downlinks": ["10.0"]
+//	}
+//
+// отсутствующие поля остаются встроенными значениями, при ошибке загрузки используются встроенные данные.
+func WithDataManifest(location string, ttl time.Duration) Option {
+	return func(g *Generator) {
+		g.manifestLocation = location
+		g.manifestTTL = ttl
+	}
+}
+
+// loadDataManifest загружает манифест, указанный в WithDataManifest, поверх встроенных данных
+func (g *Generator) loadDataManifest() {
+	if g.manifestLocation == "" {
+		return
+	}
+	data, err := g.readDataManifest()
+	if err != nil {
+		g.logger.Warn("не удалось загрузить манифест данных, используются встроенные значения",
+			"event", eventManifestFailed, "location", g.manifestLocation, "error", err)
+		return
+	}
+
+	var manifest realismData
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		g.logger.Warn("не удалось распарсить манифест данных, используются встроенные значения",
+			"event", eventManifestFailed, "location", g.manifestLocation, "error", err)
+		return
+	}
+	if err := manifest.validate(); err != nil {
+		g.logger.Warn("манифест данных отклонен, используются встроенные значения",
+			"event", eventManifestFailed, "location", g.manifestLocation, "error", err)
+		return
+	}
+
+	d := defaultRealismData()
+	d.merge(&manifest)
+	g.data = d
+	g.logger.Debug("загружен манифест данных", "event", eventManifestLoaded, "location", g.manifestLocation)
+}
+
+// readDataManifest читает манифест из файла, из кэша или по сети
+func (g *Generator) readDataManifest() ([]byte, error) {
+	return g.readDataFile(g.manifestLocation, g.manifestTTL, manifestCacheFileTemplate)
+}
+
+// readDataFile читает небольшой файл данных (манифест, реестр источников) по пути или адресу http(s)://:
+// удаленный файл кэшируется в личном каталоге кэша пользователя на время ttl под именем по шаблону cacheTemplate
+// (см. dataCachePath), с WithCacheSigningKey кэш подписывается тем же ключом, что и кэш версий
+func (g *Generator) readDataFile(location string, ttl time.Duration, cacheTemplate string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return readLimited(location, maxManifestSize)
+	}
+
+	cachePath := dataCachePath(location, cacheTemplate)
+	if cachePath != "" {
+		if data, ok := g.readDataCache(cachePath, location, ttl); ok {
+			return data, nil
+		}
+	}
+	if g.offline {
+		return nil, fmt.Errorf("офлайн-режим: удаленный файл %s недоступен", location)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.httpClient.Timeout)
+	defer cancel()
+	var data []byte
+	err := g.executeGet(ctx, location, func(r io.Reader) error {
+		var readErr error
+		data, readErr = io.ReadAll(io.LimitReader(r, maxManifestSize))
+		return readErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if cachePath != "" {
+		g.writeDataCache(cachePath, location, data)
+	}
+	return data, nil
+}
@@ -0,0 +1,94 @@
+// mobile.go генерация User-Agent Chrome для Android и согласованных с ним мобильных подсказок
+
+package useragent
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// шаблон User-Agent Chrome для Android: после сокращения User-Agent (UA reduction) Chrome
+// всегда отправляет "Android 10; K", реальные модель и версия Android доступны только через
+// sec-ch-ua-model и sec-ch-ua-platform-version
+const androidChromeUATemplate = "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s Mobile Safari/537.36"
+
+// высота системных панелей Android и интерфейса Chrome в CSS-пикселях
+const (
+	androidStatusBarHeight  = 24 // строка состояния
+	androidNavBarHeight     = 48 // панель навигации (кнопки или жесты)
+	androidChromeTopBarSize = 56 // панель адреса Chrome
+)
+
+// androidDevice популярное Android-устройство: модель, версия ОС и экран в CSS-пикселях
+type androidDevice struct {
+	Model           string
+	PlatformVersion string
+	Width           int
+	Height          int
+	DeviceMemory    string
+}
+
+// androidDevices распространенные смартфоны, размеры экранов совпадают с мобильными разрешениями commonResolutions
+var androidDevices = []androidDevice{
+	{Model: "SM-S921B", PlatformVersion: "14.0.0", Width: 360, Height: 780, DeviceMemory: "8"}, // Galaxy S24
+	{Model: "SM-S911B", PlatformVersion: "14.0.0", Width: 360, Height: 780, DeviceMemory: "8"}, // Galaxy S23
+	{Model: "SM-A546B", PlatformVersion: "14.0.0", Width: 384, Height: 854, DeviceMemory: "8"}, // Galaxy A54
+	{Model: "SM-A155F", PlatformVersion: "14.0.0", Width: 384, Height: 832, DeviceMemory: "4"}, // Galaxy A15
+	{Model: "Pixel 8", PlatformVersion: "15.0.0", Width: 412, Height: 915, DeviceMemory: "8"},
+	{Model: "Pixel 7a", PlatformVersion: "14.0.0", Width: 412, Height: 915, DeviceMemory: "8"},   //
+	{Model: "23129RAA4G", PlatformVersion: "14.0.0", Width: 393, Height: 873, DeviceMemory: "8"}, // Redmi Note 13
+	{Model: "2201117TG", PlatformVersion: "13.0.0", Width: 393, Height: 873, DeviceMemory: "4"},  // Redmi Note 11
+}
+
+// WithMobileProbability включает генерацию User-Agent Chrome для Android с вероятностью p (от 0 до 1).
+// для мобильных User-Agent GetHeaders отправляет sec-ch-ua-mobile: ?1, модель устройства,
+// версию Android и размеры вьюпорта смартфона. по умолчанию генерируются только десктопные User-Agent.
+func WithMobileProbability(p float64) Option {
+	return func(g *Generator) {
+		if math.IsNaN(p) {
+			return
+		}
+		g.mobileProbability = min(max(p, 0), 1)
+	}
+}
+
+// androidHints выбирает мобильное разрешение с учетом весов, подходящую к нему модель и вычисляет подсказки
+// для мобильного Chrome: вьюпорт - экран без строки состояния, панели навигации и панели адреса Chrome
+func (d *realismData) androidHints(r *rand.Rand) deviceHints {
+	resolution := d.pickResolution(r, DeviceMobile)
+	dpr := resolution.DPR
+	if dpr == "" {
+		dpr = "2.625"
+	}
+
+	var matching []androidDevice
+	for _, device := range androidDevices {
+		if device.Width == resolution.Width && device.Height == resolution.Height {
+			matching = append(matching, device)
+		}
+	}
+	if len(matching) == 0 {
+		// разрешение из манифеста без известной модели: модель любая, экран - из манифеста
+		matching = androidDevices
+	}
+	device := matching[r.IntN(len(matching))]
+
+	return deviceHints{
+		Mobile:          true,
+		Model:           device.Model,
+		PlatformVersion: device.PlatformVersion,
+		Arch:            "",
+		Bitness:         "",
+		DeviceMemory:    device.DeviceMemory,
+		DPR:             dpr,
+		ViewportWidth:   resolution.Width,
+		ViewportHeight:  resolution.Height - androidStatusBarHeight - androidNavBarHeight - androidChromeTopBarSize,
+		screen:          resolution,
+	}
+}
+
+// mobileUA возвращает User-Agent Chrome для Android с указанной версией
+func mobileUA(version string) string {
+	return fmt.Sprintf(androidChromeUATemplate, version)
+}
@@ -0,0 +1,112 @@
+// netinfo.go подсказки о качестве сети (ect, rtt, downlink): согласованные между собой значения Network Information API
+
+package useragent
+
+import (
+	"math"
+	"math/rand/v2"
+	"strconv"
+)
+
+// connectionType эффективный тип соединения (ect) с относительными частотами на десктопе и смартфоне
+// и диапазонами rtt (мс) и downlink (Мбит/с), при которых Chrome присваивает соединению этот тип.
+// нулевые диапазоны у 4g: значения берутся из таблиц rtts и downlinks (их может заменить манифест данных)
+type connectionType struct {
+	ECT          string
+	Weight       float64
+	MobileWeight float64
+	RTTMin       int
+	RTTMax       int
+	DownlinkMin  float64
+	DownlinkMax  float64
+}
+
+// connectionTypes типы соединений: Chrome относит соединение к 3g при rtt от 270 мс или downlink до 0.7 Мбит/с,
+// к 2g - при rtt от 1400 мс; на десктопе медленные соединения почти не встречаются
+var connectionTypes = []connectionType{
+	{ECT: "4g", Weight: 96, MobileWeight: 85},
+	{ECT: "3g", Weight: 3.5, MobileWeight: 13, RTTMin: 300, RTTMax: 1200, DownlinkMin: 0.2, DownlinkMax: 1.5},
+	{ECT: "2g", Weight: 0.5, MobileWeight: 2, RTTMin: 1400, RTTMax: 2000, DownlinkMin: 0.05, DownlinkMax: 0.25},
+}
+
+// гранулярность и предел значений: Chrome округляет rtt до 50 мс и downlink до 50 кбит/с
+// и не сообщает downlink больше 10 Мбит/с, чтобы подсказки не служили для отслеживания
+const (
+	rttGranularity = 50 // мс
+	downlinkSteps  = 20 // шагов по 50 кбит/с в 1 Мбит/с
+	maxDownlink    = 10 // Мбит/с
+)
+
+// WithNetworkHints включает или отключает подсказки о качестве сети downlink, ect и rtt в заголовках Chrome и Edge.
+// по умолчанию подсказки отправляются, а их значения согласованы между собой: сначала выбирается тип соединения
+// (почти всегда 4g, на смартфонах чаще 3g), затем rtt и downlink, при которых Chrome сообщил бы этот тип.
+// отключение полезно, если сайт не запрашивает эти подсказки через Accept-CH
+func WithNetworkHints(enabled bool) Option {
+	return func(g *Generator) {
+		g.noNetworkHints = !enabled
+	}
+}
+
+// networkHints выбирает тип соединения и согласованные с ним rtt и downlink в виде значений заголовков
+func (d *realismData) networkHints(r *rand.Rand, mobile bool) (ect, rtt, downlink string) {
+	ct := pickConnectionType(r, mobile)
+	if ct.RTTMax == 0 {
+		rtt = d.RTTs[r.IntN(len(d.RTTs))]
+		downlink = d.Downlinks[r.IntN(len(d.Downlinks))]
+		return ct.ECT, normalizeRTT(rtt), normalizeDownlink(downlink)
+	}
+	rttValue := ct.RTTMin + r.IntN(ct.RTTMax-ct.RTTMin+1)
+	downlinkValue := ct.DownlinkMin + r.Float64()*(ct.DownlinkMax-ct.DownlinkMin)
+	return ct.ECT, formatRTT(rttValue), formatDownlink(downlinkValue)
+}
+
+// pickConnectionType выбирает тип соединения с учетом весов для десктопа или смартфона
+func pickConnectionType(r *rand.Rand, mobile bool) connectionType {
+	weight := func(ct connectionType) float64 {
+		if mobile {
+			return ct.MobileWeight
+		}
+		return ct.Weight
+	}
+	var total float64
+	for _, ct := range connectionTypes {
+		total += weight(ct)
+	}
+	x := r.Float64() * total
+	for _, ct := range connectionTypes {
+		if x < weight(ct) {
+			return ct
+		}
+		x -= weight(ct)
+	}
+	return connectionTypes[0]
+}
+
+// formatRTT округляет rtt так же, как Chrome
+func formatRTT(ms int) string {
+	return strconv.Itoa(int(math.Round(float64(ms)/rttGranularity)) * rttGranularity)
+}
+
+// formatDownlink округляет и ограничивает downlink так же, как Chrome: "10", "1.45", а не "10.0"
+func formatDownlink(mbps float64) string {
+	mbps = min(math.Round(mbps*downlinkSteps)/downlinkSteps, maxDownlink)
+	return strconv.FormatFloat(mbps, 'f', -1, 64)
+}
+
+// normalizeRTT приводит значение rtt из таблицы к виду, который отправляет Chrome, нечисловое оставляет как есть
+func normalizeRTT(value string) string {
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms < 0 {
+		return value
+	}
+	return formatRTT(ms)
+}
+
+// normalizeDownlink приводит значение downlink из таблицы к виду, который отправляет Chrome, нечисловое оставляет как есть
+func normalizeDownlink(value string) string {
+	mbps, err := strconv.ParseFloat(value, 64)
+	if err != nil || mbps < 0 || math.IsInf(mbps, 0) {
+		return value
+	}
+	return formatDownlink(mbps)
+}
@@ -0,0 +1,84 @@
+//go:build !nonet
+
+// network.go сетевые запросы генератора (исключаются из сборки с тегом nonet, см. network_nonet.go)
+
+package useragent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// networkEnabled сетевые источники доступны в этой сборке
+const networkEnabled = true
+
+// versionsSnapshotJSON встроенный снимок версий: сборке с сетью он не нужен
+var versionsSnapshotJSON []byte
+
+// executeGet выполняет HTTP GET запрос и безопасно управляет закрытием тела ответа.
+func (g *Generator) executeGet(ctx context.Context, url string, process func(io.Reader) error) error {
+	_, err := g.executeConditionalGet(ctx, url, sourceValidator{}, process)
+	return err
+}
+
+// executeConditionalGet выполняет GET запрос с If-None-Match / If-Modified-Since из known:
+// при ответе 304 тело не читается и возвращается errNotModified, при успехе - валидаторы нового ответа
+func (g *Generator) executeConditionalGet(
+	ctx context.Context, url string, known sourceValidator, process func(io.Reader) error,
+) (validator sourceValidator, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return validator, fmt.Errorf("не удалось создать запрос: %w", err)
+	}
+	// при явном Accept-Encoding транспорт не распаковывает ответ сам, поэтому распаковка ниже
+	// не зависит от настроек пользовательского http.Client
+	req.Header.Set("Accept-Encoding", g.acceptEncoding())
+	if known.ETag != "" {
+		req.Header.Set("If-None-Match", known.ETag)
+	}
+	if known.LastModified != "" {
+		req.Header.Set("If-Modified-Since", known.LastModified)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return validator, fmt.Errorf("HTTP запрос не удался: %w", err)
+	}
+	defer func() {
+		// закрытие с пробросом ошибки
+		err = errors.Join(err, resp.Body.Close())
+	}()
+
+	if resp.StatusCode == http.StatusNotModified && known.conditional() {
+		return known, errNotModified
+	}
+	if resp.StatusCode != http.StatusOK {
+		return validator, fmt.Errorf("неверный HTTP статус: %s", resp.Status)
+	}
+
+	body, err := decodeBody(resp.Body, resp.Header.Get("Content-Encoding"))
+	if err != nil {
+		return validator, err
+	}
+	defer body.Close()
+
+	// ограничение применяется к распакованному телу: сжатый ответ не может развернуться без предела,
+	// а обрезанный ответ не должен разбираться как целый
+	data, err := io.ReadAll(io.LimitReader(body, maxSourceResponseSize+1))
+	if err != nil {
+		return validator, fmt.Errorf("не удалось прочитать тело ответа: %w", err)
+	}
+	if len(data) > maxSourceResponseSize {
+		return validator, fmt.Errorf("%w: больше %d байт", errSourceTooLarge, maxSourceResponseSize)
+	}
+	if err := process(bytes.NewReader(data)); err != nil {
+		return validator, err
+	}
+	validator.ETag = resp.Header.Get("ETag")
+	validator.LastModified = resp.Header.Get("Last-Modified")
+	return validator, nil
+}
@@ -0,0 +1,35 @@
+//go:build nonet
+
+// network_nonet.go сборка без сетевых запросов (тег nonet): генератор всегда работает в офлайн-режиме,
+// версии берутся из дискового кэша, встроенного снимка или аппроксимируются по дате. используется для разделяемой библиотеки
+// в Python-пакетах, которые не должны обращаться к сети
+
+package useragent
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"io"
+)
+
+// networkEnabled сетевые источники доступны в этой сборке
+const networkEnabled = false
+
+// versionsSnapshotJSON снимок версий в формате ExportVersions, снятый при подготовке релиза
+//
+//go:embed data/versions_snapshot.json
+var versionsSnapshotJSON []byte
+
+// errNetworkDisabled возвращается при попытке сетевого запроса в сборке с тегом nonet
+var errNetworkDisabled = errors.New("сетевые запросы исключены из сборки (тег nonet)")
+
+// executeGet в сборке nonet не выполняет запросов
+func (g *Generator) executeGet(context.Context, string, func(io.Reader) error) error {
+	return errNetworkDisabled
+}
+
+// executeConditionalGet в сборке nonet не выполняет запросов
+func (g *Generator) executeConditionalGet(context.Context, string, sourceValidator, func(io.Reader) error) (sourceValidator, error) {
+	return sourceValidator{}, errNetworkDisabled
+}
@@ -0,0 +1,105 @@
+// norepeat.go выдача User-Agent без повторов: строка не повторяется, пока не исчерпан весь пул комбинаций
+
+package useragent
+
+import (
+	"sync"
+	"time"
+)
+
+// deckAttempts сколько случайных выборов делает колода, прежде чем перейти к оставшимся строкам инвентаря:
+// к концу цикла случайный выбор почти всегда попадает в уже выданные строки
+const deckAttempts = 8
+
+// uaDeck колода строк User-Agent для одного цикла без повторов. пока невыданных строк много,
+// они выбираются как в Get (с долями браузеров и платформ), а когда случайный выбор начинает
+// давать повторы - берутся из перемешанного остатка инвентаря. методы вызываются под mu
+type uaDeck struct {
+	mu     sync.Mutex
+	seen   map[string]struct{} // строки, выданные в текущем цикле
+	rest   []string            // невыданные строки инвентаря в случайном порядке, nil - еще не собраны
+	restAt time.Time           // versionsAt генератора на момент сборки rest
+}
+
+// newUADeck создает пустую колоду
+func newUADeck() *uaDeck {
+	return &uaDeck{seen: make(map[string]struct{})}
+}
+
+// next возвращает невыданную в этом цикле строку, false - пул исчерпан
+func (d *uaDeck) next(g *Generator) (string, bool) {
+	g.mu.RLock()
+	if d.rest != nil && !d.restAt.Equal(g.versionsAt) {
+		// версии обновились: остаток пересобирается из нового инвентаря
+		d.rest = nil
+	}
+	if d.rest == nil {
+		for range deckAttempts {
+			if ua := g.randomUA(g.rng); d.take(ua) {
+				g.mu.RUnlock()
+				return ua, true
+			}
+		}
+		d.restAt = g.versionsAt
+	}
+	g.mu.RUnlock()
+
+	if d.rest == nil {
+		entries := g.inventory()
+		d.rest = make([]string, 0, len(entries))
+		for _, e := range entries {
+			if _, dup := d.seen[e.UserAgent]; !dup {
+				d.rest = append(d.rest, e.UserAgent)
+			}
+		}
+		g.rng.Shuffle(len(d.rest), func(i, j int) { d.rest[i], d.rest[j] = d.rest[j], d.rest[i] })
+	}
+	for len(d.rest) > 0 {
+		ua := d.rest[len(d.rest)-1]
+		d.rest = d.rest[:len(d.rest)-1]
+		if d.take(ua) {
+			return ua, true
+		}
+	}
+	return "", false
+}
+
+// take отмечает строку выданной, false - она уже выдавалась в этом цикле
+func (d *uaDeck) take(ua string) bool {
+	if _, dup := d.seen[ua]; dup {
+		return false
+	}
+	d.seen[ua] = struct{}{}
+	return true
+}
+
+// draw возвращает следующую строку без повторов, а когда пул исчерпан - начинает новый цикл
+func (d *uaDeck) draw(g *Generator) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if ua, ok := d.next(g); ok {
+		return ua
+	}
+	g.logger.Debug("все комбинации User-Agent выданы, начинается новый цикл без повторов",
+		"event", eventPoolReshuffled, "size", len(d.seen))
+	clear(d.seen)
+	d.rest = nil
+	if ua, ok := d.next(g); ok {
+		return ua
+	}
+	// пустой инвентарь возможен только при частично неудачной инициализации
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.randomUA(g.rng)
+}
+
+// WithNoRepeat включает выдачу без повторов: Get (а с ним GetHeaders и NewSession) не возвращает одну строку
+// User-Agent дважды, пока не выданы все комбинации версии, браузера и платформы (см. ExportInventory),
+// после чего пул перемешивается и цикл начинается заново. полезно, чтобы параллельные воркеры не получали
+// одинаковых отпечатков. в начале цикла доли браузеров и платформ соблюдаются, к концу цикла
+// выдаются оставшиеся комбинации независимо от долей
+func WithNoRepeat() Option {
+	return func(g *Generator) {
+		g.noRepeat = newUADeck()
+	}
+}
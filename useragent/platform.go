@@ -0,0 +1,319 @@
+// platform.go таблица профилей платформ: токены User-Agent, подсказки sec-ch-ua-* и модель окна для каждой ОС
+
+package useragent
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Platform десктопная операционная система, значение основных платформ совпадает с sec-ch-ua-platform
+type Platform string
+
+const (
+	PlatformWindows  Platform = "Windows"
+	PlatformMacOS    Platform = "macOS"
+	PlatformLinux    Platform = "Linux"
+	PlatformChromeOS Platform = "Chrome OS"
+
+	// редкие варианты Windows для покрытия «длинного хвоста» аудитории, sec-ch-ua-platform у них - Windows
+	PlatformWindowsARM64 Platform = "Windows ARM64"  // Windows 11 на ARM64 (Snapdragon X): нативный браузер или x64 в эмуляции
+	PlatformWindows32    Platform = "Windows 32-bit" // 32-битный браузер на 64-битной Windows (WOW64), устаревшие конфигурации
+)
+
+// platformProfile описывает всё, что в заголовках зависит от операционной системы,
+// чтобы User-Agent, подсказки клиента и вьюпорт всегда были согласованы между собой
+type platformProfile struct {
+	name             Platform // платформа в WithPlatforms, для основных платформ - значение sec-ch-ua-platform
+	chPlatform       string   // значение sec-ch-ua-platform варианта платформы, пусто - совпадает с name
+	uaToken          string   // токен платформы в User-Agent браузеров на Chromium
+	firefoxToken     string   // токен платформы в User-Agent Firefox, пусто - Firefox на платформе не выпускается
+	firefoxTokens    []string // варианты токена Firefox (сборки дистрибутивов), повторы задают частоту, пусто - firefoxToken
+	edge             bool     // Microsoft Edge выпускается для платформы
+	platformVersions []string // значения sec-ch-ua-platform-version, повторы задают частоту
+	archs            []string // значения sec-ch-ua-arch, повторы задают частоту
+	bitness          string   // значение sec-ch-ua-bitness
+	wow64            bool     // значение sec-ch-ua-wow64: 32-битный браузер на 64-битной Windows
+
+	reservedHeights      []int   // высота, занятая ОС: панель задач Windows, строка меню и Dock macOS, верхняя панель GNOME
+	maximizedProbability float64 // вероятность того, что окно браузера развернуто на весь экран
+}
+
+// platformProfiles профили десктопных платформ, порядок важен для распознавания токена в User-Agent
+var platformProfiles = []platformProfile{
+	{
+		name:         PlatformWindows,
+		uaToken:      "Windows NT 10.0; Win64; x64",
+		firefoxToken: "Windows NT 10.0; Win64; x64",
+		edge:         true,
+		// Windows 10 сообщает 1-10.0.0, Windows 11 - 13.0.0 и выше (22H2, 23H2, 24H2)
+		platformVersions:     []string{"10.0.0", "15.0.0", "19.0.0", "19.0.0"},
+		archs:                []string{"x86"},
+		bitness:              "64",
+		reservedHeights:      []int{40, 48}, // панель задач Windows 10 / 11
+		maximizedProbability: 0.8,
+	},
+	{
+		name: PlatformMacOS,
+		// Chrome и Edge, как и Safari, замораживают версию macOS в User-Agent на 10_15_7, Firefox - на 10.15
+		uaToken:              "Macintosh; Intel Mac OS X 10_15_7",
+		firefoxToken:         "Macintosh; Intel Mac OS X 10.15",
+		edge:                 true,
+		platformVersions:     []string{"14.6.1", "15.5.0", "15.6.1", "15.6.1", "26.0.1"},
+		archs:                []string{"arm", "arm", "arm", "x86"}, // большинство маков - на Apple Silicon
+		bitness:              "64",
+		reservedHeights:      []int{25, 25 + 70}, // строка меню, строка меню + Dock
+		maximizedProbability: 0.4,
+	},
+	{
+		name: PlatformLinux,
+		// Chromium замораживает токен Linux на "X11; Linux x86_64" независимо от дистрибутива, Wayland и архитектуры:
+		// разнообразие у Chrome и Edge дает только sec-ch-ua-platform-version (версия ядра)
+		uaToken:      "X11; Linux x86_64",
+		firefoxToken: "X11; Linux x86_64",
+		// Firefox из пакетов Ubuntu и Fedora добавляет имя дистрибутива, под Wayland токен тоже X11
+		firefoxTokens: []string{
+			"X11; Linux x86_64", "X11; Linux x86_64", "X11; Linux x86_64", "X11; Linux x86_64",
+			"X11; Ubuntu; Linux x86_64", "X11; Ubuntu; Linux x86_64", "X11; Ubuntu; Linux x86_64",
+			"X11; Fedora; Linux x86_64",
+			"X11; Linux aarch64",
+		},
+		edge: true,
+		// ядра LTS-дистрибутивов (Ubuntu 22.04/24.04, Debian 12/13) и rolling-дистрибутивов
+		platformVersions:     []string{"5.15.0", "6.1.0", "6.8.0", "6.8.0", "6.12.0", "6.14.0", "6.17.0"},
+		archs:                []string{"x86"},
+		bitness:              "64",
+		reservedHeights:      []int{0, 32}, // без панелей / верхняя панель GNOME
+		maximizedProbability: 0.7,
+	},
+	{
+		name: PlatformChromeOS,
+		// сокращенный User-Agent ChromeOS содержит замороженную версию платформы
+		uaToken:              "X11; CrOS x86_64 14541.0.0",
+		platformVersions:     []string{"16181.61.0", "16328.55.0"},
+		archs:                []string{"x86", "x86", "arm"},
+		bitness:              "64",
+		reservedHeights:      []int{48}, // полка ChromeOS
+		maximizedProbability: 0.9,
+	},
+	{
+		name:       PlatformWindowsARM64,
+		chPlatform: "Windows",
+		// сокращенный User-Agent не раскрывает архитектуру: Chrome и Firefox на ARM64 отправляют токен x64
+		uaToken:          "Windows NT 10.0; Win64; x64",
+		firefoxToken:     "Windows NT 10.0; Win64; x64",
+		edge:             true,
+		platformVersions: []string{"15.0.0", "19.0.0", "19.0.0"}, // только Windows 11
+		// x64-сборка браузера в эмуляции (Prism) сообщает arch x86, нативная - arm
+		archs:                []string{"arm", "arm", "arm", "x86"},
+		bitness:              "64",
+		reservedHeights:      []int{48},
+		maximizedProbability: 0.8,
+	},
+	{
+		name:       PlatformWindows32,
+		chPlatform: "Windows",
+		// Chromium замораживает токен Win64 и для 32-битных сборок, Firefox сохраняет WOW64
+		uaToken:              "Windows NT 10.0; Win64; x64",
+		firefoxToken:         "Windows NT 10.0; WOW64",
+		edge:                 true,
+		platformVersions:     []string{"10.0.0", "10.0.0", "15.0.0"}, // в основном Windows 10
+		archs:                []string{"x86"},
+		bitness:              "32",
+		wow64:                true,
+		reservedHeights:      []int{40, 48},
+		maximizedProbability: 0.8,
+	},
+}
+
+// WithPlatforms задает платформы, для которых Get генерирует User-Agent (по умолчанию только Windows):
+// токен платформы в User-Agent и значения sec-ch-ua-platform, sec-ch-ua-platform-version и sec-ch-ua-arch
+// в GetHeaders согласованы между собой. платформа выбирается равновероятно, неизвестные значения игнорируются.
+// Edge не генерируется для ChromeOS, Firefox для ChromeOS использует токен Windows.
+// PlatformWindowsARM64 и PlatformWindows32 отличаются от Windows подсказками sec-ch-ua-arch, sec-ch-ua-bitness
+// и sec-ch-ua-wow64 (и токеном WOW64 у Firefox).
+func WithPlatforms(platforms ...Platform) Option {
+	return func(g *Generator) {
+		var profiles []platformProfile
+		for _, name := range platforms {
+			for _, p := range platformProfiles {
+				if p.name == name && !slices.ContainsFunc(profiles, func(q platformProfile) bool { return q.name == name }) {
+					profiles = append(profiles, p)
+				}
+			}
+		}
+		if len(profiles) > 0 {
+			g.platforms = profiles
+		}
+	}
+}
+
+// pickPlatform выбирает платформу для очередного User-Agent
+func (g *Generator) pickPlatform(r *rand.Rand) platformProfile {
+	if len(g.platforms) == 0 {
+		return platformProfiles[0]
+	}
+	return g.platforms[r.IntN(len(g.platforms))]
+}
+
+// profileFor возвращает профиль платформы по значению sec-ch-ua-platform (по умолчанию - Windows)
+func profileFor(name string) platformProfile {
+	for _, p := range platformProfiles {
+		if string(p.name) == name {
+			return p
+		}
+	}
+	return platformProfiles[0]
+}
+
+// hintsProfile выбирает профиль, из которого берутся подсказки клиента для User-Agent: User-Agent Chromium
+// одинаков для всех вариантов Windows, поэтому вариант выбирается среди включенных платформ с тем же токеном
+func (g *Generator) hintsProfile(r *rand.Rand, info browserInfo) platformProfile {
+	var candidates []platformProfile
+	for _, p := range g.platforms {
+		if p.secCHPlatform() == info.Platform && strings.Contains(info.UserAgent, p.uaToken) {
+			candidates = append(candidates, p)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return profileFor(info.Platform)
+	case 1:
+		return candidates[0]
+	}
+	return candidates[r.IntN(len(candidates))]
+}
+
+// secCHPlatform значение sec-ch-ua-platform для платформы
+func (p platformProfile) secCHPlatform() string {
+	if p.chPlatform != "" {
+		return p.chPlatform
+	}
+	return string(p.name)
+}
+
+// detectPlatform определяет платформу по токену в User-Agent
+func detectPlatform(ua string) string {
+	switch {
+	case strings.Contains(ua, "Android"):
+		return "Android"
+	case strings.Contains(ua, "Windows"):
+		return "Windows"
+	case strings.Contains(ua, "Macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "CrOS"):
+		return "Chrome OS"
+	default:
+		return "Linux"
+	}
+}
+
+// androidTokenRegex версия Android и модель устройства в полном (не сокращенном) User-Agent
+var androidTokenRegex = regexp.MustCompile(`Android (\d+)(?:\.(\d+))?(?:\.(\d+))?; ([^;)]+?)(?: Build/[^;)]*)?\)`)
+
+// legacyWindowsVersions значения sec-ch-ua-platform-version для Windows до 10 (Windows 7, 8 и 8.1)
+var legacyWindowsVersions = map[string]string{
+	"Windows NT 6.1": "0.1.0",
+	"Windows NT 6.2": "0.2.0",
+	"Windows NT 6.3": "0.3.0",
+}
+
+// alignHintsWithUA согласует подсказки об устройстве с токенами User-Agent, которые сообщают больше, чем профиль
+// платформы: архитектура и разрядность Linux и ChromeOS, 32-битный браузер на Windows (WOW64), версия старой Windows,
+// модель и версия Android. для синтезированных строк ничего не меняет, нужна для строк WithUserAgentList
+func alignHintsWithUA(ua string, d *deviceHints) {
+	switch {
+	case strings.Contains(ua, "aarch64") || strings.Contains(ua, "arm64") || strings.Contains(ua, "ARM64"):
+		d.Arch, d.Bitness = "arm", "64"
+	case strings.Contains(ua, "armv7l") || strings.Contains(ua, "armv8l"):
+		d.Arch, d.Bitness = "arm", "32"
+	case strings.Contains(ua, "i686") || strings.Contains(ua, "i386"):
+		d.Arch, d.Bitness = "x86", "32"
+	case strings.Contains(ua, "WOW64"):
+		d.Arch, d.Bitness, d.Wow64 = "x86", "32", true
+	}
+
+	for token, version := range legacyWindowsVersions {
+		if strings.Contains(ua, token+";") || strings.Contains(ua, token+")") {
+			d.PlatformVersion = version
+		}
+	}
+
+	// сокращенный User-Agent Android содержит "Android 10; K", полный - настоящие версию и модель
+	if m := androidTokenRegex.FindStringSubmatch(ua); m != nil && m[4] != "K" {
+		d.Model = strings.TrimSpace(m[4])
+		d.PlatformVersion = m[1] + "." + cmp.Or(m[2], "0") + "." + cmp.Or(m[3], "0")
+		d.Arch, d.Bitness = "", ""
+	}
+}
+
+// chromeUA возвращает User-Agent Google Chrome для платформы
+func (p platformProfile) chromeUA(version string) string {
+	return string(appendChromiumUA(nil, p.uaToken, version, "", false))
+}
+
+// edgeUA возвращает User-Agent Microsoft Edge для платформы: версия Chromium в токене Chrome/
+// и собственная сборка Edge в токене Edg/ (в сокращенном User-Agent обе - мажорная версия)
+func (p platformProfile) edgeUA(chromium, edge string) string {
+	return string(appendChromiumUA(nil, p.uaToken, chromium, edge, false))
+}
+
+// appendChromiumUA дописывает к dst User-Agent Chrome (edge пусто) или Edge с токеном платформы token,
+// при reduced версии сокращаются до мажорной прямо при записи, без промежуточных строк
+func appendChromiumUA(dst []byte, token, chromium, edge string, reduced bool) []byte {
+	dst = append(dst, chromiumUAPrefix...)
+	dst = append(dst, token...)
+	dst = append(dst, chromiumUAEngine...)
+	dst = appendUAVersion(dst, chromium, reduced)
+	dst = append(dst, chromiumUASuffix...)
+	if edge != "" {
+		dst = append(dst, edgeUAToken...)
+		dst = appendUAVersion(dst, edge, reduced)
+	}
+	return dst
+}
+
+// firefoxUA возвращает User-Agent Firefox для платформы с основным токеном
+func (p platformProfile) firefoxUA(version string) string {
+	token := p.firefoxToken
+	if token == "" {
+		token = platformProfiles[0].firefoxToken
+	}
+	return fmt.Sprintf(firefoxUATemplate, token, version, version)
+}
+
+// randomFirefoxUA возвращает User-Agent Firefox для платформы со случайным вариантом токена
+func (p platformProfile) randomFirefoxUA(r *rand.Rand, version string) string {
+	if len(p.firefoxTokens) == 0 {
+		return p.firefoxUA(version)
+	}
+	return fmt.Sprintf(firefoxUATemplate, p.firefoxTokens[r.IntN(len(p.firefoxTokens))], version, version)
+}
+
+// firefoxUAVariants возвращает User-Agent Firefox для платформы со всеми вариантами токена
+func (p platformProfile) firefoxUAVariants(version string) []string {
+	if len(p.firefoxTokens) == 0 {
+		return []string{p.firefoxUA(version)}
+	}
+	var uas []string
+	for _, token := range p.firefoxTokens {
+		if ua := fmt.Sprintf(firefoxUATemplate, token, version, version); !slices.Contains(uas, ua) {
+			uas = append(uas, ua)
+		}
+	}
+	return uas
+}
+
+// pickPlatformVersion выбирает версию платформы для sec-ch-ua-platform-version
+func (p platformProfile) pickPlatformVersion(r *rand.Rand) string {
+	return p.platformVersions[r.IntN(len(p.platformVersions))]
+}
+
+// pickArch выбирает архитектуру для sec-ch-ua-arch
+func (p platformProfile) pickArch(r *rand.Rand) string {
+	return p.archs[r.IntN(len(p.archs))]
+}
@@ -0,0 +1,60 @@
+// privacy.go сигналы приватности: Global Privacy Control (sec-gpc) и Do Not Track (dnt)
+
+package useragent
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// WithGPC добавляет "sec-gpc: 1" (Global Privacy Control) с вероятностью p (от 0 до 1).
+// Firefox отправляет заголовок по настройке браузера, Chrome и Edge - с расширениями приватности
+// (поэтому у них он идет последним, как заголовок, добавленный расширением). Safari GPC не поддерживает.
+// решение принимается один раз для отпечатка: в сессии заголовок либо есть во всех запросах, либо его нет.
+// сигнал включает заметная, но небольшая часть пользователей, поэтому реалистичны значения в единицы процентов.
+// по умолчанию заголовок не отправляется.
+func WithGPC(p float64) Option {
+	return func(g *Generator) {
+		if math.IsNaN(p) {
+			return
+		}
+		g.gpc = min(max(p, 0), 1)
+	}
+}
+
+// WithDoNotTrack добавляет "dnt: 1" с вероятностью p (от 0 до 1): настройку "Do Not Track" поддерживают
+// Chrome, Edge и Firefox, Safari перестал отправлять заголовок в версии 12.1. как и WithGPC, решение
+// принимается один раз для отпечатка, реалистичны значения в единицы процентов.
+// по умолчанию заголовок не отправляется.
+func WithDoNotTrack(p float64) Option {
+	return func(g *Generator) {
+		if math.IsNaN(p) {
+			return
+		}
+		g.dnt = min(max(p, 0), 1)
+	}
+}
+
+// pickPrivacySignals решает, какие сигналы приватности отправляет браузер отпечатка
+func (g *Generator) pickPrivacySignals(r *rand.Rand, info browserInfo) (gpc, dnt bool) {
+	if info.BrandName == "Safari" {
+		return false, false
+	}
+	if g.gpc > 0 {
+		gpc = r.Float64() < g.gpc
+	}
+	if g.dnt > 0 {
+		dnt = r.Float64() < g.dnt
+	}
+	return gpc, dnt
+}
+
+// addPrivacyHeaders добавляет в заголовки выбранные для отпечатка сигналы приватности
+func addPrivacyHeaders(fp fingerprint, headers map[string]string) {
+	if fp.gpc {
+		headers["sec-gpc"] = "1"
+	}
+	if fp.dnt {
+		headers["dnt"] = "1"
+	}
+}
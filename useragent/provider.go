@@ -0,0 +1,16 @@
+// provider.go интерфейс генератора для кода, который зависит от go-fake-useragent
+
+package useragent
+
+// Provider минимальный интерфейс генератора User-Agent: код, принимающий Provider вместо *Generator,
+// можно тестировать без сети и случайности, подставив фиксированную реализацию из пакета fakegen
+type Provider interface {
+	// Get возвращает строку User-Agent
+	Get() string
+	// GetHeaders возвращает HTTP-заголовки браузера для запроса targetURL
+	GetHeaders(targetURL ...string) map[string]string
+	// GetCrawlerHeaders возвращает HTTP-заголовки поискового бота
+	GetCrawlerHeaders(crawlerType CrawlerType) map[string]string
+}
+
+var _ Provider = (*Generator)(nil)
@@ -0,0 +1,65 @@
+// random.go источники случайности: общий для вызовов генератора и детерминированный для персон из seed
+
+package useragent
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math/rand/v2"
+	"sync"
+)
+
+// globalSource источник на основе глобального генератора math/rand/v2, безопасен для конкурентного использования
+type globalSource struct{}
+
+func (globalSource) Uint64() uint64 {
+	return rand.Uint64()
+}
+
+// globalRand используется обычными вызовами (Get, GetHeaders, NewSession), если не задан WithSeed или WithRandSource
+var globalRand = rand.New(globalSource{})
+
+// lockedSource источник генератора с WithSeed или WithRandSource, безопасен для конкурентного использования
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+// WithSeed делает генератор детерминированным: последовательность результатов Get, GetHeaders, NewSession
+// и всех случайных выборов (браузер, платформа, разрешение экрана, вьюпорт) однозначно определяется
+// seed - для снапшот-тестов и воспроизведения ошибок из отчетов пользователей.
+//
+// последовательность повторяется только при одинаковых опциях, одинаковом пуле версий (фиксированный кэш
+// или WithOfflineMode в один и тот же день) и одинаковом порядке вызовов: при конкурентных вызовах
+// генератор остается потокобезопасным, но распределение результатов между горутинами не детерминировано.
+func WithSeed(seed uint64) Option {
+	return func(g *Generator) {
+		g.rng = rand.New(&lockedSource{src: rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)})
+	}
+}
+
+// WithRandSource задает источник случайности для выбора браузера и заголовков вместо глобального math/rand/v2:
+// например, общий с другими компонентами seeded-источник или криптографический (rand.NewChaCha8 с секретным
+// seed либо собственная реализация rand.Source поверх crypto/rand). подходит и *rand.Rand.
+// обращения к источнику сериализуются генератором, поэтому источник не обязан быть потокобезопасным,
+// но при совместном использовании с другими компонентами они должны сами синхронизировать доступ к нему.
+// PersonaFromSeed по-прежнему использует собственный источник из seed. nil игнорируется.
+func WithRandSource(src rand.Source) Option {
+	return func(g *Generator) {
+		if src != nil {
+			g.rng = rand.New(&lockedSource{src: src})
+		}
+	}
+}
+
+// seededRand создает детерминированный источник, однозначно определяемый строкой seed
+func seededRand(seed string) *rand.Rand {
+	sum := sha256.Sum256([]byte(seed))
+	return rand.New(rand.NewPCG(binary.LittleEndian.Uint64(sum[:8]), binary.LittleEndian.Uint64(sum[8:16])))
+}
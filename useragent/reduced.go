@@ -0,0 +1,70 @@
+// reduced.go сокращенный User-Agent Chromium (UA reduction): в строке только мажорная версия,
+// полная версия доступна сайтам лишь через подсказки клиента
+
+package useragent
+
+import (
+	"math/rand/v2"
+	"strings"
+)
+
+// reducedVersionSuffix нулевые build и patch сокращенной версии Chromium ("139.0.0.0")
+const reducedVersionSuffix = ".0.0.0"
+
+// WithFullVersionUA отключает сокращение User-Agent: Chrome и Edge получают полную версию в строке
+// ("Chrome/139.0.7258.155") вместо сокращенной ("Chrome/139.0.0.0").
+//
+// по умолчанию генератор ведет себя как Chrome 110+ и Edge: в User-Agent только мажорная версия,
+// а полная передается в sec-ch-ua-full-version и sec-ch-ua-full-version-list. полная версия в User-Agent
+// нужна только для имитации устаревших браузеров или сайтов, которые разбирают её из строки.
+func WithFullVersionUA() Option {
+	return func(g *Generator) {
+		g.fullVersionUA = true
+	}
+}
+
+// uaVersion возвращает версию Chromium в том виде, в каком она попадает в User-Agent
+func (g *Generator) uaVersion(version string) string {
+	if g.fullVersionUA {
+		return version
+	}
+	return reducedVersion(version)
+}
+
+// reducedVersion сокращает полную версию Chromium до мажорной с нулевыми build и patch
+func reducedVersion(version string) string {
+	major, _, _ := strings.Cut(version, ".")
+	return major + reducedVersionSuffix
+}
+
+// appendUAVersion дописывает к dst версию Chromium, при reduced - сокращенную, как reducedVersion
+func appendUAVersion(dst []byte, version string, reduced bool) []byte {
+	if !reduced {
+		return append(dst, version...)
+	}
+	major, _, _ := strings.Cut(version, ".")
+	return append(append(dst, major...), reducedVersionSuffix...)
+}
+
+// fullVersionFor восстанавливает полную версию для сокращенного User-Agent: случайная версия пула
+// с той же мажорной версией, чтобы доли полных версий в подсказках совпадали с пулом.
+// если в пуле нет такой мажорной версии (строка из WithUserAgentList), версия остается сокращенной
+func (g *Generator) fullVersionFor(r *rand.Rand, info browserInfo) string {
+	if !strings.HasSuffix(info.FullVersion, reducedVersionSuffix) {
+		return info.FullVersion
+	}
+	prefix := info.MajorVersion + "."
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var matching []string
+	for _, v := range g.versions {
+		if strings.HasPrefix(v, prefix) && v != info.FullVersion {
+			matching = append(matching, v)
+		}
+	}
+	if len(matching) == 0 {
+		return info.FullVersion
+	}
+	return matching[r.IntN(len(matching))]
+}
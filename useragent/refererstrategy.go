@@ -0,0 +1,132 @@
+// refererstrategy.go стратегии выбора страницы, с которой GetHeaders "совершает переход": поиск, соцсети, тот же сайт
+
+package useragent
+
+import (
+	"math/rand/v2"
+	"net/url"
+)
+
+// RefererStrategy откуда пользователь пришел на страницу в GetHeaders и GetHeaderSet (генератора и сессии):
+// от этого зависят referer, sec-fetch-site и origin. GetNavigationHeaders и GetHeadersFor получают
+// страницу перехода явно, и стратегия на них не влияет
+type RefererStrategy string
+
+const (
+	// RefererAuto - поведение по умолчанию: при известном адресе - переход с главной страницы того же сайта,
+	// иначе - из поисковой выдачи Google
+	RefererAuto RefererStrategy = ""
+	// RefererDirect - прямой заход (адресная строка, закладка): без referer и с sec-fetch-site: none
+	RefererDirect RefererStrategy = "direct"
+	// RefererNone - переход с другого сайта, который не передает referer (rel="noreferrer", политика no-referrer):
+	// referer нет, но sec-fetch-site: cross-site
+	RefererNone RefererStrategy = "none"
+	// RefererSearch - переход из выдачи поисковой системы (Google, Яндекс, Bing, DuckDuckGo) по запросу
+	// из слов адреса и названия сайта: https://www.google.com/search?q=running+shoes+example
+	RefererSearch RefererStrategy = "search-engine"
+	// RefererSocial - переход по ссылке из социальной сети
+	RefererSocial RefererStrategy = "social"
+	// RefererSameSite - переход с главной страницы того же сайта, без адреса - как RefererAuto
+	RefererSameSite RefererStrategy = "same-site"
+	// RefererCustom - переход со случайной страницы из списка, переданного в WithRefererStrategy
+	RefererCustom RefererStrategy = "custom"
+)
+
+// searchEngine поисковая система: адрес страницы выдачи и относительная частота
+type searchEngine struct {
+	Name   string
+	Host   string
+	Path   string // путь страницы выдачи
+	Param  string // параметр с поисковым запросом
+	Extra  string // постоянные параметры выдачи после запроса, пусто - нет
+	Weight float64
+}
+
+// searchEngines поисковые системы; первая используется в RefererAuto без адреса
+var searchEngines = []searchEngine{
+	{Name: "google", Host: "www.google.com", Path: "/search", Param: "q", Weight: 70},
+	{Name: "yandex", Host: "yandex.ru", Path: "/search/", Param: "text", Weight: 15},
+	{Name: "bing", Host: "www.bing.com", Path: "/search", Param: "q", Extra: "form=QBLH", Weight: 10},
+	{Name: "duckduckgo", Host: "duckduckgo.com", Path: "/", Param: "q", Weight: 5},
+}
+
+// searchURL возвращает адрес выдачи по запросу query (пустой запрос - "?q=", как при переходе из пустой выдачи)
+func (e searchEngine) searchURL(query string) *url.URL {
+	rawQuery := e.Param + "=" + url.QueryEscape(query)
+	if e.Extra != "" {
+		rawQuery += "&" + e.Extra
+	}
+	return &url.URL{Scheme: "https", Host: e.Host, Path: e.Path, RawQuery: rawQuery}
+}
+
+// socialReferers страницы социальных сетей, с которых приходят по ссылкам: сети отдают переходы через
+// собственные страницы-прослойки, поэтому сайт видит только их origin
+var socialReferers = []string{
+	"https://l.facebook.com/",
+	"https://t.co/",
+	"https://www.reddit.com/",
+	"https://vk.com/",
+	"https://www.linkedin.com/",
+	"https://www.youtube.com/",
+}
+
+// WithRefererStrategy задает, откуда пользователь приходит на страницу в GetHeaders (см. RefererStrategy).
+// для RefererCustom страницы перехода передаются в customURLs и выбираются случайно, без хотя бы одного
+// абсолютного http(s) адреса опция игнорируется, как и неизвестная стратегия.
+// итоговый referer по-прежнему формируется по политике WithReferrerPolicy: при переходе с другого сайта
+// браузер по умолчанию отправляет только origin страницы
+func WithRefererStrategy(strategy RefererStrategy, customURLs ...string) Option {
+	return func(g *Generator) {
+		switch strategy {
+		case RefererAuto, RefererDirect, RefererNone, RefererSearch, RefererSocial, RefererSameSite:
+			g.refererStrategy = strategy
+		case RefererCustom:
+			var pages []*url.URL
+			for _, raw := range customURLs {
+				if u := parseAbsoluteURL(raw); u != nil {
+					pages = append(pages, u)
+				}
+			}
+			if len(pages) > 0 {
+				g.refererStrategy, g.customReferers = strategy, pages
+			}
+		}
+	}
+}
+
+// refererPage выбирает страницу, с которой совершается переход на to (nil - неизвестный адрес),
+// и сообщает, отправляется ли Origin; nil - прямой заход
+func (g *Generator) refererPage(r *rand.Rand, to *url.URL) (from *url.URL, origin bool) {
+	switch g.refererStrategy {
+	case RefererDirect:
+		return nil, false
+	case RefererNone, RefererSearch:
+		return pickSearchEngine(r).searchURL(searchQuery(to)), false
+	case RefererSocial:
+		u, _ := url.Parse(socialReferers[r.IntN(len(socialReferers))])
+		return u, false
+	case RefererCustom:
+		u := *g.customReferers[r.IntN(len(g.customReferers))]
+		return &u, false
+	}
+	if to != nil {
+		return &url.URL{Scheme: to.Scheme, Host: to.Host, Path: "/"}, true
+	}
+	return searchEngines[0].searchURL(""), false
+}
+
+// pickSearchEngine выбирает поисковую систему с учетом весов
+func pickSearchEngine(r *rand.Rand) searchEngine {
+	var total float64
+	for _, e := range searchEngines {
+		total += e.Weight
+	}
+	x := r.Float64() * total
+	for _, e := range searchEngines {
+		if x < e.Weight {
+			return e
+		}
+		x -= e.Weight
+	}
+	return searchEngines[0]
+}
@@ -0,0 +1,197 @@
+// referrer.go вычисление заголовков referer и sec-fetch-site по правилам Referrer-Policy
+
+package useragent
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ReferrerPolicy политика формирования заголовка Referer (значения заголовка Referrer-Policy)
+// https://www.w3.org/TR/referrer-policy/#referrer-policies
+type ReferrerPolicy string
+
+const (
+	// StrictOriginWhenCrossOrigin - политика Chrome по умолчанию: полный адрес для same-origin,
+	// только origin для cross-origin и ничего при переходе с HTTPS на HTTP
+	StrictOriginWhenCrossOrigin ReferrerPolicy = "strict-origin-when-cross-origin"
+	// NoReferrer - заголовок Referer не отправляется
+	NoReferrer ReferrerPolicy = "no-referrer"
+	// OriginOnly - всегда отправляется только origin (политика "origin")
+	OriginOnly ReferrerPolicy = "origin"
+	// StrictOrigin - только origin и ничего при переходе с HTTPS на HTTP
+	StrictOrigin ReferrerPolicy = "strict-origin"
+	// SameOrigin - полный адрес для same-origin, для cross-origin заголовок не отправляется
+	SameOrigin ReferrerPolicy = "same-origin"
+	// OriginWhenCrossOrigin - полный адрес для same-origin, только origin для cross-origin
+	OriginWhenCrossOrigin ReferrerPolicy = "origin-when-cross-origin"
+	// NoReferrerWhenDowngrade - полный адрес, кроме перехода с HTTPS на HTTP
+	NoReferrerWhenDowngrade ReferrerPolicy = "no-referrer-when-downgrade"
+	// UnsafeURL - всегда полный адрес
+	UnsafeURL ReferrerPolicy = "unsafe-url"
+)
+
+// navigation описывает переход, для которого генерируются заголовки
+type navigation struct {
+	from   *url.URL       // страница, с которой совершается переход, nil - прямой заход (адресная строка, закладка)
+	to     *url.URL       // целевая страница, nil - неизвестна
+	policy ReferrerPolicy // политика формирования Referer
+	origin string         // значение заголовка Origin, пусто - не отправляется
+
+	resource ResourceType // тип запрашиваемого ресурса, пусто - навигация на страницу
+}
+
+// WithReferrerPolicy устанавливает политику формирования Referer по умолчанию
+// (StrictOriginWhenCrossOrigin, как в Chrome)
+func WithReferrerPolicy(policy ReferrerPolicy) Option {
+	return func(g *Generator) {
+		if policy != "" {
+			g.referrerPolicy = policy
+		}
+	}
+}
+
+// GetHeadersWithPolicy аналогичен GetHeaders, но формирует Referer по указанной политике
+// (пустая политика - политика WithReferrerPolicy)
+func (g *Generator) GetHeadersWithPolicy(policy ReferrerPolicy, targetURL ...string) map[string]string {
+	nav := g.navigationFor(targetURL...)
+	if policy != "" {
+		nav.policy = policy
+	}
+	return g.headersFor(g.Get(), nav)
+}
+
+// GetNavigationHeaders генерирует заголовки для перехода по ссылке со страницы fromURL на toURL:
+// referer (по политике WithReferrerPolicy), sec-fetch-site и sec-fetch-mode вычисляются из пары адресов,
+// как это делает браузер при переходе пользователя по ссылке.
+// пустой fromURL означает прямой заход (адресная строка, закладка): без referer и с sec-fetch-site: none.
+// Origin при навигационном GET-запросе браузер не отправляет.
+func (g *Generator) GetNavigationHeaders(fromURL, toURL string) map[string]string {
+	return g.headersFor(g.Get(), g.linkNavigation("", fromURL, toURL))
+}
+
+// GetNavigationHeadersWithPolicy аналогичен GetNavigationHeaders, но формирует Referer по указанной политике
+// (пустая политика - политика WithReferrerPolicy)
+func (g *Generator) GetNavigationHeadersWithPolicy(policy ReferrerPolicy, fromURL, toURL string) map[string]string {
+	return g.headersFor(g.Get(), g.linkNavigation(policy, fromURL, toURL))
+}
+
+// linkNavigation описывает переход по ссылке со страницы fromURL на toURL по политике policy
+// (пустая - политика WithReferrerPolicy)
+func (g *Generator) linkNavigation(policy ReferrerPolicy, fromURL, toURL string) navigation {
+	if policy == "" {
+		policy = g.referrerPolicy
+	}
+	return navigation{
+		from:   parseAbsoluteURL(fromURL),
+		to:     parseAbsoluteURL(toURL),
+		policy: policy,
+	}
+}
+
+// parseAbsoluteURL разбирает абсолютный http(s) адрес, для остальных возвращает nil
+func parseAbsoluteURL(raw string) *url.URL {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil
+	}
+	return u
+}
+
+// originOf возвращает origin адреса в виде scheme://host
+func originOf(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
+// sameOrigin проверяет совпадение схемы и хоста (с портом)
+func sameOrigin(a, b *url.URL) bool {
+	return strings.EqualFold(a.Scheme, b.Scheme) && strings.EqualFold(a.Host, b.Host)
+}
+
+// siteOf возвращает упрощенный "сайт" хоста: два последних домена
+// (без списка публичных суффиксов, что достаточно для большинства доменов)
+func siteOf(u *url.URL) string {
+	labels := strings.Split(strings.ToLower(u.Hostname()), ".")
+	if len(labels) <= 2 {
+		return strings.Join(labels, ".")
+	}
+	return strings.Join(labels[len(labels)-2:], ".")
+}
+
+// secFetchSite вычисляет значение sec-fetch-site для перехода
+func (n navigation) secFetchSite() string {
+	switch {
+	case n.from == nil:
+		return "none"
+	case n.to == nil:
+		return "cross-site"
+	case sameOrigin(n.from, n.to):
+		return "same-origin"
+	case n.from.Scheme == n.to.Scheme && siteOf(n.from) == siteOf(n.to):
+		return "same-site"
+	default:
+		return "cross-site"
+	}
+}
+
+// referrer вычисляет значение заголовка Referer для перехода по правилам политики
+func (n navigation) referrer() string {
+	if n.from == nil {
+		return ""
+	}
+
+	// адрес без фрагмента и данных пользователя, как его отправляет браузер
+	stripped := *n.from
+	stripped.Fragment = ""
+	stripped.RawFragment = ""
+	stripped.User = nil
+	if stripped.Path == "" {
+		stripped.Path = "/"
+	}
+	full := stripped.String()
+	origin := originOf(n.from) + "/"
+
+	crossOrigin := n.to == nil || !sameOrigin(n.from, n.to)
+	downgrade := n.to != nil && n.from.Scheme == "https" && n.to.Scheme == "http"
+
+	switch n.policy {
+	case NoReferrer:
+		return ""
+	case OriginOnly:
+		return origin
+	case StrictOrigin:
+		if downgrade {
+			return ""
+		}
+		return origin
+	case SameOrigin:
+		if crossOrigin {
+			return ""
+		}
+		return full
+	case OriginWhenCrossOrigin:
+		if crossOrigin {
+			return origin
+		}
+		return full
+	case NoReferrerWhenDowngrade:
+		if downgrade {
+			return ""
+		}
+		return full
+	case UnsafeURL:
+		return full
+	default: // StrictOriginWhenCrossOrigin
+		switch {
+		case downgrade:
+			return ""
+		case crossOrigin:
+			return origin
+		default:
+			return full
+		}
+	}
+}
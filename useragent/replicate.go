@@ -0,0 +1,104 @@
+// replicate.go передача версий браузеров между процессами: ведущий процесс с доступом в сеть экспортирует,
+// рабочие процессы без выхода в интернет импортируют
+
+package useragent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ошибки импорта версий
+var (
+	errImportBadSignature = errors.New("подпись набора версий отсутствует или неверна")
+	errImportEmpty        = errors.New("набор версий пуст")
+	errImportBadFormat    = errors.New("набор содержит версии неверного формата")
+)
+
+// ExportVersions сериализует текущие версии браузеров в небольшой блок (формат дискового кэша, единицы килобайт)
+// для передачи рабочим процессам через очередь, key-value хранилище или HTTP: вместе с ImportVersions
+// позволяет держать сеть только у ведущего процесса и обходиться без общей файловой системы.
+//
+// если задан WithCacheSigningKey, блок подписывается HMAC-SHA256 тем же ключом.
+// экспортировать стоит после обновления версий, например по уведомлению из Subscribe.
+func (g *Generator) ExportVersions() ([]byte, error) {
+	g.mu.RLock()
+	cache := cacheFile{
+		Timestamp: time.Now(),
+		Versions:  g.versions,
+		Firefox:   g.firefoxVersions,
+		Edge:      g.edgeVersions,
+	}
+	g.mu.RUnlock()
+
+	if len(cache.Versions) == 0 {
+		return nil, errImportEmpty
+	}
+	if g.cacheKey != nil {
+		signature, err := cache.sign(g.cacheKey)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось подписать набор версий: %w", err)
+		}
+		cache.Signature = signature
+	}
+	return json.Marshal(cache)
+}
+
+// ImportVersions заменяет версии браузеров набором, полученным из ExportVersions другого процесса:
+// подписчики Subscribe получают уведомление с OriginImport, а при включенном WithDiskCache набор
+// сохраняется на диск и переживет перезапуск рабочего процесса.
+//
+// при заданном WithCacheSigningKey принимаются только блоки, подписанные тем же ключом. блок, экспортированный
+// раньше уже принятого, игнорируется без ошибки: доставка через очередь не обязана сохранять порядок.
+// поврежденный или неподписанный блок отклоняется целиком, текущие версии при этом не меняются.
+// с закрепленными версиями WithStaticVersion или WithVersions импорт отклоняется.
+func (g *Generator) ImportVersions(data []byte) error {
+	if g.versionsPinned() {
+		return errVersionsPinned
+	}
+	if len(data) > maxCacheFileSize {
+		return fmt.Errorf("набор версий превышает %d байт", maxCacheFileSize)
+	}
+	var cache cacheFile
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return fmt.Errorf("не удалось распарсить набор версий: %w", err)
+	}
+	if g.cacheKey != nil && !cache.verify(g.cacheKey) {
+		return errImportBadSignature
+	}
+	if len(cache.Versions) == 0 {
+		return errImportEmpty
+	}
+	if !allMatch(cache.Versions, chromiumVersionRegex) || !allMatch(cache.Edge, chromiumVersionRegex) ||
+		!allMatch(cache.Firefox, firefoxVersionRegex) {
+		return errImportBadFormat
+	}
+
+	g.mu.Lock()
+	if cache.Timestamp.Before(g.importedAt) {
+		g.mu.Unlock()
+		return nil
+	}
+	g.importedAt = cache.Timestamp
+	if len(cache.Firefox) > 0 {
+		g.firefoxVersions = cache.Firefox
+	}
+	if len(cache.Edge) > 0 {
+		g.edgeVersions = cache.Edge
+	}
+	g.mu.Unlock()
+
+	g.setVersions(cache.Versions, OriginImport)
+	g.mu.Lock()
+	g.versionsAt = cache.Timestamp
+	g.mu.Unlock()
+	g.logger.Debug("версии браузеров импортированы", "event", eventVersionsImported,
+		"versions", len(cache.Versions), "exported_at", cache.Timestamp)
+
+	if g.diskCachePath != "" {
+		g.saveToDiskCache()
+	}
+	return nil
+}
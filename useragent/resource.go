@@ -0,0 +1,192 @@
+// resource.go заголовки запросов подресурсов страницы: изображений, скриптов, стилей, шрифтов, fetch/XHR и фреймов
+
+package useragent
+
+import "net/url"
+
+// ResourceType тип запрашиваемого ресурса (значение sec-fetch-dest)
+type ResourceType string
+
+const (
+	ResourceDocument ResourceType = "document" // навигация на страницу
+	ResourceIframe   ResourceType = "iframe"   // загрузка страницы во фрейме
+	ResourceImage    ResourceType = "image"    // <img>, фоновые изображения CSS
+	ResourceScript   ResourceType = "script"   // <script src>
+	ResourceStyle    ResourceType = "style"    // <link rel="stylesheet">
+	ResourceFont     ResourceType = "font"     // @font-face
+	ResourceFetch    ResourceType = "fetch"    // fetch() и XMLHttpRequest
+	ResourceVideo    ResourceType = "video"    // <video>
+	ResourceAudio    ResourceType = "audio"    // <audio>
+)
+
+// resourceProfile отличия запроса ресурса от навигации: режим запроса, accept и приоритет
+// по семейству браузера (ключ "" - браузеры на Chromium)
+type resourceProfile struct {
+	dest     string
+	mode     string
+	accept   map[string]string // пусто для семейства - accept навигации
+	priority map[string]string
+	encoding map[string]string // accept-encoding, пусто - сжатие как у остальных запросов
+	rangeReq bool              // запрос начинается с range: bytes=0- (медиа загружаются частями)
+}
+
+// resourceProfiles заголовки подресурсов по наблюдениям за Chrome 14x, Firefox 14x и Safari 26
+var resourceProfiles = map[ResourceType]resourceProfile{
+	ResourceIframe: {
+		dest:     "iframe",
+		mode:     "navigate",
+		priority: map[string]string{"": "u=0, i", "Firefox": "u=4, i", "Safari": "u=0, i"},
+	},
+	ResourceImage: {
+		dest: "image",
+		mode: "no-cors",
+		accept: map[string]string{
+			"":        "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8",
+			"Firefox": "image/avif,image/webp,image/png,image/svg+xml,image/*;q=0.8,*/*;q=0.5",
+			"Safari":  "image/webp,image/avif,image/jxl,image/heic,image/heic-sequence,video/*;q=0.8,image/png,image/svg+xml,image/*;q=0.8,*/*;q=0.5",
+		},
+		priority: map[string]string{"": "i", "Firefox": "u=5, i", "Safari": "u=5, i"},
+	},
+	ResourceScript: {
+		dest:     "script",
+		mode:     "no-cors",
+		accept:   map[string]string{"": "*/*", "Firefox": "*/*", "Safari": "*/*"},
+		priority: map[string]string{"": "u=1", "Firefox": "u=2", "Safari": "u=2"},
+	},
+	ResourceStyle: {
+		dest:     "style",
+		mode:     "no-cors",
+		accept:   map[string]string{"": "text/css,*/*;q=0.1", "Firefox": "text/css,*/*;q=0.1", "Safari": "text/css,*/*;q=0.1"},
+		priority: map[string]string{"": "u=0", "Firefox": "u=2", "Safari": "u=1"},
+	},
+	ResourceFont: {
+		dest: "font",
+		mode: "cors",
+		accept: map[string]string{
+			"":        "*/*",
+			"Firefox": "application/font-woff2;q=1.0,application/font-woff;q=0.9,*/*;q=0.8",
+			"Safari":  "*/*",
+		},
+		priority: map[string]string{"": "u=0", "Firefox": "u=3", "Safari": "u=1"},
+	},
+	ResourceFetch: {
+		dest:     "empty",
+		mode:     "cors",
+		accept:   map[string]string{"": "*/*", "Firefox": "*/*", "Safari": "*/*"},
+		priority: map[string]string{"": "u=1, i", "Firefox": "u=4", "Safari": "u=3, i"},
+	},
+	ResourceVideo: {
+		dest: "video",
+		mode: "no-cors",
+		accept: map[string]string{
+			"":        "*/*",
+			"Firefox": "video/webm,video/ogg,video/*;q=0.9,application/ogg;q=0.7,audio/*;q=0.6,*/*;q=0.5",
+			"Safari":  "*/*",
+		},
+		priority: map[string]string{"": "i", "Firefox": "u=4", "Safari": "u=3, i"},
+		encoding: map[string]string{"": "identity;q=1, *;q=0", "Firefox": "identity", "Safari": "identity"},
+		rangeReq: true,
+	},
+	ResourceAudio: {
+		dest: "audio",
+		mode: "no-cors",
+		accept: map[string]string{
+			"":        "*/*",
+			"Firefox": "audio/webm,audio/ogg,audio/wav,audio/*;q=0.9,application/ogg;q=0.7,video/*;q=0.6,*/*;q=0.5",
+			"Safari":  "*/*",
+		},
+		priority: map[string]string{"": "i", "Firefox": "u=4", "Safari": "u=3, i"},
+		encoding: map[string]string{"": "identity;q=1, *;q=0", "Firefox": "identity", "Safari": "identity"},
+		rangeReq: true,
+	},
+}
+
+// highEntropyHeaders подсказки клиента, которые браузер отправляет только источнику, запросившему их через Accept-CH
+// (sec-ch-ua, sec-ch-ua-mobile и sec-ch-ua-platform отправляются всем)
+var highEntropyHeaders = []string{
+	"sec-ch-ua-arch", "sec-ch-ua-bitness", "sec-ch-ua-full-version", "sec-ch-ua-full-version-list",
+	"sec-ch-ua-model", "sec-ch-ua-platform-version", "sec-ch-ua-wow64", "sec-ch-viewport-height",
+	"sec-ch-viewport-width", "sec-ch-ua-form-factors", "viewport-width", "device-memory", "downlink", "dpr", "ect", "rtt",
+}
+
+// GetHeadersFor генерирует заголовки запроса ресурса resourceURL, загружаемого страницей pageURL:
+// для каждого типа ресурса accept, sec-fetch-mode, sec-fetch-dest, priority и origin соответствуют браузеру,
+// а referer и sec-fetch-site вычисляются из пары адресов (как в GetNavigationHeaders).
+// подсказки клиента с высокой энтропией отправляются только источнику страницы.
+// для ResourceDocument результат совпадает с GetNavigationHeaders(pageURL, resourceURL).
+// для эмуляции загрузки страницы целиком используйте Session.GetHeadersFor: ресурсы загружает один браузер.
+func (g *Generator) GetHeadersFor(resource ResourceType, pageURL, resourceURL string) map[string]string {
+	return g.headersFor(g.Get(), g.resourceNavigation("", resource, pageURL, resourceURL))
+}
+
+// GetHeadersForWithPolicy аналогичен GetHeadersFor, но формирует Referer по указанной политике,
+// например атрибута referrerpolicy тега (пустая политика - политика WithReferrerPolicy)
+func (g *Generator) GetHeadersForWithPolicy(policy ReferrerPolicy, resource ResourceType, pageURL, resourceURL string) map[string]string {
+	return g.headersFor(g.Get(), g.resourceNavigation(policy, resource, pageURL, resourceURL))
+}
+
+// resourceNavigation описывает запрос ресурса со страницы pageURL по политике policy
+// (пустая - политика WithReferrerPolicy)
+func (g *Generator) resourceNavigation(policy ReferrerPolicy, resource ResourceType, pageURL, resourceURL string) navigation {
+	nav := g.linkNavigation(policy, pageURL, resourceURL)
+	nav.resource = resource
+	if _, ok := resourceProfiles[resource]; !ok {
+		return nav
+	}
+	// подресурс всегда загружается какой-то страницей: без pageURL - главной страницей сайта ресурса
+	if nav.from == nil && nav.to != nil {
+		nav.from = &url.URL{Scheme: nav.to.Scheme, Host: nav.to.Host, Path: "/"}
+	}
+	// Origin при GET-запросе в режиме cors отправляется только другому источнику
+	if resourceProfiles[resource].mode == "cors" && nav.from != nil && (nav.to == nil || !sameOrigin(nav.from, nav.to)) {
+		nav.origin = originOf(nav.from)
+	}
+	return nav
+}
+
+// requestHeaders генерирует заголовки запроса: заголовки навигации браузера, согласованные с его версией
+// и измененные под тип ресурса
+func requestHeaders(fp fingerprint, nav navigation) map[string]string {
+	headers := browserHeaders(fp, nav)
+	addPrivacyHeaders(fp, headers)
+	family := fp.info.BrandName
+	if family != "Firefox" && family != "Safari" {
+		family = ""
+		applyHeaderRules(headers, fp, nav)
+	}
+
+	profile, ok := resourceProfiles[nav.resource]
+	if !ok {
+		return headers
+	}
+	headers["sec-fetch-dest"] = profile.dest
+	headers["sec-fetch-mode"] = profile.mode
+	if accept, ok := profile.accept[family]; ok {
+		headers["accept"] = accept
+	}
+	if priority, ok := profile.priority[family]; ok {
+		headers["priority"] = priority
+	}
+	if encoding, ok := profile.encoding[family]; ok {
+		headers["accept-encoding"] = encoding
+	}
+	if profile.rangeReq {
+		headers["range"] = "bytes=0-"
+	}
+
+	// заголовки, которые отправляются только при навигации по действию пользователя
+	delete(headers, "sec-fetch-user")
+	delete(headers, "cache-control")
+	delete(headers, "pragma")
+	if profile.mode != "navigate" {
+		delete(headers, "upgrade-insecure-requests")
+	}
+
+	// делегирование подсказок клиента другим источникам не настроено
+	if nav.from != nil && nav.to != nil && !sameOrigin(nav.from, nav.to) {
+		for _, name := range highEntropyHeaders {
+			delete(headers, name)
+		}
+	}
+	return headers
+}
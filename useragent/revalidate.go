@@ -0,0 +1,74 @@
+// revalidate.go условные запросы к источникам версий: ETag и Last-Modified сохраняются в дисковом кэше,
+// и неизменившийся источник отвечает 304 без тела
+
+package useragent
+
+import (
+	"errors"
+	"maps"
+)
+
+// errNotModified источник ответил 304: данные не изменились с прошлого опроса
+var errNotModified = errors.New("источник не изменился с прошлого опроса")
+
+// sourceValidator валидаторы ответа источника и версии, полученные из этого ответа:
+// при 304 источник возвращает сохраненные версии, не загружая и не разбирая ответ заново
+type sourceValidator struct {
+	ETag         string   `json:"etag,omitempty"`
+	LastModified string   `json:"last_modified,omitempty"`
+	Versions     []string `json:"versions,omitempty"`
+}
+
+// conditional сообщает, что по валидатору можно отправить условный запрос
+func (v sourceValidator) conditional() bool {
+	return v.ETag != "" || v.LastModified != ""
+}
+
+// validatorFor возвращает сохраненный валидатор адреса источника (пустой, если условный запрос невозможен)
+func (g *Generator) validatorFor(url string) sourceValidator {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	v := g.validators[url]
+	if !v.conditional() || len(v.Versions) == 0 {
+		return sourceValidator{}
+	}
+	return v
+}
+
+// rememberValidator сохраняет валидаторы успешного ответа источника вместе с полученными из него версиями
+func (g *Generator) rememberValidator(url string, v sourceValidator, versions []string) {
+	if !v.conditional() {
+		return
+	}
+	v.Versions = versions
+	g.mu.Lock()
+	if g.validators == nil {
+		g.validators = make(map[string]sourceValidator)
+	}
+	g.validators[url] = v
+	g.mu.Unlock()
+}
+
+// loadValidators принимает валидаторы из дискового кэша, в том числе устаревшего: его версии не используются
+// напрямую, но источник может подтвердить их ответом 304
+func (g *Generator) loadValidators(validators map[string]sourceValidator) {
+	valid := make(map[string]sourceValidator, len(validators))
+	for url, v := range validators {
+		if v.conditional() && len(v.Versions) > 0 && allMatch(v.Versions, chromiumVersionRegex) {
+			valid[url] = v
+		}
+	}
+	g.mu.Lock()
+	g.validators = valid
+	g.mu.Unlock()
+}
+
+// cachedValidators возвращает копию валидаторов для записи в дисковый кэш
+func (g *Generator) cachedValidators() map[string]sourceValidator {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if len(g.validators) == 0 {
+		return nil
+	}
+	return maps.Clone(g.validators)
+}
@@ -0,0 +1,184 @@
+// rotator.go ротация User-Agent: бесконечные потоки строк и заголовков с выбранной политикой выдачи
+
+package useragent
+
+import (
+	"iter"
+	"sync"
+	"time"
+)
+
+// Rotation политика, по которой Rotator выбирает следующую строку User-Agent
+type Rotation string
+
+const (
+	// RotationRandom - каждая строка выбирается случайно и независимо, как в Get
+	RotationRandom Rotation = "random"
+	// RotationNoRepeat - строка не повторяется, пока не выданы все комбинации версии, браузера и платформы,
+	// затем пул перемешивается и цикл начинается заново (как WithNoRepeat, но со своим циклом у каждого Rotator)
+	RotationNoRepeat Rotation = "no-repeat"
+	// RotationRoundRobin - комбинации версии, браузера и платформы (см. ExportInventory) выдаются по кругу
+	// в постоянном порядке, без учета долей браузеров и платформ
+	RotationRoundRobin Rotation = "round-robin"
+	// RotationSticky - один и тот же браузер выдается в течение окна времени (по умолчанию 10 минут,
+	// другое окно задает NewStickyRotator), затем выбирается новый
+	RotationSticky Rotation = "sticky"
+	// RotationVersionAge - как RotationRandom, но старые версии браузера встречаются реже новых:
+	// доля версии уменьшается вдвое с каждой мажорной версией отставания от новейшей, как у реальной аудитории,
+	// которая обновляется в первые недели после релиза. доли браузеров и платформ сохраняются
+	RotationVersionAge Rotation = "version-age"
+)
+
+// defaultStickyWindow окно RotationSticky по умолчанию
+const defaultStickyWindow = 10 * time.Minute
+
+// Rotator источник строк User-Agent с политикой ротации: хранит состояние политики между вызовами,
+// поэтому один Rotator следует разделять между воркерами конвейера, а не создавать на каждый запрос.
+// строки выбираются из текущего пула версий генератора, обновления версий подхватываются сразу.
+//
+// Rotator безопасен для конкурентного использования.
+type Rotator struct {
+	g        *Generator
+	rotation Rotation
+
+	mu   sync.Mutex // состояние политики
+	deck *uaDeck    // колода RotationNoRepeat
+
+	pool   []string  // комбинации RotationRoundRobin в порядке выдачи
+	pos    int       // следующая позиция в pool
+	poolAt time.Time // versionsAt генератора на момент сборки pool
+
+	window  time.Duration // окно RotationSticky
+	session *Session      // браузер текущего окна RotationSticky, nil - еще не выбран
+	until   time.Time     // конец текущего окна RotationSticky
+
+	newest   map[string]int // новейшая мажорная версия по бренду для RotationVersionAge
+	newestAt time.Time      // versionsAt генератора на момент расчета newest
+}
+
+// NewRotator создает Rotator с политикой rotation, неизвестная политика заменяется на RotationRandom
+func (g *Generator) NewRotator(rotation Rotation) *Rotator {
+	rt := &Rotator{g: g, rotation: rotation}
+	switch rotation {
+	case RotationRandom, RotationRoundRobin, RotationVersionAge:
+	case RotationNoRepeat:
+		rt.deck = newUADeck()
+	case RotationSticky:
+		rt.window = defaultStickyWindow
+	default:
+		rt.rotation = RotationRandom
+	}
+	return rt
+}
+
+// NewStickyRotator создает Rotator с политикой RotationSticky и окном window: все строки и наборы заголовков
+// в течение окна принадлежат одному браузеру (с тем же экраном и железом, как у сессии).
+// окно не больше нуля заменяется окном по умолчанию (10 минут)
+func (g *Generator) NewStickyRotator(window time.Duration) *Rotator {
+	rt := g.NewRotator(RotationSticky)
+	if window > 0 {
+		rt.window = window
+	}
+	return rt
+}
+
+// Rotation возвращает политику ротации
+func (rt *Rotator) Rotation() Rotation {
+	return rt.rotation
+}
+
+// Next возвращает следующую строку User-Agent по политике ротации
+func (rt *Rotator) Next() string {
+	switch rt.rotation {
+	case RotationNoRepeat:
+		return rt.deck.draw(rt.g)
+	case RotationRoundRobin:
+		return rt.roundRobinUA()
+	case RotationSticky:
+		return rt.stickySession().UserAgent()
+	case RotationVersionAge:
+		return rt.versionAgeUA()
+	}
+	return rt.g.Get()
+}
+
+// UserAgents возвращает бесконечный поток строк User-Agent по политике ротации:
+// перебор прекращается только выходом из цикла range
+func (rt *Rotator) UserAgents() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for {
+			if !yield(rt.Next()) {
+				return
+			}
+		}
+	}
+}
+
+// Headers возвращает бесконечный поток наборов заголовков для запросов targetURL (как GetHeaders),
+// User-Agent каждого набора выбирается по политике ротации
+func (rt *Rotator) Headers(targetURL ...string) iter.Seq[map[string]string] {
+	return func(yield func(map[string]string) bool) {
+		for {
+			var headers map[string]string
+			if rt.rotation == RotationSticky {
+				headers = rt.stickySession().GetHeaders(targetURL...)
+			} else {
+				headers = rt.g.headersForUA(rt.Next(), targetURL...)
+			}
+			if !yield(headers) {
+				return
+			}
+		}
+	}
+}
+
+// roundRobinUA возвращает следующую комбинацию по кругу, после обновления версий круг собирается заново
+func (rt *Rotator) roundRobinUA() string {
+	rt.g.mu.RLock()
+	at := rt.g.versionsAt
+	rt.g.mu.RUnlock()
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.pool == nil || !rt.poolAt.Equal(at) {
+		entries := rt.g.inventory()
+		seen := make(map[string]struct{}, len(entries))
+		rt.pool, rt.pos, rt.poolAt = make([]string, 0, len(entries)), 0, at
+		for _, e := range entries {
+			if _, dup := seen[e.UserAgent]; !dup {
+				seen[e.UserAgent] = struct{}{}
+				rt.pool = append(rt.pool, e.UserAgent)
+			}
+		}
+	}
+	if len(rt.pool) == 0 {
+		return rt.g.Get()
+	}
+	ua := rt.pool[rt.pos]
+	rt.pos = (rt.pos + 1) % len(rt.pool)
+	return ua
+}
+
+// stickySession возвращает браузер текущего окна, по истечении окна выбирает новый
+func (rt *Rotator) stickySession() *Session {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if now := time.Now(); rt.session == nil || !now.Before(rt.until) {
+		rt.session, rt.until = rt.g.NewSession(), now.Add(rt.window)
+	}
+	return rt.session
+}
+
+// UserAgents возвращает бесконечный поток строк User-Agent с политикой rotation, например для конвейера,
+// который перебирает строки циклом range вместо вызовов Get под собственной блокировкой:
+//
+//	for ua := range gen.UserAgents(useragent.RotationRandom) {
+//		if !process(ua) {
+//			break
+//		}
+//	}
+//
+// каждый вызов создает отдельный Rotator, общее для нескольких потоков состояние дает NewRotator
+func (g *Generator) UserAgents(rotation Rotation) iter.Seq[string] {
+	return g.NewRotator(rotation).UserAgents()
+}
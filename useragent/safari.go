@@ -0,0 +1,132 @@
+// safari.go версии и заголовки Safari: таблица релизов Apple, аппроксимация и набор заголовков WebKit
+
+package useragent
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+const (
+	// шаблон User-Agent Safari: токены macOS (10_15_7) и WebKit (605.1.15) заморожены Apple,
+	// меняется только версия в Version/
+	safariUATemplate = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/%s Safari/605.1.15"
+
+	// количество последних версий Safari в пуле
+	safariVersionsInPool = 3
+
+	// интервал между минорными релизами Safari для аппроксимации (около 8 недель)
+	safariMinorCycle = 56 * 24 * time.Hour
+)
+
+// safariRelease релиз Safari для macOS
+type safariRelease struct {
+	Version  string
+	Released time.Time
+}
+
+// safariReleases поддерживаемая вручную таблица релизов Safari (https://developer.apple.com/documentation/safari-release-notes),
+// для дат после последней записи версии аппроксимируются
+var safariReleases = []safariRelease{
+	{Version: "17.6", Released: time.Date(2024, 7, 29, 0, 0, 0, 0, time.UTC)},
+	{Version: "18.0", Released: time.Date(2024, 9, 16, 0, 0, 0, 0, time.UTC)},
+	{Version: "18.1", Released: time.Date(2024, 10, 28, 0, 0, 0, 0, time.UTC)},
+	{Version: "18.2", Released: time.Date(2024, 12, 11, 0, 0, 0, 0, time.UTC)},
+	{Version: "18.3", Released: time.Date(2025, 1, 27, 0, 0, 0, 0, time.UTC)},
+	{Version: "18.4", Released: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)},
+	{Version: "18.5", Released: time.Date(2025, 5, 12, 0, 0, 0, 0, time.UTC)},
+	{Version: "18.6", Released: time.Date(2025, 7, 29, 0, 0, 0, 0, time.UTC)},
+	{Version: "26.0", Released: time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)},
+	{Version: "26.1", Released: time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)},
+	{Version: "26.2", Released: time.Date(2025, 12, 12, 0, 0, 0, 0, time.UTC)},
+}
+
+// WithSafariProbability включает генерацию User-Agent Safari для macOS с вероятностью p (от 0 до 1).
+// версии Safari берутся из встроенной таблицы релизов Apple и аппроксимируются для более поздних дат.
+// по умолчанию Safari не генерируется.
+func WithSafariProbability(p float64) Option {
+	return func(g *Generator) {
+		if math.IsNaN(p) {
+			return
+		}
+		g.safariProbability = min(max(p, 0), 1)
+	}
+}
+
+// safariEnabled сообщает, может ли генератор выдавать User-Agent Safari
+func (g *Generator) safariEnabled() bool {
+	return g.browserWeight(BrowserSafari) > 0
+}
+
+// randomSafariUA возвращает User-Agent Safari со случайной актуальной версией
+func randomSafariUA(r *rand.Rand) string {
+	versions := safariVersionsAt(time.Now())
+	version := versions[r.IntN(len(versions))]
+	return fmt.Sprintf(safariUATemplate, version)
+}
+
+// approximateSafariVersionForDate вычисляет версию Safari на дату: мажорная версия выходит в середине сентября
+// и с 2025 года совпадает с годом следующего релиза macOS (Safari 26 - сентябрь 2025),
+// минорные - примерно каждые 8 недель, до x.6
+func approximateSafariVersionForDate(d time.Time) string {
+	year := d.Year()
+	cycleStart := time.Date(year, 9, 15, 0, 0, 0, 0, time.UTC)
+	if d.Before(cycleStart) {
+		year--
+		cycleStart = time.Date(year, 9, 15, 0, 0, 0, 0, time.UTC)
+	}
+	minor := min(int(d.Sub(cycleStart)/safariMinorCycle), 6)
+	return fmt.Sprintf("%d.%d", year-1999, minor)
+}
+
+// safariVersionsAt возвращает последние версии Safari, вышедшие к дате d (от новых к старым)
+func safariVersionsAt(d time.Time) []string {
+	var versions []string
+	for _, r := range safariReleases {
+		if !r.Released.After(d) {
+			versions = append(versions, r.Version)
+		}
+	}
+
+	// после последней записи таблицы версии достраиваются аппроксимацией
+	last := safariReleases[len(safariReleases)-1].Released
+	for i := safariVersionsInPool - 1; i >= 0; i-- {
+		at := d.Add(-time.Duration(i) * safariMinorCycle)
+		if !at.After(last) {
+			continue
+		}
+		if v := approximateSafariVersionForDate(at); !slices.Contains(versions, v) {
+			versions = append(versions, v)
+		}
+	}
+
+	slices.Reverse(versions)
+	return versions[:min(safariVersionsInPool, len(versions))]
+}
+
+// safariHeaders генерирует набор заголовков, который отправляет Safari при навигации:
+// Safari не поддерживает User-Agent Client Hints и не отправляет sec-fetch-user и upgrade-insecure-requests
+func safariHeaders(fp fingerprint, nav navigation) map[string]string {
+	headers := map[string]string{
+		"user-agent":      fp.ua,
+		"accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+		"accept-language": fp.acceptLanguage,
+		"sec-fetch-dest":  "document",
+		"sec-fetch-mode":  "navigate",
+		"sec-fetch-site":  nav.secFetchSite(),
+		"priority":        "u=0, i",
+	}
+	if fp.encoding {
+		headers["accept-encoding"] = "gzip, deflate, br"
+	}
+	if referer := nav.referrer(); referer != "" {
+		headers["referer"] = referer
+	}
+	if nav.origin != "" {
+		headers["origin"] = nav.origin
+	}
+	return headers
+}
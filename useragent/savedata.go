@@ -0,0 +1,38 @@
+// savedata.go заголовок save-data: режим экономии трафика, который включает часть пользователей
+
+package useragent
+
+import (
+	"math"
+	"math/rand/v2"
+)
+
+// saveDataFactors во сколько раз чаще экономию трафика включают пользователи медленных соединений
+var saveDataFactors = map[string]float64{"4g": 1, "3g": 3, "2g": 5}
+
+// WithSaveData добавляет в заголовки Chrome и Edge "save-data: on" с вероятностью p (от 0 до 1):
+// так выглядят запросы пользователей с включенной экономией трафика. решение принимается один раз
+// для отпечатка, поэтому в сессии заголовок либо есть во всех запросах, либо его нет.
+// при включенных подсказках о качестве сети (WithNetworkHints) вероятность растет для медленных соединений:
+// для 3g она втрое, для 2g - впятеро выше p (но не больше 1). Firefox и Safari save-data не отправляют.
+// по умолчанию заголовок не отправляется.
+func WithSaveData(p float64) Option {
+	return func(g *Generator) {
+		if math.IsNaN(p) {
+			return
+		}
+		g.saveData = min(max(p, 0), 1)
+	}
+}
+
+// pickSaveData решает, включена ли у отпечатка экономия трафика, с учетом типа соединения ect
+func (g *Generator) pickSaveData(r *rand.Rand, ect string) bool {
+	if g.saveData <= 0 {
+		return false
+	}
+	p := g.saveData
+	if factor, ok := saveDataFactors[ect]; ok {
+		p = min(p*factor, 1)
+	}
+	return r.Float64() < p
+}
@@ -0,0 +1,174 @@
+// schedule.go фоновое обновление версий браузеров по расписанию
+
+package useragent
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheduleCheckInterval - период проверки наступления времени обновления:
+// проверка по "настенным" часам позволяет догнать пропущенное обновление после сна хоста,
+// когда монотонные таймеры Go стоят на паузе
+const scheduleCheckInterval = time.Minute
+
+// refreshSchedule описывает расписание обновления: ежедневно в hour:minute или ежечасно в minute
+type refreshSchedule struct {
+	hourly bool
+	hour   int
+	minute int
+}
+
+// parseRefreshSchedule разбирает расписание вида "daily@03:00" или "hourly@15"
+func parseRefreshSchedule(spec string) (refreshSchedule, error) {
+	kind, at, ok := strings.Cut(strings.TrimSpace(spec), "@")
+	if !ok {
+		return refreshSchedule{}, fmt.Errorf("неверный формат расписания %q, ожидается daily@HH:MM или hourly@MM", spec)
+	}
+	switch strings.ToLower(kind) {
+	case "daily":
+		t, err := time.Parse("15:04", at)
+		if err != nil {
+			return refreshSchedule{}, fmt.Errorf("неверное время в расписании %q: %w", spec, err)
+		}
+		return refreshSchedule{hour: t.Hour(), minute: t.Minute()}, nil
+	case "hourly":
+		m, err := strconv.Atoi(at)
+		if err != nil || m < 0 || m > 59 {
+			return refreshSchedule{}, fmt.Errorf("неверная минута в расписании %q", spec)
+		}
+		return refreshSchedule{hourly: true, minute: m}, nil
+	default:
+		return refreshSchedule{}, fmt.Errorf("неизвестный тип расписания %q, поддерживаются daily и hourly", kind)
+	}
+}
+
+// next возвращает ближайший момент обновления строго после from (в локальной зоне from)
+func (s refreshSchedule) next(from time.Time) time.Time {
+	if s.hourly {
+		t := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), s.minute, 0, 0, from.Location())
+		if !t.After(from) {
+			t = t.Add(time.Hour)
+		}
+		return t
+	}
+	t := time.Date(from.Year(), from.Month(), from.Day(), s.hour, s.minute, 0, 0, from.Location())
+	if !t.After(from) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// WithRefreshSchedule включает фоновое обновление версий по расписанию в локальном времени
+// (горутина обновления останавливается методом Close):
+//   - "daily@03:00" - ежедневно в 03:00
+//   - "hourly@15" - ежечасно в 15 минут
+//
+// jitter добавляет к каждому моменту обновления случайную задержку [0, jitter),
+// чтобы множество процессов не обращались к источникам одновременно.
+// если хост спал и пропустил запланированное время, обновление выполняется сразу после пробуждения.
+// при ошибке сети текущие версии сохраняются. неверное расписание игнорируется с предупреждением в логе.
+func WithRefreshSchedule(spec string, jitter time.Duration) Option {
+	return func(g *Generator) {
+		g.refreshSpec = spec
+		g.refreshJitter = max(jitter, 0)
+	}
+}
+
+// startRefreshSchedule разбирает расписание и запускает горутину фонового обновления
+func (g *Generator) startRefreshSchedule() {
+	if g.refreshSpec == "" {
+		return
+	}
+	schedule, err := parseRefreshSchedule(g.refreshSpec)
+	if err != nil {
+		g.logger.Warn("расписание обновления проигнорировано", "event", eventScheduleInvalid, "error", err)
+		return
+	}
+	g.background.Add(1)
+	go func() {
+		defer g.background.Done()
+		g.runRefreshSchedule(schedule)
+	}()
+}
+
+// runRefreshSchedule ожидает наступления запланированного времени и обновляет версии до вызова Close
+func (g *Generator) runRefreshSchedule(schedule refreshSchedule) {
+	due := g.nextRefresh(schedule, time.Now())
+	g.logger.Debug("запланировано обновление версий", "event", eventScheduleNext, "at", due)
+
+	ticker := time.NewTicker(scheduleCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-g.done:
+			return
+		case <-ticker.C:
+		}
+		// Round(0) отбрасывает монотонные показания: сравнение идёт по настенным часам
+		now := time.Now().Round(0)
+		if now.Before(due) {
+			continue
+		}
+		g.refresh()
+		due = g.nextRefresh(schedule, now)
+		g.logger.Debug("запланировано обновление версий", "event", eventScheduleNext, "at", due)
+	}
+}
+
+// nextRefresh вычисляет следующий момент обновления с учетом случайной задержки
+func (g *Generator) nextRefresh(schedule refreshSchedule, from time.Time) time.Time {
+	due := schedule.next(from.Round(0))
+	if g.refreshJitter > 0 {
+		due = due.Add(rand.N(g.refreshJitter))
+	}
+	return due
+}
+
+// Refresh немедленно обновляет версии из сетевых источников, независимо от расписания WithRefreshSchedule:
+// при ошибке источников текущие версии сохраняются и возвращается ошибка. версии Firefox (если он включен)
+// при ошибке аппроксимируются, а в строгом режиме WithStrictSources тоже сохраняются с ошибкой.
+// в офлайн-режиме и с закрепленными версиями WithStaticVersion или WithVersions всегда возвращает ошибку.
+func (g *Generator) Refresh() error {
+	return g.RefreshCtx(context.Background())
+}
+
+// RefreshCtx аналогичен Refresh, но запросы к источникам отменяются вместе с ctx,
+// а записи лога создаются с ctx (обработчик slog может взять из него идентификатор трассировки)
+func (g *Generator) RefreshCtx(ctx context.Context) error {
+	if g.versionsPinned() {
+		return errVersionsPinned
+	}
+	if g.offline {
+		return errSourcesOffline
+	}
+	versions, err := g.fetchVersions(ctx)
+	if err != nil {
+		return err
+	}
+	g.setVersions(versions, OriginNetwork)
+	g.logger.InfoContext(ctx, "версии браузеров обновлены из сети", "event", eventVersionsUpdated, "fallback", false)
+
+	var firefoxErr error
+	if g.firefoxEnabled() {
+		firefoxErr = g.updateFirefoxVersions(ctx)
+	}
+	if g.diskCachePath != "" {
+		g.saveToDiskCache()
+	}
+	return firefoxErr
+}
+
+// refresh выполняет запланированное обновление версий, сохраняя текущие при ошибке
+func (g *Generator) refresh() {
+	if g.offline || g.versionsPinned() {
+		return
+	}
+	if err := g.Refresh(); err != nil {
+		g.logger.Warn("фоновое обновление версий не удалось, текущие версии сохранены", "event", eventRefreshFailed, "error", err)
+	}
+}
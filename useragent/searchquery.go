@@ -0,0 +1,82 @@
+// searchquery.go поисковый запрос, по которому пользователь мог найти страницу: слова из адреса и название сайта
+
+package useragent
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxSearchTerms ограничивает число слов запроса: люди редко ищут длинными фразами
+const maxSearchTerms = 4
+
+// searchStopWords служебные части адресов, которые не попадают в поисковый запрос
+var searchStopWords = []string{
+	"index", "html", "htm", "php", "aspx", "jsp", "amp", "www", "item", "items", "page", "post", "posts",
+	"product", "products", "catalog", "category", "article", "articles", "view", "detail", "details", "id",
+	"en", "ru", "de", "fr", "us", "uk", "of", "the", "and", "for", "to", "in", "on", "на", "по", "для",
+}
+
+// secondLevelSuffixes метки составных доменных зон вида co.uk и com.au: название сайта стоит перед ними
+var secondLevelSuffixes = []string{"co", "com", "net", "org", "gov", "edu", "ac"}
+
+// searchQuery строит правдоподобный запрос для страницы to: слова последних значимых сегментов пути
+// (без идентификаторов, расширений и служебных слов) и название сайта. пусто - адрес неизвестен
+func searchQuery(to *url.URL) string {
+	if to == nil {
+		return ""
+	}
+	var terms []string
+	segments := strings.Split(strings.Trim(to.EscapedPath(), "/"), "/")
+	for i := len(segments) - 1; i >= 0 && len(terms) < maxSearchTerms-1; i-- {
+		words := searchWords(segments[i])
+		if len(words) == 0 {
+			continue
+		}
+		// слова более раннего сегмента (раздел сайта) идут перед словами более позднего
+		words = words[:min(len(words), maxSearchTerms-1-len(terms))]
+		terms = append(words, terms...)
+	}
+	if name := siteName(to); name != "" && !slices.Contains(terms, name) {
+		terms = append(terms, name)
+	}
+	return strings.Join(terms, " ")
+}
+
+// searchWords разбивает сегмент пути на слова в нижнем регистре: разделители - дефисы, подчеркивания,
+// точки и пробелы, слова без букв (идентификаторы, даты) и служебные слова отбрасываются
+func searchWords(segment string) []string {
+	if decoded, err := url.PathUnescape(segment); err == nil {
+		segment = decoded
+	}
+	fields := strings.FieldsFunc(strings.ToLower(segment), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+	words := fields[:0]
+	for _, w := range fields {
+		if len([]rune(w)) < 2 || !strings.ContainsFunc(w, unicode.IsLetter) || strings.ContainsFunc(w, unicode.IsDigit) {
+			continue
+		}
+		if slices.Contains(searchStopWords, w) || slices.Contains(words, w) {
+			continue
+		}
+		words = append(words, w)
+	}
+	return words
+}
+
+// siteName возвращает название сайта: метку перед доменной зоной ("example" для shop.example.org
+// и blog.example.co.uk), пусто для IP-адреса
+func siteName(u *url.URL) string {
+	labels := strings.Split(strings.ToLower(u.Hostname()), ".")
+	if len(labels) < 2 || !strings.ContainsFunc(labels[len(labels)-1], unicode.IsLetter) {
+		return ""
+	}
+	i := len(labels) - 2
+	if i > 0 && slices.Contains(secondLevelSuffixes, labels[i]) {
+		i--
+	}
+	return labels[i]
+}
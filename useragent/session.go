@@ -0,0 +1,215 @@
+// session.go сессии: один неизменный отпечаток браузера для серии запросов
+
+package useragent
+
+import (
+	"crypto/tls"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// sessionCacheSize ограничивает количество адресов в HTTP-кэше сессии
+	sessionCacheSize = 256
+
+	// sessionTLSCacheSize - количество TLS-сессий, которые сессия может возобновить
+	sessionTLSCacheSize = 64
+)
+
+// Session один "браузер", зафиксированный на всё время сессии: User-Agent, экран и вьюпорт,
+// железо и сеть выбираются один раз при создании, а заголовки каждого запроса
+// отличаются только тем, что зависит от перехода (referer, origin, sec-fetch-site).
+// реальный браузер не меняет разрешение экрана и версию от запроса к запросу, поэтому
+// для многошаговых сценариев (авторизация, пагинация) следует использовать одну сессию.
+//
+// сессия также хранит состояние "прогретого" браузера: кэш TLS-сессий для возобновления рукопожатия
+// (TLSSessionCache) и валидаторы HTTP-кэша (Observe), чтобы повторные визиты выглядели как
+// визиты браузера с кэшем, а не как холодный клиент.
+//
+// Session безопасна для конкурентного использования.
+type Session struct {
+	g       *Generator
+	fp      fingerprint
+	persona string // seed PersonaFromSeed, пусто для случайной сессии
+
+	tlsCache tls.ClientSessionCache // TLS-сессии для возобновления рукопожатия
+
+	mu    sync.Mutex
+	cache map[string]cacheValidators // валидаторы HTTP-кэша по адресу страницы
+	order []string                   // порядок добавления адресов в cache для вытеснения старых
+}
+
+// cacheValidators валидаторы закэшированного ответа для условного запроса
+type cacheValidators struct {
+	etag         string
+	lastModified string
+}
+
+// NewSession создает сессию со случайным отпечатком, выбранным по настройкам генератора
+func (g *Generator) NewSession() *Session {
+	return g.newSession(g.rng, g.Get())
+}
+
+// PersonaFromSeed создает сессию, отпечаток которой (User-Agent, экран, железо, сеть)
+// однозначно определяется строкой seed, например идентификатором аккаунта: разные процессы и машины
+// получают для одного seed одну и ту же личность без общего состояния.
+//
+// результат совпадает только при одинаковых настройках генератора и одинаковом пуле версий,
+// поэтому после обновления версий (или смены опций) личность для того же seed может измениться.
+func (g *Generator) PersonaFromSeed(seed string) *Session {
+	r := seededRand(seed)
+	g.mu.RLock()
+	ua := g.randomUA(r)
+	g.mu.RUnlock()
+	s := g.newSession(r, ua)
+	s.persona = seed
+	return s
+}
+
+// newSession создает сессию для строки User-Agent, выбирая остальные характеристики из r
+func (g *Generator) newSession(r *rand.Rand, ua string) *Session {
+	return &Session{
+		g:        g,
+		fp:       g.newFingerprint(r, ua),
+		tlsCache: tls.NewLRUClientSessionCache(sessionTLSCacheSize),
+		cache:    make(map[string]cacheValidators),
+	}
+}
+
+// UserAgent возвращает строку User-Agent сессии
+func (s *Session) UserAgent() string {
+	return s.fp.ua
+}
+
+// GetHeaders аналогичен Generator.GetHeaders, но всегда возвращает заголовки одного и того же браузера
+func (s *Session) GetHeaders(targetURL ...string) map[string]string {
+	return s.headersFor(s.g.navigationFor(targetURL...))
+}
+
+// GetHeadersWithPolicy аналогичен Generator.GetHeadersWithPolicy для браузера сессии
+func (s *Session) GetHeadersWithPolicy(policy ReferrerPolicy, targetURL ...string) map[string]string {
+	nav := s.g.navigationFor(targetURL...)
+	if policy != "" {
+		nav.policy = policy
+	}
+	return s.headersFor(nav)
+}
+
+// GetNavigationHeaders аналогичен Generator.GetNavigationHeaders для браузера сессии
+func (s *Session) GetNavigationHeaders(fromURL, toURL string) map[string]string {
+	return s.headersFor(s.g.linkNavigation("", fromURL, toURL))
+}
+
+// GetNavigationHeadersWithPolicy аналогичен Generator.GetNavigationHeadersWithPolicy для браузера сессии
+func (s *Session) GetNavigationHeadersWithPolicy(policy ReferrerPolicy, fromURL, toURL string) map[string]string {
+	return s.headersFor(s.g.linkNavigation(policy, fromURL, toURL))
+}
+
+// GetHeadersFor аналогичен Generator.GetHeadersFor для браузера сессии:
+// позволяет получить заголовки всех запросов при загрузке страницы одним и тем же браузером
+func (s *Session) GetHeadersFor(resource ResourceType, pageURL, resourceURL string) map[string]string {
+	return s.headersFor(s.g.resourceNavigation("", resource, pageURL, resourceURL))
+}
+
+// GetHeadersForWithPolicy аналогичен Generator.GetHeadersForWithPolicy для браузера сессии
+func (s *Session) GetHeadersForWithPolicy(policy ReferrerPolicy, resource ResourceType, pageURL, resourceURL string) map[string]string {
+	return s.headersFor(s.g.resourceNavigation(policy, resource, pageURL, resourceURL))
+}
+
+// headersFor генерирует заголовки браузера сессии с учетом её HTTP-кэша
+func (s *Session) headersFor(nav navigation) map[string]string {
+	headers := requestHeaders(s.fp, nav)
+	s.applyCache(nav.to, headers)
+	return s.g.transformHeaders(headers)
+}
+
+// TLSSessionCache возвращает кэш TLS-сессий персоны для tls.Config.ClientSessionCache:
+// браузер возобновляет TLS-сессию при повторном подключении к уже посещенному хосту,
+// и клиент с общим для сессии кэшем ведет себя так же
+//
+//	transport := &http.Transport{TLSClientConfig: &tls.Config{ClientSessionCache: s.TLSSessionCache()}}
+func (s *Session) TLSSessionCache() tls.ClientSessionCache {
+	return s.tlsCache
+}
+
+// Observe запоминает валидаторы ответа (ETag, Last-Modified), полученного с заголовками этой сессии:
+// при следующем запросе того же адреса GetHeaders добавит If-None-Match / If-Modified-Since,
+// как браузер, проверяющий актуальность своего кэша (сервер ответит 304 Not Modified).
+// ответы с Cache-Control: no-store не кэшируются, ошибки сервера удаляют адрес из кэша.
+func (s *Session) Observe(resp *http.Response) {
+	if resp == nil || resp.Request == nil || resp.Request.URL == nil {
+		return
+	}
+	key := cacheKey(resp.Request.URL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		// кэш актуален, валидаторы не меняются
+	case resp.StatusCode == http.StatusOK && !strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store"):
+		v := cacheValidators{etag: resp.Header.Get("ETag"), lastModified: resp.Header.Get("Last-Modified")}
+		if v.etag == "" && v.lastModified == "" {
+			s.forget(key)
+			return
+		}
+		if _, ok := s.cache[key]; !ok {
+			s.order = append(s.order, key)
+		}
+		s.cache[key] = v
+		if len(s.order) > sessionCacheSize {
+			delete(s.cache, s.order[0])
+			s.order = s.order[1:]
+		}
+	default:
+		s.forget(key)
+	}
+}
+
+// forget удаляет адрес из HTTP-кэша, вызывается под s.mu
+func (s *Session) forget(key string) {
+	if _, ok := s.cache[key]; !ok {
+		return
+	}
+	delete(s.cache, key)
+	for i, k := range s.order {
+		if k == key {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// applyCache добавляет условные заголовки для закэшированного адреса: при повторном визите
+// браузер не отправляет cache-control: no-cache, а проверяет свой кэш валидаторами
+func (s *Session) applyCache(to *url.URL, headers map[string]string) {
+	if to == nil {
+		return
+	}
+	s.mu.Lock()
+	v, ok := s.cache[cacheKey(to)]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	delete(headers, "cache-control")
+	delete(headers, "pragma")
+	if v.etag != "" {
+		headers["if-none-match"] = v.etag
+	}
+	if v.lastModified != "" {
+		headers["if-modified-since"] = v.lastModified
+	}
+}
+
+// cacheKey адрес без фрагмента, как ключ HTTP-кэша браузера
+func cacheKey(u *url.URL) string {
+	stripped := *u
+	stripped.Fragment = ""
+	stripped.RawFragment = ""
+	return stripped.String()
+}
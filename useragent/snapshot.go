@@ -0,0 +1,45 @@
+// snapshot.go встроенный снимок версий браузеров для сборки без сети (тег nonet)
+
+package useragent
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// snapshotMaxAge возраст снимка, после которого он уступает аппроксимации по дате:
+// за два релизных цикла Chromium снимок отстает от настоящих браузеров на две мажорные версии
+const snapshotMaxAge = 8 * 7 * 24 * time.Hour
+
+// loadSnapshot применяет встроенный снимок версий, если он есть в сборке и не устарел,
+// и сообщает, удалось ли это. снимок проверяется так же, как дисковый кэш
+func (g *Generator) loadSnapshot() bool {
+	if len(versionsSnapshotJSON) == 0 {
+		return false
+	}
+	var snapshot cacheFile
+	if err := json.Unmarshal(versionsSnapshotJSON, &snapshot); err != nil || len(snapshot.Versions) == 0 ||
+		!allMatch(snapshot.Versions, chromiumVersionRegex) || !allMatch(snapshot.Edge, chromiumVersionRegex) ||
+		!allMatch(snapshot.Firefox, firefoxVersionRegex) {
+		g.logger.Warn("встроенный снимок версий поврежден и проигнорирован", "event", eventSnapshotInvalid)
+		return false
+	}
+	if time.Since(snapshot.Timestamp) > snapshotMaxAge {
+		g.logger.Debug("встроенный снимок версий устарел, используется аппроксимация", "event", eventSnapshotExpired,
+			"snapshot_at", snapshot.Timestamp)
+		return false
+	}
+
+	g.setVersions(snapshot.Versions, OriginSnapshot)
+	g.mu.Lock()
+	g.versionsAt = snapshot.Timestamp
+	if len(snapshot.Firefox) > 0 {
+		g.firefoxVersions = snapshot.Firefox
+	}
+	if len(snapshot.Edge) > 0 {
+		g.edgeVersions = snapshot.Edge
+	}
+	g.mu.Unlock()
+	g.logger.Debug("версии загружены из встроенного снимка", "event", eventSnapshotLoaded, "snapshot_at", snapshot.Timestamp)
+	return true
+}
@@ -0,0 +1,214 @@
+// sources.go описывает сетевые источники версий браузеров и их зеркала
+
+package useragent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// registryCacheFileTemplate шаблон имени файла кэша удаленного реестра источников (с хэшем адреса)
+const registryCacheFileTemplate = "go_ua_sources_%x.json"
+
+// Source определяет сетевой источник версий браузеров
+type Source int
+
+const (
+	// SourceGoogle - официальный Google Versions API (версии Chrome)
+	SourceGoogle Source = iota
+	// SourceMicrosoft - репозиторий пакетов Microsoft Edge
+	SourceMicrosoft
+	// SourceMozilla - Mozilla product-details (версии Firefox)
+	SourceMozilla
+)
+
+// String возвращает человекочитаемое имя источника для логов
+func (s Source) String() string {
+	switch s {
+	case SourceGoogle:
+		return "Google API"
+	case SourceMicrosoft:
+		return "Microsoft Repo"
+	case SourceMozilla:
+		return "Mozilla product-details"
+	default:
+		return "unknown"
+	}
+}
+
+// canonicalURL возвращает основной адрес источника
+func (s Source) canonicalURL() string {
+	switch s {
+	case SourceGoogle:
+		return googleAPIURL
+	case SourceMicrosoft:
+		return msEdgeRepoURL
+	case SourceMozilla:
+		return mozillaProductDetailsURL
+	default:
+		return ""
+	}
+}
+
+// WithMirrors добавляет адреса зеркал для указанного источника:
+// они опрашиваются по порядку после основного адреса, если тот недоступен.
+// зеркало должно отдавать ответ в том же формате, что и оригинальный источник
+// (например, сохранённый JSON Google API или копия страницы репозитория Microsoft).
+func WithMirrors(source Source, urls ...string) Option {
+	return func(g *Generator) {
+		if g.mirrors == nil {
+			g.mirrors = make(map[Source][]string)
+		}
+		for _, u := range urls {
+			if u != "" {
+				g.mirrors[source] = append(g.mirrors[source], u)
+			}
+		}
+	}
+}
+
+// WithSourceRegistry загружает адреса сетевых источников версий из небольшого JSON-реестра, чтобы при переезде
+// или переименовании API Google, Microsoft или Mozilla достаточно было обновить файл данных, не дожидаясь релиза пакета.
+//
+// location - путь к локальному файлу или адрес https://, удаленный реестр кэшируется в личном каталоге кэша
+// пользователя на время ttl (с WithCacheSigningKey - с подписью). реестр определяет, откуда генератор берет версии,
+// поэтому адрес http:// без TLS отклоняется. адреса из реестра заменяют встроенный основной адрес источника и опрашиваются по порядку,
+// зеркала WithMirrors - после них. формат реестра:
+//
+//	{
+//	  "sources": {
+//	    "google": ["https://versionhistory.googleapis.com/v1/chrome/platforms/win64/channels/stable/versions/all/releases"],
+//	    "microsoft": ["https://packages.microsoft.com/repos/edge/pool/main/m/microsoft-edge-stable"],
+//	    "mozilla": ["https://product-details.mozilla.org/1.0/firefox_history_major_releases.json"]
+//	  }
+//	}
+//
+// отсутствующие источники сохраняют встроенные адреса, при ошибке загрузки или неверном адресе в реестре
+// реестр целиком игнорируется.
+func WithSourceRegistry(location string, ttl time.Duration) Option {
+	return func(g *Generator) {
+		g.registryLocation = location
+		g.registryTTL = ttl
+	}
+}
+
+// sourceRegistry формат реестра источников
+type sourceRegistry struct {
+	Sources map[string][]string `json:"sources"`
+}
+
+// registryNames имена источников в реестре
+var registryNames = map[string]Source{
+	"google":    SourceGoogle,
+	"microsoft": SourceMicrosoft,
+	"mozilla":   SourceMozilla,
+}
+
+// loadSourceRegistry загружает реестр, указанный в WithSourceRegistry, и заменяет им основные адреса источников
+func (g *Generator) loadSourceRegistry() {
+	if g.registryLocation == "" {
+		return
+	}
+	if strings.HasPrefix(g.registryLocation, "http://") {
+		g.logger.Warn("реестр источников по адресу http:// без TLS отклонен, используются встроенные адреса",
+			"event", eventRegistryFailed, "location", g.registryLocation)
+		return
+	}
+	data, err := g.readDataFile(g.registryLocation, g.registryTTL, registryCacheFileTemplate)
+	if err != nil {
+		g.logger.Warn("не удалось загрузить реестр источников, используются встроенные адреса",
+			"event", eventRegistryFailed, "location", g.registryLocation, "error", err)
+		return
+	}
+	overrides, err := parseSourceRegistry(data)
+	if err != nil {
+		g.logger.Warn("реестр источников отклонен, используются встроенные адреса",
+			"event", eventRegistryFailed, "location", g.registryLocation, "error", err)
+		return
+	}
+	g.sourceOverrides = overrides
+	g.logger.Debug("загружен реестр источников", "event", eventRegistryLoaded, "location", g.registryLocation)
+}
+
+// parseSourceRegistry разбирает и проверяет реестр источников
+func parseSourceRegistry(data []byte) (map[Source][]string, error) {
+	var registry sourceRegistry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, err
+	}
+	overrides := make(map[Source][]string)
+	for name, urls := range registry.Sources {
+		source, ok := registryNames[name]
+		if !ok {
+			return nil, fmt.Errorf("неизвестный источник в реестре: %q", name)
+		}
+		for _, raw := range urls {
+			u, err := url.Parse(raw)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("неверный адрес источника %s в реестре: %q", name, raw)
+			}
+		}
+		if len(urls) > 0 {
+			overrides[source] = urls
+		}
+	}
+	return overrides, nil
+}
+
+// sourceURLs возвращает основной адрес источника (или адреса из реестра) и его зеркала в порядке опроса
+func (g *Generator) sourceURLs(source Source) []string {
+	primary := g.sourceOverrides[source]
+	if len(primary) == 0 {
+		primary = []string{source.canonicalURL()}
+	}
+	urls := make([]string, 0, len(primary)+len(g.mirrors[source]))
+	urls = append(urls, primary...)
+	return append(urls, g.mirrors[source]...)
+}
+
+// requestTimeout срок одного запроса к адресу источника: таймаут HTTP-клиента, без него - минута
+func (g *Generator) requestTimeout() time.Duration {
+	if g.httpClient.Timeout <= 0 {
+		return time.Minute
+	}
+	return g.httpClient.Timeout
+}
+
+// sourcesTimeout общий срок опроса источников sources: каждый адрес (основной и зеркала) получает
+// свой requestTimeout, поэтому недоступный основной адрес не отнимает время у зеркал
+func (g *Generator) sourcesTimeout(sources ...Source) time.Duration {
+	attempts := 1
+	for _, source := range sources {
+		attempts = max(attempts, len(g.sourceURLs(source)))
+	}
+	return time.Duration(attempts) * g.requestTimeout()
+}
+
+// fetchWithMirrors последовательно опрашивает адреса источника до первого успешного ответа,
+// каждый адрес - в пределах собственного requestTimeout
+func (g *Generator) fetchWithMirrors(
+	ctx context.Context, source Source, fetch func(ctx context.Context, url string) ([]string, error),
+) ([]string, error) {
+	var errs []error
+	for _, url := range g.sourceURLs(source) {
+		attemptCtx, cancel := context.WithTimeout(ctx, g.requestTimeout())
+		versions, err := fetch(attemptCtx, url)
+		cancel()
+		if err == nil {
+			return versions, nil
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			// общий контекст отменён: остальные зеркала опрашивать бессмысленно,
+			// истекший срок отдельной попытки перебор не останавливает
+			break
+		}
+		g.logger.Debug("адрес источника недоступен, попытка следующего…", "event", eventSourceMirrorFailed, "source", source.String(), "url", url, "error", err)
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,87 @@
+// static.go закрепленные версии Chrome/Edge: генератор не обращается ни к сети, ни к кэшу, ни к аппроксимации
+
+package useragent
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// errVersionsPinned версии закреплены опцией и не обновляются
+var errVersionsPinned = errors.New("версии Chrome/Edge закреплены WithStaticVersion или WithVersions и не обновляются")
+
+// WithStaticVersion закрепляет генератор за одной точной версией Chromium ("142.0.7444.60"): сетевые источники,
+// дисковый кэш и аппроксимация для Chrome/Edge не используются, а User-Agent, sec-ch-ua-full-version
+// и TLS-отпечаток всегда соответствуют этой сборке - например, чтобы совпасть с реальным браузером,
+// который используется в другой части системы.
+//
+// edgeBuild - собственная сборка Edge той же мажорной версии ("142.0.3595.53"), без нее бренд Edge
+// получает версию Chromium. версия неверного формата игнорируется, как и сборка Edge другой мажорной версии.
+// Refresh, расписание WithRefreshSchedule и ImportVersions закрепленные версии не меняют.
+// версии Firefox (если он включен) получаются как обычно.
+func WithStaticVersion(version string, edgeBuild ...string) Option {
+	return func(g *Generator) {
+		if !chromiumVersionRegex.MatchString(version) {
+			return
+		}
+		major, _, _ := strings.Cut(version, ".")
+		var edge []string
+		for _, build := range edgeBuild {
+			if chromiumVersionRegex.MatchString(build) && strings.HasPrefix(build, major+".") {
+				edge = append(edge, build)
+			}
+		}
+		g.pinnedVersions, g.pinnedEdge = []string{version}, edge
+	}
+}
+
+// WithVersions задает собственный набор версий Chromium ("142.0.7444.60"), например из внутреннего
+// источника истины: как и с WithStaticVersion, сетевые источники, дисковый кэш и аппроксимация для Chrome/Edge
+// не используются, а User-Agent и заголовки генерируются по этому набору. набор копируется, повторы удаляются,
+// версии упорядочиваются от новых к старым; версии неверного формата отбрасываются, а набор без единой
+// верной версии игнорируется. Edge получает версии Chromium (собственные сборки Edge - см. WithStaticVersion).
+// Refresh, расписание WithRefreshSchedule и ImportVersions заданный набор не меняют.
+func WithVersions(versions []string) Option {
+	return func(g *Generator) {
+		var valid []string
+		for _, v := range versions {
+			if chromiumVersionRegex.MatchString(v) && !slices.Contains(valid, v) {
+				valid = append(valid, v)
+			}
+		}
+		if len(valid) == 0 {
+			return
+		}
+		slices.SortStableFunc(valid, func(a, b string) int { return compareChromiumVersions(b, a) })
+		g.pinnedVersions, g.pinnedEdge = valid, nil
+	}
+}
+
+// compareChromiumVersions сравнивает версии Chromium по числовым компонентам
+func compareChromiumVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range min(len(as), len(bs)) {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if c := cmp.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
+
+// versionsPinned сообщает, что версии Chrome/Edge закреплены и источники версий не используются
+func (g *Generator) versionsPinned() bool {
+	return len(g.pinnedVersions) > 0
+}
+
+// applyPinnedVersions устанавливает закрепленные версии
+func (g *Generator) applyPinnedVersions() {
+	g.setEdgeVersions(g.pinnedEdge)
+	g.setVersions(g.pinnedVersions, OriginStatic)
+	g.logger.Debug("версии Chrome/Edge закреплены, источники версий не используются",
+		"event", eventVersionsPinned, "versions", g.pinnedVersions, "edge_versions", g.pinnedEdge)
+}
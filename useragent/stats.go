@@ -0,0 +1,39 @@
+// stats.go сведения о состоянии генератора для мониторинга долгоживущих процессов
+
+package useragent
+
+import "time"
+
+// Stats снимок состояния генератора: свежесть версий и результат последнего обращения к сети
+type Stats struct {
+	Versions       int       // количество версий Chrome/Edge в текущем наборе
+	VersionsOrigin string    // источник текущего набора: OriginCache, OriginNetwork, OriginApproximation, OriginImport, OriginStatic или OriginSnapshot
+	VersionsAt     time.Time // момент получения набора (для кэша и импорта - момент создания кэша или экспорта)
+	LastFetchAt    time.Time // момент завершения последнего опроса сетевых источников, нулевой - опросов не было
+	LastFetchError string    // ошибка последнего опроса, пусто - опрос успешен или не выполнялся
+}
+
+// Stats возвращает снимок состояния генератора: позволяет следить из мониторинга за тем, что версии
+// не устарели, а фоновое обновление WithRefreshSchedule не завершается ошибкой неделями.
+// возраст набора версий - time.Since(Stats().VersionsAt)
+func (g *Generator) Stats() Stats {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	s := Stats{
+		Versions:       len(g.versions),
+		VersionsOrigin: g.versionsOrigin,
+		VersionsAt:     g.versionsAt,
+		LastFetchAt:    g.lastFetchAt,
+	}
+	if g.lastFetchErr != nil {
+		s.LastFetchError = g.lastFetchErr.Error()
+	}
+	return s
+}
+
+// recordFetch запоминает результат опроса сетевых источников для Stats
+func (g *Generator) recordFetch(err error) {
+	g.mu.Lock()
+	g.lastFetchAt, g.lastFetchErr = time.Now(), err
+	g.mu.Unlock()
+}
@@ -0,0 +1,86 @@
+// subscribe.go уведомления подписчиков об изменении набора версий браузеров
+
+package useragent
+
+import (
+	"slices"
+	"time"
+)
+
+// источники набора версий в VersionsUpdate.Origin
+const (
+	OriginCache         = "cache"         // версии загружены из дискового кэша
+	OriginNetwork       = "network"       // версии получены из сетевых источников
+	OriginApproximation = "approximation" // версии аппроксимированы по дате
+	OriginImport        = "import"        // версии приняты от другого процесса через ImportVersions
+	OriginStatic        = "static"        // версии закреплены WithStaticVersion или WithVersions
+	OriginSnapshot      = "snapshot"      // версии из снимка, встроенного в сборку с тегом nonet
+)
+
+// VersionsUpdate уведомление об изменении набора версий браузеров
+type VersionsUpdate struct {
+	Versions []string  // новый набор версий (копия, может изменяться получателем)
+	Origin   string    // откуда получены версии: OriginCache, OriginNetwork, OriginApproximation, OriginImport, OriginStatic или OriginSnapshot
+	At       time.Time // момент изменения
+}
+
+// Subscribe возвращает канал, в который приходят уведомления при изменении набора версий:
+// позволяет зависимым компонентам (пулы персон, кэши TLS-профилей) сбрасывать производное состояние.
+//
+// канал буферизован на одно сообщение: если получатель не успевает читать,
+// в канале остается только самое свежее уведомление. для отписки используйте Unsubscribe.
+func (g *Generator) Subscribe() <-chan VersionsUpdate {
+	ch := make(chan VersionsUpdate, 1)
+	g.subsMu.Lock()
+	g.subscribers = append(g.subscribers, ch)
+	g.subsMu.Unlock()
+	return ch
+}
+
+// Unsubscribe отписывает канал, полученный из Subscribe, и закрывает его
+// (после Close каналы всех подписчиков уже закрыты)
+func (g *Generator) Unsubscribe(ch <-chan VersionsUpdate) {
+	g.subsMu.Lock()
+	defer g.subsMu.Unlock()
+	for i, sub := range g.subscribers {
+		if sub == ch {
+			g.subscribers = slices.Delete(g.subscribers, i, i+1)
+			close(sub)
+			return
+		}
+	}
+}
+
+// setVersions атомарно заменяет набор версий и уведомляет подписчиков, если он изменился
+func (g *Generator) setVersions(versions []string, origin string) {
+	now := time.Now()
+	g.mu.Lock()
+	changed := !slices.Equal(g.versions, versions)
+	g.versions = versions
+	g.versionsOrigin, g.versionsAt = origin, now
+	g.mu.Unlock()
+
+	if changed {
+		g.notify(VersionsUpdate{Origin: origin, At: now}, versions)
+	}
+}
+
+// notify неблокирующе рассылает уведомление всем подписчикам
+func (g *Generator) notify(update VersionsUpdate, versions []string) {
+	g.subsMu.Lock()
+	defer g.subsMu.Unlock()
+	for _, sub := range g.subscribers {
+		u := update
+		u.Versions = slices.Clone(versions)
+		select {
+		case sub <- u:
+		default:
+			// получатель не успевает: устаревшее уведомление заменяется свежим
+			select {
+			case <-sub:
+			default:
+			}
+			sub <- u
+		}
+	}
+}
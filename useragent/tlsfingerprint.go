@@ -0,0 +1,185 @@
+// tlsfingerprint.go описание TLS ClientHello (JA3/JA4), соответствующего версии Chrome/Edge из User-Agent
+
+package useragent
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TLSFingerprint параметры TLS ClientHello браузера (без значений GREASE) и вычисленные по ним отпечатки JA3 и JA4:
+// позволяет настроить собственный TLS-стек (например, uTLS) так, чтобы ClientHello соответствовал User-Agent.
+type TLSFingerprint struct {
+	TLSVersion          uint16   // legacy_version в ClientHello (771 - TLS 1.2)
+	CipherSuites        []uint16 // наборы шифров в порядке браузера
+	Extensions          []uint16 // расширения в каноническом порядке (см. ExtensionsShuffled)
+	SupportedGroups     []uint16 // группы ключевого обмена, включая постквантовую гибридную
+	PointFormats        []uint8  // форматы точек эллиптических кривых
+	SignatureAlgorithms []uint16 // алгоритмы подписи в порядке браузера
+	ALPN                []string // протоколы прикладного уровня
+
+	// ExtensionsShuffled - браузер перемешивает порядок расширений в каждом ClientHello (Chrome 110+),
+	// поэтому JA3 меняется от соединения к соединению, а JA4 (с сортировкой расширений) стабилен
+	ExtensionsShuffled bool
+
+	JA3     string // строка JA3 для канонического порядка расширений
+	JA3Hash string // MD5 строки JA3
+	JA4     string // отпечаток JA4 (без расширения pre_shared_key, то есть для первого соединения)
+}
+
+// коды расширений TLS (https://www.iana.org/assignments/tls-extensiontype-values)
+const (
+	tlsExtServerName           uint16 = 0
+	tlsExtStatusRequest        uint16 = 5
+	tlsExtSupportedGroups      uint16 = 10
+	tlsExtECPointFormats       uint16 = 11
+	tlsExtSignatureAlgorithms  uint16 = 13
+	tlsExtALPN                 uint16 = 16
+	tlsExtSCT                  uint16 = 18
+	tlsExtExtendedMasterSecret uint16 = 23
+	tlsExtCompressCertificate  uint16 = 27
+	tlsExtSessionTicket        uint16 = 35
+	tlsExtSupportedVersions    uint16 = 43
+	tlsExtPSKKeyExchangeModes  uint16 = 45
+	tlsExtKeyShare             uint16 = 51
+	tlsExtALPSOld              uint16 = 17513 // application_settings до Chrome 133
+	tlsExtALPS                 uint16 = 17613 // application_settings с Chrome 133
+	tlsExtECH                  uint16 = 65037 // encrypted_client_hello (GREASE ECH)
+	tlsExtRenegotiationInfo    uint16 = 65281
+)
+
+// группы ключевого обмена
+const (
+	tlsGroupX25519MLKEM768 uint16 = 4588  // Chrome 131+
+	tlsGroupX25519Kyber768 uint16 = 25497 // Chrome 124-130
+	tlsGroupX25519         uint16 = 29
+	tlsGroupSecp256r1      uint16 = 23
+	tlsGroupSecp384r1      uint16 = 24
+)
+
+const (
+	tlsVersion12 uint16 = 771  // legacy_version ClientHello
+	tlsVersion13        = "13" // максимальная версия в supported_versions для JA4
+
+	// версии Chrome, в которых менялся ClientHello
+	chromeTLSFingerprintBase = 117 // первая версия с GREASE ECH, более ранние не поддерживаются
+	chromeKyberMajor         = 124
+	chromeMLKEMMajor         = 131
+	chromeNewALPSCodepoint   = 133
+)
+
+// chromeCipherSuites наборы шифров Chrome (без GREASE), не менялись с Chrome 83
+var chromeCipherSuites = []uint16{4865, 4866, 4867, 49195, 49199, 49196, 49200, 52393, 52392, 49171, 49172, 156, 157, 47, 53}
+
+// chromeSignatureAlgorithms алгоритмы подписи Chrome
+var chromeSignatureAlgorithms = []uint16{1027, 2052, 1025, 1283, 2053, 1281, 2054, 1537}
+
+// TLSFingerprintFor возвращает параметры TLS ClientHello, соответствующие строке User-Agent Chrome или Edge
+// (Edge использует TLS-стек Chromium той же версии). для других браузеров и версий Chrome старше 117
+// возвращается false. учитываются изменения ClientHello по версиям: постквантовый обмен ключами
+// (X25519Kyber768 в 124-130, X25519MLKEM768 с 131) и новый код расширения ALPS с 133.
+func TLSFingerprintFor(ua string) (TLSFingerprint, bool) {
+	info := parseUserAgent(ua)
+	if info.BrandName != "Google Chrome" && info.BrandName != "Microsoft Edge" {
+		return TLSFingerprint{}, false
+	}
+	major := info.major()
+	if major < chromeTLSFingerprintBase {
+		return TLSFingerprint{}, false
+	}
+
+	alps := tlsExtALPS
+	if major < chromeNewALPSCodepoint {
+		alps = tlsExtALPSOld
+	}
+	groups := []uint16{tlsGroupX25519, tlsGroupSecp256r1, tlsGroupSecp384r1}
+	switch {
+	case major >= chromeMLKEMMajor:
+		groups = slices.Insert(groups, 0, tlsGroupX25519MLKEM768)
+	case major >= chromeKyberMajor:
+		groups = slices.Insert(groups, 0, tlsGroupX25519Kyber768)
+	}
+
+	fp := TLSFingerprint{
+		TLSVersion:   tlsVersion12,
+		CipherSuites: slices.Clone(chromeCipherSuites),
+		Extensions: []uint16{
+			tlsExtServerName, tlsExtExtendedMasterSecret, tlsExtRenegotiationInfo, tlsExtSupportedGroups,
+			tlsExtECPointFormats, tlsExtSessionTicket, tlsExtALPN, tlsExtStatusRequest, tlsExtSignatureAlgorithms,
+			tlsExtSCT, tlsExtKeyShare, tlsExtPSKKeyExchangeModes, tlsExtSupportedVersions, tlsExtCompressCertificate,
+			alps, tlsExtECH,
+		},
+		SupportedGroups:     groups,
+		PointFormats:        []uint8{0},
+		SignatureAlgorithms: slices.Clone(chromeSignatureAlgorithms),
+		ALPN:                []string{"h2", "http/1.1"},
+		ExtensionsShuffled:  true,
+	}
+	fp.JA3 = fp.ja3()
+	sum := md5.Sum([]byte(fp.JA3))
+	fp.JA3Hash = hex.EncodeToString(sum[:])
+	fp.JA4 = fp.ja4()
+	return fp, true
+}
+
+// TLSFingerprint возвращает параметры TLS ClientHello для User-Agent сессии (см. TLSFingerprintFor)
+func (s *Session) TLSFingerprint() (TLSFingerprint, bool) {
+	return TLSFingerprintFor(s.fp.ua)
+}
+
+// ja3 строит строку JA3: версия,шифры,расширения,группы,форматы точек
+func (fp TLSFingerprint) ja3() string {
+	points := make([]uint16, len(fp.PointFormats))
+	for i, p := range fp.PointFormats {
+		points[i] = uint16(p)
+	}
+	return strings.Join([]string{
+		strconv.Itoa(int(fp.TLSVersion)),
+		joinCodes(fp.CipherSuites, "%d", "-"),
+		joinCodes(fp.Extensions, "%d", "-"),
+		joinCodes(fp.SupportedGroups, "%d", "-"),
+		joinCodes(points, "%d", "-"),
+	}, ",")
+}
+
+// ja4 строит отпечаток JA4 (https://github.com/FoxIO-LLC/ja4): TCP, TLS 1.3, с SNI
+func (fp TLSFingerprint) ja4() string {
+	alpn := "00"
+	if len(fp.ALPN) > 0 && fp.ALPN[0] != "" {
+		first := fp.ALPN[0]
+		alpn = first[:1] + first[len(first)-1:]
+	}
+	a := fmt.Sprintf("t%sd%02d%02d%s", tlsVersion13, min(len(fp.CipherSuites), 99), min(len(fp.Extensions), 99), alpn)
+
+	ciphers := slices.Sorted(slices.Values(fp.CipherSuites))
+	b := truncatedSHA256(joinCodes(ciphers, "%04x", ","))
+
+	// SNI и ALPN учитываются в количестве расширений, но не в хэше
+	extensions := slices.DeleteFunc(slices.Clone(fp.Extensions), func(e uint16) bool {
+		return e == tlsExtServerName || e == tlsExtALPN
+	})
+	slices.Sort(extensions)
+	c := truncatedSHA256(joinCodes(extensions, "%04x", ",") + "_" + joinCodes(fp.SignatureAlgorithms, "%04x", ","))
+
+	return a + "_" + b + "_" + c
+}
+
+// joinCodes форматирует коды и соединяет их разделителем
+func joinCodes(codes []uint16, format, sep string) string {
+	parts := make([]string, len(codes))
+	for i, c := range codes {
+		parts[i] = fmt.Sprintf(format, c)
+	}
+	return strings.Join(parts, sep)
+}
+
+// truncatedSHA256 первые 12 символов hex SHA-256, как в JA4
+func truncatedSHA256(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:12]
+}
@@ -0,0 +1,100 @@
+// ualist.go внешний список User-Agent (например, собранный из реального трафика) как дополнительный источник
+
+package useragent
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"math/rand/v2"
+	"strings"
+)
+
+const (
+	// defaultUserAgentListWeight - доля Get, отдаваемая внешнему списку, если вес не задан
+	defaultUserAgentListWeight = 0.5
+
+	// maxUserAgentLength отсекает заведомо мусорные строки
+	maxUserAgentLength = 512
+)
+
+// WithUserAgentList загружает список строк User-Agent (по одной на строку, пустые строки и строки с # пропускаются)
+// и подмешивает их в вывод Get с весом WithUserAgentListWeight (по умолчанию 0.5).
+//
+// принимаются только строки, которые генератор умеет сопровождать заголовками: Chrome, Edge, Firefox и Safari
+// (GetHeaders для них строит набор заголовков по бренду, версии и платформе из строки).
+// отклоненные строки и ошибки чтения записываются в лог, генератор при этом создается.
+func WithUserAgentList(r io.Reader) Option {
+	return func(g *Generator) {
+		g.uaListReader = r
+	}
+}
+
+// WithUserAgentListWeight задает долю вызовов Get (от 0 до 1), возвращающих строку из WithUserAgentList
+func WithUserAgentListWeight(weight float64) Option {
+	return func(g *Generator) {
+		if math.IsNaN(weight) {
+			return
+		}
+		g.uaListWeight = min(max(weight, 0), 1)
+	}
+}
+
+// loadUserAgentList читает и проверяет список из WithUserAgentList
+func (g *Generator) loadUserAgentList() {
+	if g.uaListReader == nil {
+		return
+	}
+	defer func() { g.uaListReader = nil }()
+
+	var accepted []string
+	rejected := 0
+	scanner := bufio.NewScanner(g.uaListReader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !validUserAgent(line) {
+			rejected++
+			g.logger.Debug("строка User-Agent отклонена", "event", eventUAListRejected, "user_agent", line)
+			continue
+		}
+		accepted = append(accepted, line)
+	}
+	if err := scanner.Err(); err != nil {
+		g.logger.Warn("не удалось дочитать список User-Agent", "event", eventUAListFailed, "error", err)
+	}
+
+	g.uaList = accepted
+	g.logger.Debug("загружен список User-Agent", "event", eventUAListLoaded, "accepted", len(accepted), "rejected", rejected)
+}
+
+// validUserAgent проверяет, что строка похожа на User-Agent поддерживаемого браузера
+func validUserAgent(ua string) bool {
+	if len(ua) > maxUserAgentLength || !strings.HasPrefix(ua, "Mozilla/5.0 (") {
+		return false
+	}
+	for _, r := range ua {
+		if r < 0x20 || r > 0x7e {
+			return false
+		}
+	}
+	info := parseUserAgent(ua)
+	switch info.BrandName {
+	case "Firefox", "Safari":
+		return true
+	default:
+		// Chrome и Edge: без версии Chromium не получится построить sec-ch-ua
+		return info.MajorVersion != ""
+	}
+}
+
+// randomListUA возвращает случайную строку из внешнего списка или пустую строку,
+// если список не загружен или выпал синтезированный User-Agent
+func (g *Generator) randomListUA(r *rand.Rand) string {
+	if len(g.uaList) == 0 || r.Float64() >= g.uaListWeight {
+		return ""
+	}
+	return g.uaList[r.IntN(len(g.uaList))]
+}
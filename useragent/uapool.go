@@ -0,0 +1,88 @@
+// uapool.go заранее сгенерированный пул User-Agent: Get без блокировок для горячих путей
+
+package useragent
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// uaPoolRefreshInterval как часто пул генерируется заново: за это время версии браузеров могли обновиться,
+// а строки в кольце - примелькаться
+const uaPoolRefreshInterval = time.Minute
+
+// uaPoolFillChunk сколько строк пула генерируется под одной блокировкой чтения: большой пул
+// не должен надолго задерживать обновление версий, которому нужна блокировка записи
+const uaPoolFillChunk = 256
+
+// uaPool кольцо заранее выбранных строк: чтение - атомарное увеличение позиции, замена кольца - атомарная
+type uaPool struct {
+	size int
+	ring atomic.Pointer[[]string]
+	pos  atomic.Uint64
+}
+
+// next возвращает следующую строку кольца, пусто - кольцо еще не заполнено
+func (p *uaPool) next() string {
+	ring := p.ring.Load()
+	if ring == nil {
+		return ""
+	}
+	return (*ring)[(p.pos.Add(1)-1)%uint64(len(*ring))]
+}
+
+// WithUAPool включает пул из size заранее сгенерированных строк User-Agent для горячих путей
+// (миллионы вызовов Get в секунду): Get берет строки из кольца по очереди атомарным увеличением индекса,
+// без блокировки генератора и форматирования строки. пул генерируется заново в фоне каждую минуту
+// и сразу после обновления версий, поэтому строки в пределах size вызовов могут повторяться
+// (как и при обычном случайном выборе), но каждые size вызовов последовательность не повторяется.
+// size не больше нуля игнорируется. с WithNoRepeat пул не используется, а с WithSeed последовательность
+// воспроизводима только до первого фонового обновления пула
+func WithUAPool(size int) Option {
+	return func(g *Generator) {
+		if size > 0 {
+			g.uaPool = &uaPool{size: size}
+		}
+	}
+}
+
+// startUAPool заполняет пул и запускает его фоновое обновление до вызова Close
+func (g *Generator) startUAPool() {
+	if g.uaPool == nil || g.noRepeat != nil {
+		return
+	}
+	g.fillUAPool()
+	updates := g.Subscribe()
+	g.background.Add(1)
+	go func() {
+		defer g.background.Done()
+		defer g.Unsubscribe(updates)
+		ticker := time.NewTicker(uaPoolRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-g.done:
+				return
+			case <-ticker.C:
+			case <-updates:
+			}
+			g.fillUAPool()
+		}
+	}()
+}
+
+// fillUAPool генерирует новое кольцо строк и атомарно заменяет им текущее.
+// кольцо заполняется частями по uaPoolFillChunk строк, между которыми блокировка отпускается:
+// если версии обновились посреди заполнения, кольцо смешанное, но уведомление об обновлении
+// сразу запускает следующее заполнение
+func (g *Generator) fillUAPool() {
+	ring := make([]string, g.uaPool.size)
+	for start := 0; start < len(ring); start += uaPoolFillChunk {
+		g.mu.RLock()
+		for i := start; i < min(start+uaPoolFillChunk, len(ring)); i++ {
+			ring[i] = g.randomUA(g.rng)
+		}
+		g.mu.RUnlock()
+	}
+	g.uaPool.ring.Store(&ring)
+}
@@ -8,6 +8,9 @@ package useragent
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -16,6 +19,7 @@ import (
 	"math"
 	"math/rand/v2"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -34,14 +38,43 @@ const (
 	versionsToKeepFromGoogle = 45
 	versionsToKeepFromMS     = 20
 
-	// шаблоны User-Agent
-	chromeUATemplate = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s Safari/537.36"
-	edgeUATemplate   = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%s Safari/537.36 Edg/%s"
+	// ограничения размера ответа источника и файла кэша: сломанный или подмененный источник
+	// не должен занимать память процесса без ограничений
+	maxSourceResponseSize = 8 << 20
+	maxCacheFileSize      = 1 << 20
+
+	// части User-Agent Chrome и Edge "Mozilla/5.0 (<токен платформы>) AppleWebKit/537.36 (KHTML, like Gecko)
+	// Chrome/<версия> Safari/537.36 Edg/<сборка Edge>": строка собирается без fmt (см. appendChromiumUA)
+	chromiumUAPrefix = "Mozilla/5.0 ("
+	chromiumUAEngine = ") AppleWebKit/537.36 (KHTML, like Gecko) Chrome/"
+	chromiumUASuffix = " Safari/537.36"
+	edgeUAToken      = " Edg/"
 
 	// имя файла для дискового кэша по умолчанию
 	defaultCacheFileName = "go_ua_versions.json"
+
+	// вероятность выбора Edge по умолчанию
+	defaultEdgeProbability = 0.5
+)
+
+// ошибки получения версий из сетевых источников
+var (
+	errSourcesFailed  = errors.New("сетевые источники версий браузеров завершились безрезультатно")
+	errSourcesTimeout = errors.New("сетевые источники версий браузеров завершены по таймауту")
+	errSourcesOffline = errors.New("сетевые источники версий браузеров отключены (офлайн-режим)")
+	errStrictSources  = errors.New("строгий режим: ни один сетевой источник версий браузеров не ответил, аппроксимация запрещена")
+	errSourceTooLarge = errors.New("ответ источника версий превышает допустимый размер")
 )
 
+// errOffline возвращается методами, которым нужна сеть, в офлайн-режиме и в сборке с тегом nonet
+var errOffline = errors.New("сетевые запросы отключены (офлайн-режим или сборка с тегом nonet)")
+
+// chromiumVersionRegex формат полной версии Chromium (142.0.7444.59)
+var chromiumVersionRegex = regexp.MustCompile(`^\d{1,4}\.\d{1,4}\.\d{1,6}\.\d{1,6}$`)
+
+// firefoxVersionRegex формат мажорной версии Firefox в пуле (142.0)
+var firefoxVersionRegex = regexp.MustCompile(`^\d{1,4}\.0$`)
+
 // регулярное выражение для парсинга версий MS Edge со страницы
 var msEdgeVersionRegex = regexp.MustCompile(
 	`<a href="([^"]+\.deb)">[^<]+</a>\s+(\d{1,2}-[A-Za-z]{3}-\d{4})\s+(\d{1,2}:\d{2})`,
@@ -58,6 +91,36 @@ type googleAPIResponse struct {
 type cacheFile struct {
 	Timestamp time.Time `json:"timestamp"`
 	Versions  []string  `json:"versions"`
+	Firefox   []string  `json:"firefox_versions,omitempty"` // версии Firefox, если генерация Firefox включена
+	Edge      []string  `json:"edge_versions,omitempty"`    // сборки Edge из репозитория Microsoft
+	Signature string    `json:"signature,omitempty"`        // HMAC-SHA256 содержимого, если задан ключ подписи
+
+	// Validators ETag и Last-Modified ответов источников по адресу для условных запросов при обновлении
+	Validators map[string]sourceValidator `json:"validators,omitempty"`
+}
+
+// sign вычисляет HMAC-SHA256 от содержимого кэша без учета самой подписи
+func (c cacheFile) sign(key []byte) (string, error) {
+	c.Signature = ""
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// verify проверяет подпись кэша заданным ключом за постоянное время
+func (c cacheFile) verify(key []byte) bool {
+	if c.Signature == "" {
+		return false
+	}
+	expected, err := c.sign(key)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(c.Signature))
 }
 
 // msEdgeRelease содержит информацию, извлеченную из репозитория Microsoft Edge
@@ -78,6 +141,73 @@ type Generator struct {
 	logger        *slog.Logger
 	diskCachePath string
 	diskCacheTTL  time.Duration
+	cacheKey      []byte // ключ HMAC-подписи дискового кэша, nil - подпись отключена
+
+	mirrors          map[Source][]string // зеркала сетевых источников, опрашиваются после основного адреса
+	sourceOverrides  map[Source][]string // адреса источников из реестра WithSourceRegistry вместо встроенных
+	registryLocation string              // путь или адрес реестра источников, пусто - встроенные адреса
+	registryTTL      time.Duration       // время жизни кэша удаленного реестра
+	offline          bool                // сетевые источники отключены, используются только кэш и аппроксимация
+	sourceEncodings  []string            // кодировки сжатия ответов источников (WithSourceEncodings), пусто - gzip и deflate
+	sourceProxy      *url.URL            // прокси сетевых запросов генератора (WithProxy), nil - как у HTTP-клиента
+	strictSources    bool                // ошибка вместо аппроксимации версий (WithStrictSources)
+	importedAt       time.Time           // момент экспорта последнего набора, принятого ImportVersions
+	versionsOrigin   string              // источник текущего набора версий (OriginCache, OriginNetwork...)
+	versionsAt       time.Time           // момент получения текущего набора (для кэша и импорта - момент его создания)
+	lastFetchAt      time.Time           // момент завершения последнего опроса сетевых источников
+	lastFetchErr     error               // ошибка последнего опроса сетевых источников, nil - успех
+
+	validators map[string]sourceValidator // ETag и Last-Modified ответов источников по адресу для условных запросов
+
+	pinnedVersions []string // версии Chromium, закрепленные WithStaticVersion или WithVersions, пусто - версии из источников
+	pinnedEdge     []string // сборки Edge, закрепленные WithStaticVersion
+
+	refreshSpec   string        // расписание фонового обновления версий, пусто - обновление отключено
+	refreshJitter time.Duration // максимальная случайная задержка запланированного обновления
+	initDeadline  time.Duration // бюджет времени на ожидание сетевых источников в NewGenerator, 0 - без ограничения
+
+	edgeProbability    float64             // вероятность выбора Edge вместо Chrome в Get
+	firefoxProbability float64             // вероятность выбора Firefox в Get, 0 - Firefox не генерируется
+	firefoxVersions    []string            // мажорные версии Firefox ("142.0")
+	edgeVersions       []string            // сборки Edge из репозитория Microsoft ("142.0.3595.53"), пусто - как у Chromium
+	safariProbability  float64             // вероятность выбора Safari в Get, 0 - Safari не генерируется
+	mobileProbability  float64             // вероятность выбора Chrome для Android вместо десктопного Chrome/Edge
+	platforms          []platformProfile   // десктопные платформы для Get, пусто - только Windows
+	browserWeights     map[Browser]float64 // веса семейств браузеров из WithBrowserWeights, nil - по вероятностям отдельных опций
+	transformers       []func(*HeaderSet)  // обработчики сгенерированных заголовков из WithHeaderTransformer
+	rng                *rand.Rand          // источник случайности Get, GetHeaders и NewSession: globalRand, WithSeed или WithRandSource
+	fullVersionUA      bool                // полная версия Chromium в User-Agent вместо сокращенной (WithFullVersionUA)
+	noNetworkHints     bool                // без подсказок downlink, ect и rtt (WithNetworkHints(false))
+	saveData           float64             // вероятность заголовка save-data: on (WithSaveData), 0 - не отправляется
+	gpc                float64             // вероятность заголовка sec-gpc: 1 (WithGPC), 0 - не отправляется
+	dnt                float64             // вероятность заголовка dnt: 1 (WithDoNotTrack), 0 - не отправляется
+	browserEncoding    bool                // accept-encoding браузера в заголовках (WithBrowserEncoding)
+
+	uaListReader   io.Reader      // источник WithUserAgentList, читается в NewGenerator
+	uaList         []string       // проверенные строки внешнего списка
+	uaListWeight   float64        // доля Get, отдаваемая внешнему списку
+	locales        []string       // языки браузера для accept-language, пусто - ru-RU и en-US
+	referrerPolicy ReferrerPolicy // политика формирования Referer, пусто - strict-origin-when-cross-origin
+
+	refererStrategy RefererStrategy // откуда пользователь приходит на страницу в GetHeaders (WithRefererStrategy)
+	customReferers  []*url.URL      // страницы перехода RefererCustom
+
+	noRepeat *uaDeck // колода WithNoRepeat: Get не повторяет строки до исчерпания пула, nil - режим выключен
+	uaPool   *uaPool // заранее сгенерированные строки WithUAPool, nil - Get выбирает строку при каждом вызове
+
+	data             *realismData  // таблицы для реалистичности заголовков (встроенные или из манифеста)
+	manifestLocation string        // путь или адрес манифеста данных, пусто - только встроенные данные
+	manifestTTL      time.Duration // время жизни кэша удаленного манифеста
+
+	ranges   map[CrawlerType]*cachedRanges // опубликованные диапазоны адресов роботов для VerifyCrawler
+	rangesMu sync.Mutex                    // защита ranges
+
+	subscribers []chan VersionsUpdate // подписчики на изменения набора версий
+	subsMu      sync.Mutex            // защита subscribers
+
+	done       chan struct{}  // закрывается в Close, сигнал остановки фоновых горутин
+	background sync.WaitGroup // фоновые горутины генератора, Close дожидается их завершения
+	closeOnce  sync.Once      // гарантия однократного закрытия
 }
 
 // WithHTTPClient устанавливает пользовательский клиент для генератора
@@ -98,35 +228,93 @@ func WithLogger(logger *slog.Logger) Option {
 	}
 }
 
+// WithEdgeProbability устанавливает вероятность (от 0 до 1) того, что Get вернет User-Agent Edge, а не Chrome.
+// реальная доля Edge среди десктопных браузеров заметно ниже 50% (порядка 0.05-0.15),
+// а неестественно высокая доля Edge в трафике может выглядеть подозрительно.
+func WithEdgeProbability(p float64) Option {
+	return func(g *Generator) {
+		if math.IsNaN(p) {
+			return
+		}
+		g.edgeProbability = min(max(p, 0), 1)
+	}
+}
+
+// WithOfflineMode полностью отключает сетевые запросы генератора:
+// версии берутся только из дискового кэша (если он включен) или аппроксимируются по текущей дате,
+// а Check и VerifyCrawler возвращают ошибку без обращения к сети.
+func WithOfflineMode() Option {
+	return func(g *Generator) {
+		g.offline = true
+	}
+}
+
+// WithStrictSources запрещает аппроксимацию версий: если ни кэш, ни сетевые источники не дали версий,
+// NewGenerator возвращает ошибку, а Refresh сохраняет текущие версии и возвращает ошибку
+// (в том числе для Firefox, если он включен). подходит для развертываний, которые лучше остановить,
+// чем запускать с вычисленными номерами версий. с WithInitDeadline истечение срока тоже считается ошибкой,
+// а с WithOfflineMode генератор создается только из актуального дискового кэша.
+func WithStrictSources() Option {
+	return func(g *Generator) {
+		g.strictSources = true
+	}
+}
+
 // loadFromDiskCache загружает версии из дискового кэша, если он актуален и содержит версии браузеров:
 // возвращает true, если кэш был успешно загружен, иначе false
 func (g *Generator) loadFromDiskCache() bool {
-	data, err := os.ReadFile(g.diskCachePath)
+	return g.loadDiskCache(false)
+}
+
+// loadDiskCache загружает версии из дискового кэша, acceptStale - в том числе из устаревшего
+func (g *Generator) loadDiskCache(acceptStale bool) bool {
+	data, err := readLimited(g.diskCachePath, maxCacheFileSize)
 	if err != nil {
 		if !os.IsNotExist(err) {
-			g.logger.Warn("не удалось прочитать кэш из файла", "path", g.diskCachePath, "error", err)
+			g.logger.Warn("не удалось прочитать кэш из файла", "event", eventCacheReadFailed, "cache_path", g.diskCachePath, "error", err)
 		}
 		return false
 	}
 
 	var cache cacheFile
 	if err := json.Unmarshal(data, &cache); err != nil {
-		g.logger.Warn("не удалось распарсить кэш из файла", "path", g.diskCachePath, "error", err)
+		g.logger.Warn("не удалось распарсить кэш из файла", "event", eventCacheParseFailed, "cache_path", g.diskCachePath, "error", err)
+		return false
+	}
+
+	if g.cacheKey != nil && !cache.verify(g.cacheKey) {
+		g.logger.Warn("подпись кэша на диске отсутствует или неверна, кэш проигнорирован", "event", eventCacheBadSignature, "cache_path", g.diskCachePath)
 		return false
 	}
 
-	if time.Since(cache.Timestamp) > g.diskCacheTTL {
-		g.logger.Debug("кэш на диске устарел и будет обновлен…", "path", g.diskCachePath)
+	// валидаторы берутся и из устаревшего кэша: неизменившийся источник подтвердит версии ответом 304
+	g.loadValidators(cache.Validators)
+
+	if !acceptStale && time.Since(cache.Timestamp) > g.diskCacheTTL {
+		g.logger.Debug("кэш на диске устарел и будет обновлен…", "event", eventCacheExpired, "cache_path", g.diskCachePath)
 		return false
 	}
 
 	if len(cache.Versions) == 0 {
-		g.logger.Warn("кэш версий браузеров пуст", "path", g.diskCachePath)
+		g.logger.Warn("кэш версий браузеров пуст", "event", eventCacheEmpty, "cache_path", g.diskCachePath)
+		return false
+	}
+	// кэш с версиями неверного формата поврежден или подменен целиком, и частично ему доверять нельзя
+	if !allMatch(cache.Versions, chromiumVersionRegex) || !allMatch(cache.Edge, chromiumVersionRegex) ||
+		!allMatch(cache.Firefox, firefoxVersionRegex) {
+		g.logger.Warn("кэш содержит версии неверного формата и проигнорирован", "event", eventCacheParseFailed, "cache_path", g.diskCachePath)
 		return false
 	}
 
+	g.setVersions(cache.Versions, OriginCache)
 	g.mu.Lock()
-	g.versions = cache.Versions
+	g.versionsAt = cache.Timestamp
+	if len(cache.Firefox) > 0 {
+		g.firefoxVersions = cache.Firefox
+	}
+	if len(cache.Edge) > 0 {
+		g.edgeVersions = cache.Edge
+	}
 	g.mu.Unlock()
 	return true
 }
@@ -135,21 +323,35 @@ func (g *Generator) loadFromDiskCache() bool {
 func (g *Generator) saveToDiskCache() {
 	g.mu.RLock()
 	versionsToCache := g.versions
+	firefoxToCache := g.firefoxVersions
+	edgeToCache := g.edgeVersions
 	g.mu.RUnlock()
 
 	if len(versionsToCache) == 0 {
-		g.logger.Warn("пропуск сохранения кеша диска, так как не было загружено ни одной версии")
+		g.logger.Warn("пропуск сохранения кеша диска, так как не было загружено ни одной версии", "event", eventCacheSaveSkipped)
 		return
 	}
 
 	cache := cacheFile{
-		Timestamp: time.Now(),
-		Versions:  versionsToCache,
+		Timestamp:  time.Now(),
+		Versions:   versionsToCache,
+		Firefox:    firefoxToCache,
+		Edge:       edgeToCache,
+		Validators: g.cachedValidators(),
+	}
+
+	if g.cacheKey != nil {
+		signature, err := cache.sign(g.cacheKey)
+		if err != nil {
+			g.logger.Error("не удалось подписать кэш", "event", eventCacheSaveFailed, "error", err)
+			return
+		}
+		cache.Signature = signature
 	}
 
 	data, err := json.Marshal(cache)
 	if err != nil {
-		g.logger.Error("не удалось преобразовать версии из кеша на диске", "error", err)
+		g.logger.Error("не удалось преобразовать версии из кеша на диске", "event", eventCacheSaveFailed, "error", err)
 		return
 	}
 
@@ -158,7 +360,7 @@ func (g *Generator) saveToDiskCache() {
 	dir := filepath.Dir(g.diskCachePath)
 	tempFile, err := os.CreateTemp(dir, "useragent-cache-*.tmp")
 	if err != nil {
-		g.logger.Error("не удалось создать временный файл для кэша", "error", err)
+		g.logger.Error("не удалось создать временный файл для кэша", "event", eventCacheSaveFailed, "cache_path", g.diskCachePath, "error", err)
 		return
 	}
 
@@ -168,23 +370,24 @@ func (g *Generator) saveToDiskCache() {
 	}()
 
 	if _, err := tempFile.Write(data); err != nil {
-		g.logger.Error("не удалось записать версии браузеров во временный файл", "error", err)
+		g.logger.Error("не удалось записать версии браузеров во временный файл", "event", eventCacheSaveFailed, "cache_path", g.diskCachePath, "error", err)
 		_ = tempFile.Close()
 		return
 	}
 
 	if err := tempFile.Close(); err != nil {
-		g.logger.Error("не удалось закрыть временный файл", "error", err)
+		g.logger.Error("не удалось закрыть временный файл", "event", eventCacheSaveFailed, "cache_path", g.diskCachePath, "error", err)
 		return
 	}
 
 	if err := os.Rename(tempFile.Name(), g.diskCachePath); err != nil {
 		g.logger.Error(
-			fmt.Sprintf("не удалось переименовать временный файл %s в %s", tempFile.Name(), g.diskCachePath), "error", err)
+			fmt.Sprintf("не удалось переименовать временный файл %s в %s", tempFile.Name(), g.diskCachePath),
+			"event", eventCacheSaveFailed, "cache_path", g.diskCachePath, "error", err)
 		return
 	}
 
-	g.logger.Debug("версии браузера сохранены в дисковый кэш", "path", g.diskCachePath)
+	g.logger.Debug("версии браузера сохранены в дисковый кэш", "event", eventCacheSaved, "cache_path", g.diskCachePath)
 }
 
 // NewGenerator создаёт генератор User-Agent:
@@ -195,52 +398,152 @@ func NewGenerator(opts ...Option) (*Generator, error) {
 	g := &Generator{
 		httpClient: &http.Client{Timeout: 15 * time.Second},
 		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)), // по умолчанию используется тихий логгер
+
+		edgeProbability: defaultEdgeProbability,
+		uaListWeight:    defaultUserAgentListWeight,
+		done:            make(chan struct{}),
+		rng:             globalRand,
 	}
 
 	for _, opt := range opts {
 		opt(g)
 	}
+	if !networkEnabled {
+		// сборка с тегом nonet: сетевые источники исключены
+		g.offline = true
+	}
+	// прокси применяется после всех опций, чтобы не зависеть от порядка WithProxy и WithHTTPClient
+	g.applySourceProxy()
+
+	// таблицы для генерации заголовков: встроенные, при необходимости дополненные манифестом
+	g.data = defaultRealismData()
+	g.loadDataManifest()
+
+	// адреса источников версий из реестра, если он задан
+	g.loadSourceRegistry()
+
+	// внешний список User-Agent, если задан
+	g.loadUserAgentList()
+
+	// закрепленные версии не загружаются ни из кэша, ни из сети
+	pinned := g.versionsPinned()
+	if pinned {
+		g.applyPinnedVersions()
+	}
 
 	// 1. попытка загрузить из дискового кэша
-	if g.diskCachePath != "" {
-		if loaded := g.loadFromDiskCache(); loaded {
-			g.logger.Debug("успешно загружены версии User-Agent из кэша на диске")
-			return g, nil
+	cacheLoaded := false
+	if g.diskCachePath != "" && !pinned {
+		if cacheLoaded = g.loadFromDiskCache(); cacheLoaded {
+			g.logger.Debug("успешно загружены версии User-Agent из кэша на диске", "event", eventCacheLoaded, "cache_path", g.diskCachePath)
+		}
+	}
+
+	// 2. при одновременном старте нескольких процессов сеть опрашивает владелец блокировки,
+	// остальные дожидаются записанного им кэша
+	if !cacheLoaded && g.diskCachePath != "" && !g.offline && !pinned {
+		release, owner := g.acquireCacheLock()
+		defer release()
+		if !owner {
+			cacheLoaded = g.waitForCacheRefresh()
 		}
 	}
 
-	// 2. если кэш невалиден или отключен, используются данные из сетевых источников
-	if err := g.updateVersions(); err != nil {
-		// теоретически, этого никогда не произойдёт, из-за резервного варианта с аппроксимацией.
-		return nil, fmt.Errorf("не удалось получить версии после всех резервных вариантов: %w", err)
+	// 3. если кэш невалиден или отключен, используются данные из сетевых источников
+	needSave := false
+	if !cacheLoaded && !pinned {
+		if err := g.updateVersions(); err != nil {
+			// возможно только в строгом режиме WithStrictSources: без него срабатывает аппроксимация
+			g.abort()
+			return nil, fmt.Errorf("не удалось получить версии после всех резервных вариантов: %w", err)
+		}
+		needSave = true
+	}
+	// версии Firefox запрашиваются, только если его генерация включена и их нет в кэше
+	if g.firefoxEnabled() && len(g.firefoxVersions) == 0 {
+		if err := g.initFirefoxVersions(); err != nil {
+			g.abort()
+			return nil, fmt.Errorf("не удалось получить версии Firefox: %w", err)
+		}
+		needSave = true
 	}
 
-	// 3. если кэш включен, версии сохраняются на диск
-	if g.diskCachePath != "" {
+	// 4. если кэш включен, версии сохраняются на диск
+	if needSave && g.diskCachePath != "" && !pinned {
 		g.saveToDiskCache()
 	}
 
+	// 5. запуск фонового обновления по расписанию, если оно задано, и пула строк WithUAPool
+	g.startRefreshSchedule()
+	g.startUAPool()
+
 	return g, nil
 }
 
 // Get конкурентнобезопасно возвращает случайную, актуальную строку User-Agent для браузера Chrome или Edge
+// (а также Firefox и Safari, если они включены через WithBrowserWeights, WithFirefoxProbability
+// или WithSafariProbability, и строки внешнего списка WithUserAgentList)
 func (g *Generator) Get() string {
+	if g.noRepeat != nil {
+		return g.noRepeat.draw(g)
+	}
+	if g.uaPool != nil {
+		if ua := g.uaPool.next(); ua != "" {
+			return ua
+		}
+	}
 	g.mu.RLock()
 	defer g.mu.RUnlock()
+	return g.randomUA(g.rng)
+}
+
+// uaBufferSize емкость буфера для сборки User-Agent в randomUA: вмещает любую строку Chrome и Edge
+const uaBufferSize = 192
+
+// randomUA выбирает User-Agent по настройкам генератора, используя источник случайности r,
+// вызывается под g.mu.RLock
+func (g *Generator) randomUA(r *rand.Rand) string {
+	var buf [uaBufferSize]byte
+	return string(g.appendRandomUA(buf[:0], r))
+}
+
+// appendRandomUA дописывает к dst User-Agent, выбранный по настройкам генератора, вызывается под g.mu.RLock.
+// строки десктопных Chrome и Edge собираются прямо в dst, остальные дописываются готовыми
+func (g *Generator) appendRandomUA(dst []byte, r *rand.Rand) []byte {
+	// строка из внешнего списка WithUserAgentList
+	if ua := g.randomListUA(r); ua != "" {
+		return append(dst, ua...)
+	}
+
+	browser := g.pickBrowser(r)
+	switch browser {
+	case BrowserFirefox:
+		return append(dst, g.randomFirefoxUA(r)...)
+	case BrowserSafari:
+		return append(dst, randomSafariUA(r)...)
+	}
 
 	if len(g.versions) == 0 {
 		// резервный вариант на случай маловероятной ситуации, когда инициализация частично завершилась неудачей, но не вернула ошибку.
-		return fmt.Sprintf(chromeUATemplate, g.approximateVersions())
+		return appendChromiumUA(dst, g.pickPlatform(r).uaToken, approximateVersionForDate(time.Now()), "", !g.fullVersionUA)
 	}
 
 	// выбор случайной версии из кэша
-	randomVersion := g.versions[rand.IntN(len(g.versions))]
+	version := g.versions[r.IntN(len(g.versions))]
 
-	// вероятность выбора Chrome - 50%, Edge - 50%
-	if rand.IntN(2) == 0 {
-		return fmt.Sprintf(chromeUATemplate, randomVersion)
+	// мобильный Chrome для Android, если включен WithMobileProbability
+	if browser == BrowserChrome && g.mobileProbability > 0 && r.Float64() < g.mobileProbability {
+		return append(dst, mobileUA(g.uaVersion(version))...)
 	}
-	return fmt.Sprintf(edgeUATemplate, randomVersion, randomVersion)
+
+	// доля Edge задается WithEdgeProbability (по умолчанию 50%) или WithBrowserWeights,
+	// на платформах без Edge (ChromeOS) всегда выбирается Chrome
+	platform := g.pickPlatform(r)
+	if browser == BrowserChrome || !platform.edge {
+		return appendChromiumUA(dst, platform.uaToken, version, "", !g.fullVersionUA)
+	}
+	chromium, edge := g.randomEdgeVersions(r, version)
+	return appendChromiumUA(dst, platform.uaToken, chromium, edge, !g.fullVersionUA)
 }
 
 // WithDiskCache включает кеширование на диске для сохранения версий браузера между запусками приложения.
@@ -256,38 +559,64 @@ func WithDiskCache(path string, ttl time.Duration) Option {
 	}
 }
 
-// executeGet выполняет HTTP GET запрос и безопасно управляет закрытием тела ответа.
-func (g *Generator) executeGet(ctx context.Context, url string, process func(io.Reader) error) (err error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return fmt.Errorf("не удалось создать запрос: %w", err)
+// WithCacheSigningKey включает HMAC-подпись дискового кэша указанным ключом:
+// кэш без подписи или с неверной подписью игнорируется при загрузке.
+// защищает от подмены версий (и, как следствие, строк User-Agent) через общий
+// или доступный на запись всем пользователям каталог кэша.
+func WithCacheSigningKey(key []byte) Option {
+	return func(g *Generator) {
+		if len(key) > 0 {
+			g.cacheKey = append([]byte(nil), key...)
+		}
 	}
+}
 
-	resp, err := g.httpClient.Do(req)
+// readLimited читает файл целиком, если он не больше limit байт
+func readLimited(path string, limit int64) ([]byte, error) {
+	f, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("HTTP запрос не удался: %w", err)
+		return nil, err
 	}
-	defer func() {
-		// закрытие с пробросом ошибки
-		err = errors.Join(err, resp.Body.Close())
-	}()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("неверный HTTP статус: %s", resp.Status)
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("размер файла превышает %d байт", limit)
 	}
+	return data, nil
+}
 
-	return process(resp.Body)
+// allMatch проверяет, что все строки соответствуют регулярному выражению
+func allMatch(values []string, re *regexp.Regexp) bool {
+	for _, v := range values {
+		if !re.MatchString(v) {
+			return false
+		}
+	}
+	return true
 }
 
-// fetchGoogleVersions получает последние версии Chrome через официальный API Google.
+// fetchGoogleVersions получает последние версии Chrome через официальный API Google или его зеркала.
 func (g *Generator) fetchGoogleVersions(ctx context.Context) ([]string, error) {
+	return g.fetchWithMirrors(ctx, SourceGoogle, g.fetchGoogleVersionsFrom)
+}
+
+// fetchGoogleVersionsFrom получает версии Chrome по указанному адресу в формате Google Versions API.
+func (g *Generator) fetchGoogleVersionsFrom(ctx context.Context, url string) ([]string, error) {
 	var apiResponse googleAPIResponse
-	err := g.executeGet(ctx, googleAPIURL, func(body io.Reader) error {
+	known := g.validatorFor(url)
+	validator, err := g.executeConditionalGet(ctx, url, known, func(body io.Reader) error {
 		if err := json.NewDecoder(body).Decode(&apiResponse); err != nil {
 			return fmt.Errorf("не удалось декодировать JSON-ответ: %w", err)
 		}
 		return nil
 	})
+	if errors.Is(err, errNotModified) {
+		g.logger.DebugContext(ctx, "источник не изменился, версии подтверждены ответом 304", "event", eventSourceNotModified, "url", url)
+		return known.Versions, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -296,20 +625,36 @@ func (g *Generator) fetchGoogleVersions(ctx context.Context) ([]string, error) {
 		return nil, errors.New("API не вернул релизов")
 	}
 
-	limit := min(versionsToKeepFromGoogle, len(apiResponse.Releases))
-	versions := make([]string, 0, limit)
-	for i := 0; i < limit; i++ {
-		versions = append(versions, apiResponse.Releases[i].Version)
+	versions := make([]string, 0, versionsToKeepFromGoogle)
+	for _, release := range apiResponse.Releases {
+		if len(versions) >= versionsToKeepFromGoogle {
+			break
+		}
+		if !chromiumVersionRegex.MatchString(release.Version) {
+			g.logger.Debug("версия неверного формата в ответе Google, пропуск записи…", "event", eventSourceParseSkipped, "version", release.Version)
+			continue
+		}
+		versions = append(versions, release.Version)
+	}
+	if len(versions) == 0 {
+		return nil, errors.New("API не вернул ни одной версии верного формата")
 	}
 
+	g.rememberValidator(url, validator, versions)
 	return versions, nil
 }
 
-// fetchMicrosoftVersions парсит страницу репозитория Microsoft Edge, чтобы найти последние версии браузеров.
+// fetchMicrosoftVersions получает последние версии Edge из репозитория Microsoft или его зеркал.
 func (g *Generator) fetchMicrosoftVersions(ctx context.Context) ([]string, error) {
+	return g.fetchWithMirrors(ctx, SourceMicrosoft, g.fetchMicrosoftVersionsFrom)
+}
+
+// fetchMicrosoftVersionsFrom парсит страницу репозитория Microsoft Edge по указанному адресу, чтобы найти последние версии браузеров.
+func (g *Generator) fetchMicrosoftVersionsFrom(ctx context.Context, url string) ([]string, error) {
 	var body []byte
 
-	err := g.executeGet(ctx, msEdgeRepoURL, func(r io.Reader) error {
+	known := g.validatorFor(url)
+	validator, err := g.executeConditionalGet(ctx, url, known, func(r io.Reader) error {
 		var readErr error
 		body, readErr = io.ReadAll(r)
 		if readErr != nil {
@@ -317,14 +662,27 @@ func (g *Generator) fetchMicrosoftVersions(ctx context.Context) ([]string, error
 		}
 		return nil
 	})
+	if errors.Is(err, errNotModified) {
+		g.logger.DebugContext(ctx, "источник не изменился, версии подтверждены ответом 304", "event", eventSourceNotModified, "url", url)
+		return known.Versions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	versions, err := g.parseMicrosoftVersions(url, body)
 	if err != nil {
 		return nil, err
 	}
+	g.rememberValidator(url, validator, versions)
+	return versions, nil
+}
 
+// parseMicrosoftVersions извлекает последние версии Edge из страницы репозитория Microsoft, загруженной с url
+func (g *Generator) parseMicrosoftVersions(url string, body []byte) ([]string, error) {
 	matches := msEdgeVersionRegex.FindAllStringSubmatch(string(body), -1)
 	if len(matches) == 0 {
-		g.logger.Debug(string(body)) // логгирование всего тела страницы для отладки
-		return nil, fmt.Errorf("не удалось найти версии браузеров на странице %s, возможно паттерн регулярного выражения устарел", msEdgeRepoURL)
+		g.logger.Debug(string(body), "event", eventSourceBody, "url", url) // логгирование всего тела страницы для отладки
+		return nil, fmt.Errorf("не удалось найти версии браузеров на странице %s, возможно паттерн регулярного выражения устарел", url)
 	}
 
 	releases := make([]msEdgeRelease, 0, len(matches))
@@ -346,7 +704,7 @@ func (g *Generator) fetchMicrosoftVersions(ctx context.Context) ([]string, error
 		const layout = "02-Jan-2006 15:04" // аналог "%d-%b-%Y %H:%M"
 		parsedTime, err := time.Parse(layout, fullDateTimeStr)
 		if err != nil {
-			g.logger.Debug("не удалось спарсить дату из репо MS, пропуск записи…", "date_string", fullDateTimeStr, "error", err)
+			g.logger.Debug("не удалось спарсить дату из репо MS, пропуск записи…", "event", eventSourceParseSkipped, "date_string", fullDateTimeStr, "error", err)
 			continue
 		}
 
@@ -354,6 +712,10 @@ func (g *Generator) fetchMicrosoftVersions(ctx context.Context) ([]string, error
 		version := strings.TrimPrefix(filename, "microsoft-edge-stable_")
 		version = strings.TrimSuffix(version, "_amd64.deb")
 		version = strings.TrimSuffix(version, "-1") // удаление суффикса "-1"
+		if !chromiumVersionRegex.MatchString(version) {
+			g.logger.Debug("версия неверного формата в репо MS, пропуск записи…", "event", eventSourceParseSkipped, "version", version)
+			continue
+		}
 
 		releases = append(releases, msEdgeRelease{Version: version, Date: parsedTime})
 	}
@@ -381,7 +743,6 @@ func (g *Generator) fetchMicrosoftVersions(ctx context.Context) ([]string, error
 			break
 		}
 	}
-
 	return versions, nil
 }
 
@@ -405,69 +766,124 @@ func approximateVersionForDate(d time.Time) string {
 	return fmt.Sprintf("%d.0.%d.%d", int(M), int(B), int(p))
 }
 
-// approximateVersions генерирует правдоподобный набор актуальных версий браузеров на текущую дату
-func (g *Generator) approximateVersions() []string {
-	versions := make([]string, 0, 5)
-	// создание вариантов для сегодняшнего дня и недавнего прошлого для разнообразия
-	for i := 0; i < 5; i++ {
-		d := time.Now().AddDate(0, 0, -i*7) // сегодня, неделю назад, две недели назад…
+// ApproximateVersionsFor вычисляет n правдоподобных версий Chrome/Edge, актуальных на дату date:
+// версия на саму дату и на каждую из предыдущих недель (date, date-7d, date-14d…).
+// это та же математическая модель, что используется как крайний фоллбэк при недоступности сети.
+func ApproximateVersionsFor(date time.Time, n int) []string {
+	versions := make([]string, 0, max(n, 0))
+	for i := 0; i < n; i++ {
+		d := date.AddDate(0, 0, -i*7)
 		versions = append(versions, approximateVersionForDate(d))
 	}
 	return versions
 }
 
+// approximateVersions генерирует правдоподобный набор актуальных версий браузеров на текущую дату
+func (g *Generator) approximateVersions() []string {
+	// создание вариантов для сегодняшнего дня и недавнего прошлого для разнообразия
+	return ApproximateVersionsFor(time.Now(), 5)
+}
+
 // updateVersions пытается получить версии браузеров из сетевых источников параллельно до первого успеха или использует аппроксимацию.
 func (g *Generator) updateVersions() error {
-	// общий таймаут на все сетевые операции
-	ctx, cancel := context.WithTimeout(context.Background(), g.httpClient.Timeout)
+	if g.offline {
+		if g.strictSources {
+			return fmt.Errorf("%w: %w", errStrictSources, errSourcesOffline)
+		}
+		if g.loadSnapshot() {
+			return nil
+		}
+		g.logger.Debug("офлайн-режим: сетевые источники отключены, используется аппроксимация", "event", eventFallback, "fallback", true)
+		g.setVersions(g.approximateVersions(), OriginApproximation)
+		return nil
+	}
+
+	var versions []string
+	var err error
+	if g.initDeadline > 0 {
+		fallback := func() {
+			g.logger.Warn("фоллбэк на аппроксимацию: сетевые источники не ответили за время инициализации, запросы продолжаются в фоне",
+				"event", eventFallback, "fallback", true, "deadline", g.initDeadline)
+			g.setVersions(g.approximateVersions(), OriginApproximation)
+		}
+		if g.strictSources {
+			fallback = func() {}
+		}
+		versions, err = g.awaitInit(func() ([]string, error) { return g.fetchVersions(context.Background()) }, fallback, g.lateVersions)
+		if errors.Is(err, errInitDeadline) && !g.strictSources {
+			return nil
+		}
+	} else {
+		versions, err = g.fetchVersions(context.Background())
+	}
+	if err != nil && g.strictSources {
+		return fmt.Errorf("%w: %w", errStrictSources, err)
+	}
+
+	origin := OriginApproximation
+	switch {
+	case err == nil:
+		origin = OriginNetwork
+		g.logger.Info("версии браузеров успешно получены из сети!", "event", eventVersionsUpdated, "fallback", false)
+	case errors.Is(err, errSourcesTimeout):
+		g.logger.Error("фоллбэк на аппроксимацию: сетевые источники версий браузеров завершены по таймауту.",
+			"event", eventFallback, "fallback", true)
+		versions = g.approximateVersions()
+	default:
+		g.logger.Warn("фоллбэк на аппроксимацию: сетевые источники версий браузеров завершились безрезультатно.",
+			"event", eventFallback, "fallback", true)
+		versions = g.approximateVersions()
+	}
+
+	g.setVersions(versions, origin)
+	return nil // без строгого режима фоллбэк всегда успешен
+}
+
+// fetchVersions параллельно запрашивает версии браузеров у сетевых источников
+// и возвращает набор версий Chromium, либо ошибку, если все источники завершились безрезультатно
+// или parent отменен. версии Edge из репозитория Microsoft сохраняются отдельно (setEdgeVersions).
+//
+// после первого ответа второй источник ждется не дольше secondSourceGrace: Google дает версии Chromium,
+// Microsoft - настоящие сборки Edge, а без ответа Google набором Chromium служат версии Edge
+func (g *Generator) fetchVersions(parent context.Context) (versions []string, err error) {
+	defer func() { g.recordFetch(err) }()
+
+	// общий таймаут на все сетевые операции: по таймауту на каждый адрес источника
+	ctx, cancel := context.WithTimeout(parent, g.sourcesTimeout(SourceGoogle, SourceMicrosoft))
 	defer cancel()
 
-	resultsChan := make(chan []string, 2) // буферизированный канал для результатов
+	type sourceResult struct {
+		source   Source
+		versions []string
+	}
+	resultsChan := make(chan sourceResult, 2) // буферизированный канал: отправка никогда не блокируется
 	var wg sync.WaitGroup
-	wg.Add(2)
 
-	// источник 1: Google API
-	go func() {
-		defer wg.Done()
-		sourceName := "Google API"
-		g.logger.Debug("попытка получить версии браузеров через Google API…")
-		versions, err := g.fetchGoogleVersions(ctx)
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				g.logger.Debug("запрос к источнику был отменен, так как другой источник ответил быстрее", "source", sourceName)
-			} else {
-				g.logger.Warn("не удалось получить данные от источника", "source", sourceName, "error", err)
-			}
-			return
-		}
-		// неблокирующая отправка, если другой источник завершится успешно раньше
-		select {
-		case resultsChan <- versions:
-			g.logger.Debug("получение версий браузеров через источник прошло успешно", "source", sourceName)
-		case <-ctx.Done():
+	for _, source := range []Source{SourceGoogle, SourceMicrosoft} {
+		fetch := g.fetchGoogleVersions
+		if source == SourceMicrosoft {
+			fetch = g.fetchMicrosoftVersions
 		}
-	}()
-
-	// источник 2: Microsoft Repo
-	go func() {
-		defer wg.Done()
-		sourceName := "Microsoft Repo"
-		g.logger.Debug("попытка получить версии браузеров из репозитория Microsoft…")
-		versions, err := g.fetchMicrosoftVersions(ctx)
-		if err != nil {
-			if errors.Is(err, context.Canceled) {
-				g.logger.Debug("запрос к источнику был отменен, так как другой источник ответил быстрее", "source", sourceName)
-			} else {
-				g.logger.Warn("не удалось получить данные от источника", "source", sourceName, "error", err)
+		wg.Go(func() {
+			sourceName := source.String()
+			g.logger.DebugContext(ctx, "попытка получить версии браузеров из источника…", "event", eventSourceFetchStarted, "source", sourceName)
+			started := time.Now()
+			versions, err := fetch(ctx)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					g.logger.DebugContext(ctx, "запрос к источнику был отменен, так как другой источник ответил быстрее",
+						"event", eventSourceCanceled, "source", sourceName, "duration", time.Since(started))
+				} else {
+					g.logger.WarnContext(ctx, "не удалось получить данные от источника",
+						"event", eventSourceFetchFailed, "source", sourceName, "duration", time.Since(started), "error", err)
+				}
+				return
 			}
-			return
-		}
-		select {
-		case resultsChan <- versions:
-			g.logger.Debug("получение версий браузеров через источник прошло успешно", "source", sourceName)
-		case <-ctx.Done():
-		}
-	}()
+			g.logger.DebugContext(ctx, "получение версий браузеров через источник прошло успешно",
+				"event", eventSourceFetchOK, "source", sourceName, "duration", time.Since(started))
+			resultsChan <- sourceResult{source, versions}
+		})
+	}
 
 	// горутина для завершения обоих сетевых запросов
 	allNetworkDone := make(chan struct{})
@@ -476,28 +892,54 @@ func (g *Generator) updateVersions() error {
 		close(allNetworkDone)
 	}()
 
-	// ожидание первого успешного запроса или завершения обоих
-	select {
-	case versions := <-resultsChan:
-		g.logger.Info("версии браузеров успешно получены из сети!")
-		g.mu.Lock()
-		g.versions = versions
-		g.mu.Unlock()
-		return nil
-	case <-allNetworkDone:
-		// оба источника завершились безрезультатно
-		g.logger.Warn("фоллбэк на аппроксимацию: сетевые источники версий браузеров завершились безрезультатно.")
-		g.mu.Lock()
-		g.versions = g.approximateVersions()
-		g.mu.Unlock()
-		return nil
-	case <-ctx.Done():
-		// общий таймаут
-		g.logger.Error("фоллбэк на аппроксимацию: сетевые источники версий браузеров завершены по таймауту.")
-		g.mu.Lock()
-		g.versions = g.approximateVersions()
-		g.mu.Unlock()
-		return nil // фоллбэк всегда успешен, ошибки для возврата быть не может
+	// ожидание обоих источников: после первого ответа - не дольше secondSourceGrace
+	var chromium, edge []string
+	var grace <-chan time.Time
+	collect := func(res sourceResult) {
+		if res.source == SourceGoogle {
+			chromium = res.versions
+		} else {
+			edge = res.versions
+		}
+	}
+wait:
+	for chromium == nil || edge == nil {
+		select {
+		case res := <-resultsChan:
+			collect(res)
+			if grace == nil {
+				grace = time.After(secondSourceGrace)
+			}
+		case <-allNetworkDone:
+			// оба источника завершились, но результат мог успеть попасть в канал
+			for len(resultsChan) > 0 {
+				collect(<-resultsChan)
+			}
+			break wait
+		case <-grace:
+			break wait
+		case <-ctx.Done():
+			if chromium != nil || edge != nil {
+				break wait
+			}
+			if err := parent.Err(); err != nil {
+				return nil, err // отмена вызывающим кодом
+			}
+			// общий таймаут
+			return nil, errSourcesTimeout
+		}
+	}
+
+	if edge != nil {
+		g.setEdgeVersions(edge)
+	}
+	switch {
+	case chromium != nil:
+		return chromium, nil
+	case edge != nil:
+		return edge, nil
+	default:
+		return nil, errSourcesFailed
 	}
 }
 
@@ -0,0 +1,82 @@
+// useragent_test.go фаззинг разбора ответов источников версий и дискового кэша: go test -fuzz FuzzXxx ./useragent
+
+package useragent
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newFuzzGenerator создает офлайн-генератор без логов для фаззинга внутренних парсеров
+func newFuzzGenerator(t testing.TB) *Generator {
+	g, err := NewGenerator(WithOfflineMode(), WithLogger(slog.New(slog.DiscardHandler)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = g.Close() })
+	return g
+}
+
+// FuzzMicrosoftRepo страница репозитория Microsoft Edge произвольного содержания не роняет разбор,
+// а в результат попадают только уникальные версии верного формата
+func FuzzMicrosoftRepo(f *testing.F) {
+	f.Add([]byte(`<a href="microsoft-edge-stable_128.0.2739.25-1_amd64.deb">microsoft-edge-stable_128.0.2739.25-1_amd64.deb</a>  20-Aug-2024 20:31  171M`))
+	f.Add([]byte(`<a href="microsoft-edge-stable_999999.0.0.0-1_amd64.deb">x</a> 31-Feb-2024 25:99`))
+	f.Add([]byte(`<a href="microsoft-edge-stable_.deb">x</a> 01-Jan-2024 00:00`))
+	f.Add([]byte{})
+
+	g := newFuzzGenerator(f)
+	f.Fuzz(func(t *testing.T, body []byte) {
+		versions, err := g.parseMicrosoftVersions(msEdgeRepoURL, body)
+		if err != nil {
+			return
+		}
+		if len(versions) == 0 || len(versions) > versionsToKeepFromMS {
+			t.Fatalf("неверное количество версий: %d", len(versions))
+		}
+		seen := make(map[string]bool)
+		for _, v := range versions {
+			if !chromiumVersionRegex.MatchString(v) || seen[v] {
+				t.Fatalf("неверная или повторная версия %q в %q", v, versions)
+			}
+			seen[v] = true
+		}
+	})
+}
+
+// FuzzDiskCache файл кэша произвольного содержания не роняет загрузку, а принятый кэш содержит
+// только версии верного формата, с которыми генератор работает
+func FuzzDiskCache(f *testing.F) {
+	valid, _ := json.Marshal(cacheFile{
+		Timestamp: time.Now(),
+		Versions:  []string{"142.0.7444.59", "141.0.7390.122"},
+		Firefox:   []string{"144.0"},
+		Edge:      []string{"142.0.3595.53"},
+	})
+	f.Add(valid)
+	f.Add([]byte(`{"timestamp":"2026-01-01T00:00:00Z","versions":["1.2.3"]}`))
+	f.Add([]byte(`{"versions":["142.0.7444.59"],"firefox":["x"]}`))
+	f.Add([]byte(`null`))
+
+	g := newFuzzGenerator(f)
+	path := filepath.Join(f.TempDir(), "cache.json")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		g.diskCachePath, g.diskCacheTTL = path, 100*365*24*time.Hour
+		if !g.loadFromDiskCache() {
+			return
+		}
+		if !allMatch(g.GetVersions(), chromiumVersionRegex) {
+			t.Fatalf("принят кэш с версиями неверного формата: %q", g.GetVersions())
+		}
+		if ua := g.Get(); ua == "" {
+			t.Fatal("пустой User-Agent после загрузки кэша")
+		}
+	})
+}
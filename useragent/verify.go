@@ -0,0 +1,265 @@
+// verify.go проверка подлинности поисковых роботов на стороне сервера: опубликованные диапазоны IP и обратный DNS
+
+package useragent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+const (
+	// опубликованные диапазоны адресов роботов
+	googleBotRangesURL = "https://developers.google.com/static/search/apis/ipranges/googlebot.json"
+	bingBotRangesURL   = "https://www.bing.com/toolbox/bingbot.json"
+	appleBotRangesURL  = "https://search.developer.apple.com/applebot.json"
+
+	// время жизни загруженных диапазонов адресов
+	crawlerRangesTTL = 24 * time.Hour
+	// пауза перед повторной загрузкой диапазонов после неудачи
+	crawlerRangesRetry = 5 * time.Minute
+)
+
+// crawlerSpec признаки поискового робота для проверки подлинности
+type crawlerSpec struct {
+	crawler   CrawlerType
+	token     string   // токен в User-Agent (без учета регистра)
+	domains   []string // домены, в которые должен разрешаться обратный DNS адреса робота
+	rangesURL string   // опубликованные диапазоны адресов, пусто - только обратный DNS
+}
+
+// crawlerSpecs способы проверки роботов по документации поисковых систем
+var crawlerSpecs = []crawlerSpec{
+	{crawler: GoogleBot, token: "googlebot", domains: []string{"googlebot.com", "google.com", "googleusercontent.com"}, rangesURL: googleBotRangesURL},
+	{crawler: BingBot, token: "bingbot", domains: []string{"search.msn.com"}, rangesURL: bingBotRangesURL},
+	{crawler: YandexBot, token: "yandex", domains: []string{"yandex.ru", "yandex.net", "yandex.com"}},
+	{crawler: DuckDuckBot, token: "duckduckbot", domains: []string{"duckduckgo.com"}},
+	{crawler: BaiduSpider, token: "baiduspider", domains: []string{"baidu.com", "baidu.jp"}},
+	{crawler: AppleBot, token: "applebot", domains: []string{"applebot.apple.com"}, rangesURL: appleBotRangesURL},
+	{crawler: SogouSpider, token: "sogou", domains: []string{"sogou.com"}},
+}
+
+// методы подтверждения в CrawlerVerification.Method
+const (
+	VerifiedByIPRange = "ip-range" // адрес входит в опубликованные диапазоны поисковой системы
+	VerifiedByDNS     = "rdns"     // обратный DNS в домене поисковой системы и прямой DNS подтверждает адрес
+)
+
+// CrawlerVerification результат проверки запроса, представившегося поисковым роботом
+type CrawlerVerification struct {
+	Claimed  bool        // User-Agent принадлежит известному роботу
+	Crawler  CrawlerType // заявленный робот (при Claimed)
+	Verified bool        // адрес действительно принадлежит поисковой системе
+	Method   string      // чем подтвержден адрес: VerifiedByIPRange или VerifiedByDNS
+	Hostname string      // имя хоста из обратного DNS (при VerifiedByDNS)
+}
+
+// VerifyCrawler проверяет, что запрос с адреса remoteIP и User-Agent поискового робота (Googlebot, Bingbot,
+// YandexBot и другие из GetCrawlerHeaders) действительно отправлен поисковой системой:
+// сначала по опубликованным диапазонам адресов (Google, Bing, Apple), затем обратным DNS-запросом
+// с подтверждением прямым, как рекомендуют сами поисковые системы.
+//
+// ошибка возвращается при неверном адресе и при сбое DNS, когда подлинность установить не удалось:
+// в этом случае запрос не следует считать ни подлинным, ни поддельным. диапазоны адресов кэшируются на сутки,
+// метод выполняет сетевые запросы, результат стоит кэшировать по адресу. в офлайн-режиме и в сборке
+// с тегом nonet проверка невозможна: для робота возвращается ошибка, запрос обычного браузера не проверяется.
+func (g *Generator) VerifyCrawler(remoteIP, userAgent string) (CrawlerVerification, error) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(remoteIP))
+	if err != nil {
+		return CrawlerVerification{}, fmt.Errorf("неверный IP-адрес %q: %w", remoteIP, err)
+	}
+	addr = addr.Unmap()
+
+	spec, ok := crawlerSpecFor(userAgent)
+	if !ok {
+		return CrawlerVerification{}, nil
+	}
+	result := CrawlerVerification{Claimed: true, Crawler: spec.crawler}
+	if g.offline {
+		return result, errOffline
+	}
+
+	if spec.rangesURL != "" {
+		if ranges, err := g.crawlerRanges(spec); err == nil {
+			for _, prefix := range ranges {
+				if prefix.Contains(addr) {
+					result.Verified, result.Method = true, VerifiedByIPRange
+					return result, nil
+				}
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.httpClient.Timeout)
+	defer cancel()
+	hostname, err := verifyByDNS(ctx, addr, spec.domains)
+	if err != nil {
+		return result, err
+	}
+	if hostname != "" {
+		result.Verified, result.Method, result.Hostname = true, VerifiedByDNS, hostname
+	}
+	return result, nil
+}
+
+// crawlerSpecFor определяет робота по строке User-Agent
+func crawlerSpecFor(userAgent string) (crawlerSpec, bool) {
+	lower := strings.ToLower(userAgent)
+	for _, spec := range crawlerSpecs {
+		if strings.Contains(lower, spec.token) {
+			return spec, true
+		}
+	}
+	return crawlerSpec{}, false
+}
+
+// verifyByDNS выполняет обратный DNS-запрос и подтверждает найденное имя прямым запросом:
+// возвращает имя хоста в домене поисковой системы или пустую строку, если адрес ей не принадлежит
+func verifyByDNS(ctx context.Context, addr netip.Addr, domains []string) (string, error) {
+	names, err := net.DefaultResolver.LookupAddr(ctx, addr.String())
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("обратный DNS-запрос не удался: %w", err)
+	}
+
+	for _, name := range names {
+		host := strings.TrimSuffix(strings.ToLower(name), ".")
+		if !inDomains(host, domains) {
+			continue
+		}
+		// имя из обратной зоны может указать кто угодно, поэтому оно должно разрешаться обратно в тот же адрес
+		ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+		if err != nil {
+			continue
+		}
+		for _, ip := range ips {
+			if ip.Unmap() == addr {
+				return host, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// inDomains проверяет, что хост совпадает с одним из доменов или является его поддоменом
+func inDomains(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// crawlerRangesFile формат опубликованных диапазонов адресов (общий для Google, Bing и Apple)
+type crawlerRangesFile struct {
+	Prefixes []struct {
+		IPv4Prefix string `json:"ipv4Prefix"`
+		IPv6Prefix string `json:"ipv6Prefix"`
+	} `json:"prefixes"`
+}
+
+// cachedRanges загруженные диапазоны адресов робота и состояние их загрузки
+type cachedRanges struct {
+	prefixes []netip.Prefix
+	loadedAt time.Time     // время последней успешной загрузки
+	failedAt time.Time     // время последней неудачной попытки
+	err      error         // ошибка последней попытки
+	loading  chan struct{} // закрывается по окончании текущей загрузки, nil - загрузки нет
+}
+
+// result возвращает загруженные диапазоны, в том числе устаревшие, или ошибку последней попытки
+func (c *cachedRanges) result() ([]netip.Prefix, error) {
+	if c.prefixes != nil {
+		return c.prefixes, nil
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return nil, errors.New("диапазоны адресов робота еще не загружены")
+}
+
+// crawlerRanges возвращает опубликованные диапазоны адресов робота, загружая их не чаще раза в сутки.
+// загрузка идет вне мьютекса и одна на робота: параллельные вызовы получают устаревшие диапазоны сразу
+// или, если их еще нет, ждут ту же загрузку. после неудачи новая попытка не раньше crawlerRangesRetry,
+// а до тех пор вызовы сразу получают устаревшие диапазоны или ошибку
+func (g *Generator) crawlerRanges(spec crawlerSpec) ([]netip.Prefix, error) {
+	g.rangesMu.Lock()
+	if g.ranges == nil {
+		g.ranges = make(map[CrawlerType]*cachedRanges)
+	}
+	entry := g.ranges[spec.crawler]
+	if entry == nil {
+		entry = &cachedRanges{}
+		g.ranges[spec.crawler] = entry
+	}
+	switch {
+	case time.Since(entry.loadedAt) < crawlerRangesTTL, time.Since(entry.failedAt) < crawlerRangesRetry:
+		defer g.rangesMu.Unlock()
+		return entry.result()
+	case entry.loading != nil:
+		if entry.prefixes != nil {
+			defer g.rangesMu.Unlock()
+			return entry.prefixes, nil
+		}
+		loading := entry.loading
+		g.rangesMu.Unlock()
+		<-loading
+		g.rangesMu.Lock()
+		defer g.rangesMu.Unlock()
+		return entry.result()
+	}
+	entry.loading = make(chan struct{})
+	g.rangesMu.Unlock()
+
+	prefixes, err := g.fetchCrawlerRanges(spec)
+
+	g.rangesMu.Lock()
+	defer g.rangesMu.Unlock()
+	if err != nil {
+		entry.failedAt, entry.err = time.Now(), err
+	} else {
+		entry.prefixes, entry.loadedAt, entry.err = prefixes, time.Now(), nil
+	}
+	close(entry.loading)
+	entry.loading = nil
+	return entry.result()
+}
+
+// fetchCrawlerRanges загружает опубликованные диапазоны адресов робота
+func (g *Generator) fetchCrawlerRanges(spec crawlerSpec) ([]netip.Prefix, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.requestTimeout())
+	defer cancel()
+	var file crawlerRangesFile
+	err := g.executeGet(ctx, spec.rangesURL, func(body io.Reader) error {
+		return json.NewDecoder(body).Decode(&file)
+	})
+	if err != nil {
+		g.logger.Warn("не удалось загрузить диапазоны адресов робота", "event", eventCrawlerRangesFailed, "url", spec.rangesURL, "error", err)
+		return nil, err
+	}
+
+	var prefixes []netip.Prefix
+	for _, p := range file.Prefixes {
+		for _, raw := range []string{p.IPv4Prefix, p.IPv6Prefix} {
+			if prefix, err := netip.ParsePrefix(raw); err == nil {
+				prefixes = append(prefixes, prefix.Masked())
+			}
+		}
+	}
+	if len(prefixes) == 0 {
+		err := errors.New("в ответе нет ни одного диапазона адресов")
+		g.logger.Warn("не удалось загрузить диапазоны адресов робота", "event", eventCrawlerRangesFailed, "url", spec.rangesURL, "error", err)
+		return nil, err
+	}
+	return prefixes, nil
+}
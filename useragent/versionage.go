@@ -0,0 +1,81 @@
+// versionage.go политика RotationVersionAge: старые версии браузеров встречаются реже новых
+
+package useragent
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// versionAgeHalfLife на сколько мажорных версий нужно отстать от новейшей, чтобы доля версии уменьшилась вдвое
+	versionAgeHalfLife = 1.0
+
+	// versionAgeAttempts ограничивает число выборов одной строки: при длинном хвосте старых версий
+	// отказ подряд маловероятен, но не должен превращаться в долгий цикл
+	versionAgeAttempts = 64
+)
+
+// versionAgeUA выбирает строку с учетом возраста версии: первая случайная строка определяет бренд
+// (так доли браузеров не меняются), а версия этого бренда принимается с вероятностью,
+// которая убывает с отставанием от новейшей мажорной версии
+func (rt *Rotator) versionAgeUA() string {
+	g := rt.g
+	newest := rt.newestMajors()
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	first := g.randomUA(g.rng)
+	brand := parseUserAgent(first).BrandName
+	for attempt := range versionAgeAttempts {
+		ua := first
+		if attempt > 0 {
+			ua = g.randomUA(g.rng)
+		}
+		info := parseUserAgent(ua)
+		if info.BrandName != brand {
+			continue
+		}
+		top, major := newest[brand], newestMajor([]string{fullVersion(info)})
+		age := top - major
+		if top == 0 || major == 0 || age <= 0 || g.rng.Float64() < math.Exp2(-float64(age)/versionAgeHalfLife) {
+			return ua
+		}
+	}
+	return first
+}
+
+// newestMajors возвращает новейшие мажорные версии брендов, пересчитывая их после обновления версий
+func (rt *Rotator) newestMajors() map[string]int {
+	g := rt.g
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if rt.newest != nil && rt.newestAt.Equal(g.versionsAt) {
+		return rt.newest
+	}
+	chromium := newestMajor(g.versions)
+	rt.newest = map[string]int{
+		"Google Chrome":  chromium,
+		"Microsoft Edge": max(chromium, newestMajor(g.edgeVersions)),
+		"Firefox":        newestMajor(g.firefoxVersions),
+		"Safari":         newestMajor(safariVersionsAt(time.Now())),
+	}
+	rt.newestAt = g.versionsAt
+	return rt.newest
+}
+
+// newestMajor возвращает наибольшую мажорную версию набора, 0 - набор пуст
+func newestMajor(versions []string) int {
+	var top int
+	for _, v := range versions {
+		major, _, _ := strings.Cut(v, ".")
+		if n, err := strconv.Atoi(major); err == nil {
+			top = max(top, n)
+		}
+	}
+	return top
+}
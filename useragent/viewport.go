@@ -0,0 +1,92 @@
+// viewport.go модель вьюпорта, согласованная с платформой и состоянием окна браузера
+
+package useragent
+
+import (
+	"math"
+	"math/rand/v2"
+	"strconv"
+)
+
+// минимальный размер вьюпорта: окна браузера меньше этого практически не встречаются
+const (
+	minViewportWidth  = 500
+	minViewportHeight = 300
+)
+
+// zoomLevel уровень масштаба страницы с относительной частотой
+type zoomLevel struct {
+	Level  float64
+	Weight float64
+}
+
+// zoomLevels распространенные уровни масштаба: подавляющее большинство пользователей его не меняют
+var zoomLevels = []zoomLevel{
+	{Level: 1.0, Weight: 85},
+	{Level: 1.1, Weight: 5},
+	{Level: 1.25, Weight: 6},
+	{Level: 0.9, Weight: 4},
+}
+
+// windowState состояние окна браузера: выбирается один раз и может храниться неизменным
+// в пределах персоны/сессии, чтобы подсказки вьюпорта не менялись от запроса к запросу
+type windowState struct {
+	Maximized bool    // окно развернуто на весь экран
+	Zoom      float64 // масштаб страницы
+	Width     int     // ширина вьюпорта в CSS-пикселях
+	Height    int     // высота вьюпорта в CSS-пикселях
+}
+
+// pickZoom выбирает уровень масштаба с учетом весов
+func pickZoom(r *rand.Rand) float64 {
+	var total float64
+	for _, z := range zoomLevels {
+		total += z.Weight
+	}
+	x := r.Float64() * total
+	for _, z := range zoomLevels {
+		if x < z.Weight {
+			return z.Level
+		}
+		x -= z.Weight
+	}
+	return 1.0
+}
+
+// pickWindowState выбирает состояние окна и вычисляет вьюпорт для платформы и разрешения экрана
+// (место, занятое ОС, и частота развернутых окон берутся из профиля платформы):
+// из высоты экрана вычитается место, занятое ОС и интерфейсом браузера (вкладки, панель адреса, закладки),
+// из ширины - скроллбар и боковые панели; не развернутое окно занимает 60-95% доступной области,
+// а масштаб страницы уменьшает вьюпорт в CSS-пикселях
+func (d *realismData) pickWindowState(r *rand.Rand, model platformProfile, res screenResolution) windowState {
+	state := windowState{
+		Maximized: r.Float64() < model.maximizedProbability,
+		Zoom:      pickZoom(r),
+	}
+
+	availableHeight := res.Height - model.reservedHeights[r.IntN(len(model.reservedHeights))]
+	availableWidth := res.Width
+	if !state.Maximized {
+		availableHeight = int(float64(availableHeight) * (0.6 + 0.35*r.Float64()))
+		availableWidth = int(float64(availableWidth) * (0.6 + 0.35*r.Float64()))
+	}
+
+	browserUIHeight := d.ViewportHeightSubtractions[r.IntN(len(d.ViewportHeightSubtractions))]
+	sideWidth := d.ViewportWidthSubtractions[r.IntN(len(d.ViewportWidthSubtractions))]
+
+	state.Width = max(int(float64(availableWidth-sideWidth)/state.Zoom), minViewportWidth)
+	state.Height = max(int(float64(availableHeight-browserUIHeight)/state.Zoom), minViewportHeight)
+	return state
+}
+
+// scaleDPR учитывает масштаб страницы в device pixel ratio: в Chrome масштаб меняет DPR
+func scaleDPR(dpr string, zoom float64) string {
+	if zoom == 1.0 {
+		return dpr
+	}
+	base, err := strconv.ParseFloat(dpr, 64)
+	if err != nil {
+		return dpr
+	}
+	return strconv.FormatFloat(math.Round(base*zoom*1e4)/1e4, 'f', -1, 64)
+}